@@ -0,0 +1,16 @@
+package config
+
+import "github.com/tal-tech/go-zero/rest"
+
+type Config struct {
+	rest.RestConf
+	Database struct {
+		// DataSource is the dsn of the database, its scheme chooses the driver:
+		// sqlite://, mysql:// or postgres://
+		DataSource string `json:",default=sqlite:///data/algorithm.db"`
+	}
+	// Auth accepts the access tokens of the user-api, AccessSecret is ${ACCESS_SECRET} of its environment
+	Auth struct {
+		AccessSecret string
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-zero-api/algorithm/internal/logic"
+	"go-zero-api/algorithm/internal/svc"
+	"go-zero-api/algorithm/internal/types"
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func RegisterAlgorithmHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.RegisterAlgorithmRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, errorx.NewBadRequest(err.Error()))
+			return
+		}
+
+		l := logic.NewRegisterAlgorithmLogic(r.Context(), ctx)
+		resp, err := l.RegisterAlgorithm(req)
+		if err != nil {
+			httpx.Error(w, err)
+		} else {
+			httpx.OkJson(w, resp)
+		}
+	}
+}
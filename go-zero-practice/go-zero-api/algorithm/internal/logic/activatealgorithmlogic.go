@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/algorithm/internal/svc"
+	"go-zero-api/algorithm/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ActivateAlgorithmLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewActivateAlgorithmLogic(ctx context.Context, svcCtx *svc.ServiceContext) ActivateAlgorithmLogic {
+	return ActivateAlgorithmLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ActivateAlgorithmLogic) ActivateAlgorithm(req types.AlgorithmRequest) (*types.Algorithm, error) {
+	item, err := l.svcCtx.AlgorithmModel.Activate(req.Id)
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	l.Infof("algorithm %s %s activated", item.Name, item.Version)
+	return convert(item), nil
+}
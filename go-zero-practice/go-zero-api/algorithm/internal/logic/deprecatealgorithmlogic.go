@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/algorithm/internal/svc"
+	"go-zero-api/algorithm/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type DeprecateAlgorithmLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeprecateAlgorithmLogic(ctx context.Context, svcCtx *svc.ServiceContext) DeprecateAlgorithmLogic {
+	return DeprecateAlgorithmLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeprecateAlgorithmLogic) DeprecateAlgorithm(req types.AlgorithmRequest) (*types.Algorithm, error) {
+	item, err := l.svcCtx.AlgorithmModel.Deprecate(req.Id)
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	l.Infof("algorithm %s %s deprecated", item.Name, item.Version)
+	return convert(item), nil
+}
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/algorithm/internal/svc"
+	"go-zero-api/algorithm/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type GetAlgorithmLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetAlgorithmLogic(ctx context.Context, svcCtx *svc.ServiceContext) GetAlgorithmLogic {
+	return GetAlgorithmLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetAlgorithmLogic) GetAlgorithm(req types.AlgorithmRequest) (*types.Algorithm, error) {
+	item, err := l.svcCtx.AlgorithmModel.FindOne(req.Id)
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	return convert(item), nil
+}
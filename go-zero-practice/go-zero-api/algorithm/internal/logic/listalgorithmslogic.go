@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/algorithm/internal/model"
+	"go-zero-api/algorithm/internal/svc"
+	"go-zero-api/algorithm/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ListAlgorithmsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListAlgorithmsLogic(ctx context.Context, svcCtx *svc.ServiceContext) ListAlgorithmsLogic {
+	return ListAlgorithmsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListAlgorithmsLogic) ListAlgorithms(req types.ListAlgorithmsRequest) (*types.ListAlgorithmsResponse, error) {
+	filter := model.Filter{
+		Name:    req.Name,
+		Keyword: req.Keyword,
+		Status:  req.Status,
+	}
+	items, total, err := l.svcCtx.AlgorithmModel.List(filter, (req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.ListAlgorithmsResponse{
+		Total:      total,
+		Algorithms: make([]types.Algorithm, len(items)),
+	}
+	for i, item := range items {
+		resp.Algorithms[i] = *convert(item)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/algorithm/internal/model"
+	"go-zero-api/algorithm/internal/svc"
+	"go-zero-api/algorithm/internal/types"
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type RegisterAlgorithmLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRegisterAlgorithmLogic(ctx context.Context, svcCtx *svc.ServiceContext) RegisterAlgorithmLogic {
+	return RegisterAlgorithmLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RegisterAlgorithmLogic) RegisterAlgorithm(req types.RegisterAlgorithmRequest) (*types.Algorithm, error) {
+	if err := validateName(req.Name); err != nil {
+		return nil, err
+	}
+	if err := validateVersion(req.Version); err != nil {
+		return nil, err
+	}
+	if err := validateImage(req.Image); err != nil {
+		return nil, err
+	}
+	inputSchema, err := normalizeSchema("inputSchema", req.InputSchema)
+	if err != nil {
+		return nil, err
+	}
+	outputSchema, err := normalizeSchema("outputSchema", req.OutputSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	item := model.Algorithm{
+		Name:         req.Name,
+		Version:      req.Version,
+		Image:        req.Image,
+		Description:  req.Description,
+		InputSchema:  inputSchema,
+		OutputSchema: outputSchema,
+	}
+	switch err := l.svcCtx.AlgorithmModel.Insert(&item); err {
+	case nil:
+	case model.ErrDuplicate:
+		return nil, errorx.NewConflict(err.Error())
+	default:
+		return nil, err
+	}
+
+	return convert(&item), nil
+}
+
+func convert(item *model.Algorithm) *types.Algorithm {
+	return &types.Algorithm{
+		Id:           item.Id,
+		Name:         item.Name,
+		Version:      item.Version,
+		Image:        item.Image,
+		Description:  item.Description,
+		InputSchema:  item.InputSchema,
+		OutputSchema: item.OutputSchema,
+		Status:       item.Status,
+		CreateTime:   item.CreateTime.Unix(),
+		UpdateTime:   item.UpdateTime.Unix(),
+	}
+}
+
+// modelError maps the errors of the model to the responses.
+func modelError(err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return errorx.NewNotFound(err.Error())
+	case model.ErrDeprecated:
+		return errorx.NewConflict(err.Error())
+	default:
+		return err
+	}
+}
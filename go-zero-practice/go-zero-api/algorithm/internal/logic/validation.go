@@ -0,0 +1,71 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-zero-api/common/errorx"
+)
+
+const (
+	maxImageLength  = 255
+	maxSchemaLength = 64 << 10
+)
+
+var (
+	// the names are dns labels, so that they can name the deployments
+	namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	// semantic versions, the v prefix is optional
+	versionPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	// [registry/]repository[:tag][@digest]
+	imagePattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*(:[\w][\w.-]{0,127})?(@sha256:[a-f0-9]{64})?$`)
+)
+
+func validateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return errorx.NewBadRequest("name must be 1 to 63 lower case letters, digits or dashes, starting and ending with a letter or digit")
+	}
+
+	return nil
+}
+
+func validateVersion(version string) error {
+	if !versionPattern.MatchString(version) {
+		return errorx.NewBadRequest("version must be a semantic version like 1.2.3")
+	}
+
+	return nil
+}
+
+func validateImage(image string) error {
+	if len(image) > maxImageLength || !imagePattern.MatchString(image) {
+		return errorx.NewBadRequest("image must be a container image reference like registry/repository:tag")
+	}
+
+	return nil
+}
+
+// normalizeSchema returns the compact form of the json schema, an empty schema accepts anything.
+func normalizeSchema(field, schema string) (string, error) {
+	schema = strings.TrimSpace(schema)
+	if len(schema) == 0 {
+		return "{}", nil
+	}
+	if len(schema) > maxSchemaLength {
+		return "", errorx.NewBadRequest(fmt.Sprintf("%s must be at most %d bytes", field, maxSchemaLength))
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &value); err != nil || value == nil {
+		return "", errorx.NewBadRequest(fmt.Sprintf("%s must be a json object", field))
+	}
+
+	compact, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(compact), nil
+}
@@ -0,0 +1,72 @@
+package logic
+
+import "testing"
+
+func TestValidation(t *testing.T) {
+	valid := []struct {
+		check func(string) error
+		value string
+	}{
+		{validateName, "face-detect"},
+		{validateName, "a"},
+		{validateVersion, "1.0.0"},
+		{validateVersion, "v2.10.3-rc.1+build.5"},
+		{validateImage, "nginx"},
+		{validateImage, "registry.local:5000/vision/face-detect:1.0.0"},
+		{validateImage, "ghcr.io/team/ocr@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	}
+	for _, test := range valid {
+		if err := test.check(test.value); err != nil {
+			t.Fatalf("%q: %v", test.value, err)
+		}
+	}
+
+	invalid := []struct {
+		check func(string) error
+		value string
+	}{
+		{validateName, ""},
+		{validateName, "Face"},
+		{validateName, "-face"},
+		{validateName, "face_detect"},
+		{validateVersion, "1.0"},
+		{validateVersion, "01.0.0"},
+		{validateVersion, "latest"},
+		{validateImage, ""},
+		{validateImage, "Registry/Face"},
+		{validateImage, "face detect:1.0"},
+		{validateImage, "ghcr.io/team/ocr@sha256:123"},
+	}
+	for _, test := range invalid {
+		if err := test.check(test.value); err == nil {
+			t.Fatalf("%q: expect error", test.value)
+		}
+	}
+}
+
+func TestNormalizeSchema(t *testing.T) {
+	tests := []struct {
+		schema string
+		expect string
+	}{
+		{"", "{}"},
+		{"  ", "{}"},
+		{`{ "type": "object", "properties": { "image": { "type": "string" } } }`,
+			`{"properties":{"image":{"type":"string"}},"type":"object"}`},
+	}
+	for _, test := range tests {
+		actual, err := normalizeSchema("inputSchema", test.schema)
+		if err != nil {
+			t.Fatalf("%q: %v", test.schema, err)
+		}
+		if actual != test.expect {
+			t.Fatalf("%q: expect %s, got %s", test.schema, test.expect, actual)
+		}
+	}
+
+	for _, schema := range []string{"null", "[]", `"object"`, "{", "1"} {
+		if _, err := normalizeSchema("inputSchema", schema); err == nil {
+			t.Fatalf("%q: expect error", schema)
+		}
+	}
+}
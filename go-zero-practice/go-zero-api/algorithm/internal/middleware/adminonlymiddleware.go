@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+// roleAdmin is the admin role of the rbac of the user-api.
+const roleAdmin = "admin"
+
+// AdminOnlyMiddleware lets only the admins change the algorithms. The role is the claim
+// of the access token issued by the user-api, a changed role takes effect with the next token.
+type AdminOnlyMiddleware struct{}
+
+func NewAdminOnlyMiddleware() *AdminOnlyMiddleware {
+	return &AdminOnlyMiddleware{}
+}
+
+func (m *AdminOnlyMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the jwt middleware puts the claims into the context before
+		if role, _ := r.Context().Value("role").(string); role != roleAdmin {
+			httpx.Error(w, errorx.New(http.StatusForbidden, "the admin role is required"))
+			return
+		}
+
+		next(w, r)
+	}
+}
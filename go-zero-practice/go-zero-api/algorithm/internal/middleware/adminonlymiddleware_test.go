@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func TestAdminOnlyMiddleware(t *testing.T) {
+	httpx.SetErrorHandler(errorx.Handler)
+
+	handler := NewAdminOnlyMiddleware().Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		role   interface{}
+		expect int
+	}{
+		{"admin", http.StatusNoContent},
+		{"user", http.StatusForbidden},
+		{"", http.StatusForbidden},
+		{nil, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/algorithms/1/activate", nil)
+		if test.role != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "role", test.role))
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != test.expect {
+			t.Fatalf("role %v: expect %d, got %d", test.role, test.expect, w.Code)
+		}
+	}
+}
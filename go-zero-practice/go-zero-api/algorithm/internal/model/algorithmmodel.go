@@ -0,0 +1,198 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-zero-api/common/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// StatusInactive is the status of the registered versions.
+	StatusInactive = "inactive"
+	// StatusActive is the status of the version in use, there is at most one per name.
+	StatusActive = "active"
+	// StatusDeprecated is the status of the versions that can not be activated anymore.
+	StatusDeprecated = "deprecated"
+)
+
+var (
+	// ErrNotFound means there is no algorithm with the id.
+	ErrNotFound = errors.New("algorithm not found")
+	// ErrDuplicate means the version of the algorithm is already registered.
+	ErrDuplicate = errors.New("algorithm version is already registered")
+	// ErrDeprecated means the version is deprecated.
+	ErrDeprecated = errors.New("algorithm version is deprecated")
+)
+
+type (
+	// Algorithm is a version of an algorithm package.
+	Algorithm struct {
+		Id           int64     `gorm:"primaryKey"`
+		Name         string    `gorm:"size:64;not null"`
+		Version      string    `gorm:"size:64;not null"`
+		Image        string    `gorm:"size:255;not null"`
+		Description  string    `gorm:"size:1024;not null;default:''"`
+		InputSchema  string    `gorm:"type:text;not null"`
+		OutputSchema string    `gorm:"type:text;not null"`
+		Status       string    `gorm:"size:16;not null"`
+		CreateTime   time.Time `gorm:"autoCreateTime"`
+		UpdateTime   time.Time `gorm:"autoUpdateTime"`
+	}
+
+	// Filter selects the algorithms to list, the zero fields select all.
+	Filter struct {
+		Name string
+		// Keyword is searched in the names, the descriptions and the images
+		Keyword string
+		Status  string
+	}
+
+	// AlgorithmModel keeps the algorithm packages.
+	AlgorithmModel interface {
+		Insert(data *Algorithm) error
+		FindOne(id int64) (*Algorithm, error)
+		List(filter Filter, offset, limit int64) ([]*Algorithm, int64, error)
+		// Activate activates the version and deactivates the other versions of the name
+		Activate(id int64) (*Algorithm, error)
+		Deprecate(id int64) (*Algorithm, error)
+	}
+
+	defaultAlgorithmModel struct {
+		db *gorm.DB
+	}
+)
+
+// NewAlgorithmModel returns an AlgorithmModel that keeps the algorithms in db.
+func NewAlgorithmModel(db *gorm.DB) AlgorithmModel {
+	return &defaultAlgorithmModel{db: db}
+}
+
+func (m *defaultAlgorithmModel) Insert(data *Algorithm) error {
+	data.Status = StatusInactive
+	err := m.db.Create(data).Error
+	if database.IsDuplicate(err) {
+		return ErrDuplicate
+	}
+
+	return err
+}
+
+func (m *defaultAlgorithmModel) FindOne(id int64) (*Algorithm, error) {
+	return findOne(m.db, id)
+}
+
+func (m *defaultAlgorithmModel) List(filter Filter, offset, limit int64) ([]*Algorithm, int64, error) {
+	query := m.db.Model(&Algorithm{})
+	if len(filter.Name) > 0 {
+		query = query.Where("name = ?", filter.Name)
+	}
+	if len(filter.Status) > 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if len(filter.Keyword) > 0 {
+		keyword := "%" + escapeLike(filter.Keyword) + "%"
+		query = query.Where("name LIKE ? ESCAPE '!' OR description LIKE ? ESCAPE '!' OR image LIKE ? ESCAPE '!'",
+			keyword, keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var resp []*Algorithm
+	err := query.Order("name").Order("id DESC").
+		Offset(int(offset)).
+		Limit(int(limit)).
+		Find(&resp).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return resp, total, nil
+}
+
+func (m *defaultAlgorithmModel) Activate(id int64) (*Algorithm, error) {
+	item, err := findOne(m.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		// lock all versions of the name, not only the activated one, so that the activations
+		// of two versions run one after the other and never leave both active
+		var versions []*Algorithm
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", item.Name).
+			Find(&versions).Error; err != nil {
+			return err
+		}
+
+		item = nil
+		for _, version := range versions {
+			if version.Id == id {
+				item = version
+			}
+		}
+		if item == nil {
+			return ErrNotFound
+		}
+
+		switch item.Status {
+		case StatusActive:
+			return nil
+		case StatusDeprecated:
+			return ErrDeprecated
+		}
+
+		if err := tx.Model(&Algorithm{}).
+			Where("name = ? AND status = ?", item.Name, StatusActive).
+			Update("status", StatusInactive).Error; err != nil {
+			return err
+		}
+		return tx.Model(item).Update("status", StatusActive).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (m *defaultAlgorithmModel) Deprecate(id int64) (*Algorithm, error) {
+	item, err := findOne(m.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Status != StatusDeprecated {
+		if err := m.db.Model(item).Update("status", StatusDeprecated).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func findOne(db *gorm.DB, id int64) (*Algorithm, error) {
+	var resp Algorithm
+	err := db.Take(&resp, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// escapeLike escapes the wildcards of LIKE with !.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"go-zero-api/common/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestModel(t *testing.T) AlgorithmModel {
+	dsn := fmt.Sprintf("sqlite://file:%s?mode=memory&cache=shared", t.Name())
+	db, err := database.Open(dsn, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewAlgorithmModel(db)
+}
+
+func mustInsert(t *testing.T, m AlgorithmModel, name, version, description string) *Algorithm {
+	t.Helper()
+
+	item := &Algorithm{
+		Name:         name,
+		Version:      version,
+		Image:        "registry.local/" + name + ":" + version,
+		Description:  description,
+		InputSchema:  "{}",
+		OutputSchema: "{}",
+	}
+	if err := m.Insert(item); err != nil {
+		t.Fatal(err)
+	}
+
+	return item
+}
+
+func assertStatus(t *testing.T, m AlgorithmModel, id int64, expect string) {
+	t.Helper()
+
+	item, err := m.FindOne(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Status != expect {
+		t.Fatalf("expect %d %s, got %s", id, expect, item.Status)
+	}
+}
+
+func TestInsertAndFind(t *testing.T) {
+	m := newTestModel(t)
+
+	item := mustInsert(t, m, "face-detect", "1.0.0", "finds faces")
+	if item.Id == 0 || item.Status != StatusInactive || item.CreateTime.IsZero() {
+		t.Fatalf("unexpected inserted algorithm: %+v", item)
+	}
+
+	found, err := m.FindOne(item.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Name != "face-detect" || found.Version != "1.0.0" || found.Description != "finds faces" {
+		t.Fatalf("unexpected algorithm: %+v", found)
+	}
+
+	if _, err := m.FindOne(item.Id + 1); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+
+	duplicate := &Algorithm{Name: "face-detect", Version: "1.0.0", Image: "other", InputSchema: "{}", OutputSchema: "{}"}
+	if err := m.Insert(duplicate); err != ErrDuplicate {
+		t.Fatalf("expect ErrDuplicate, got %v", err)
+	}
+}
+
+func TestActivateAndDeprecate(t *testing.T) {
+	m := newTestModel(t)
+
+	v1 := mustInsert(t, m, "face-detect", "1.0.0", "")
+	v2 := mustInsert(t, m, "face-detect", "2.0.0", "")
+	other := mustInsert(t, m, "plate-ocr", "1.0.0", "")
+
+	if _, err := m.Activate(v1.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Activate(other.Id); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, m, v1.Id, StatusActive)
+
+	// activating another version deactivates the former one of the same name only
+	item, err := m.Activate(v2.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Status != StatusActive {
+		t.Fatalf("expect active, got %s", item.Status)
+	}
+	assertStatus(t, m, v1.Id, StatusInactive)
+	assertStatus(t, m, v2.Id, StatusActive)
+	assertStatus(t, m, other.Id, StatusActive)
+
+	// activating twice is fine
+	if _, err := m.Activate(v2.Id); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, m, v2.Id, StatusActive)
+
+	if _, err := m.Deprecate(v2.Id); err != nil {
+		t.Fatal(err)
+	}
+	assertStatus(t, m, v2.Id, StatusDeprecated)
+	if _, err := m.Activate(v2.Id); err != ErrDeprecated {
+		t.Fatalf("expect ErrDeprecated, got %v", err)
+	}
+
+	if _, err := m.Activate(0); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+	if _, err := m.Deprecate(0); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	m := newTestModel(t)
+
+	v1 := mustInsert(t, m, "face-detect", "1.0.0", "finds faces")
+	mustInsert(t, m, "face-detect", "2.0.0", "finds faces faster")
+	mustInsert(t, m, "plate-ocr", "1.0.0", "reads 100% of plates")
+	mustInsert(t, m, "crowd_count", "1.0.0", "counts people")
+	if _, err := m.Activate(v1.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		expect []string
+	}{
+		{"all", Filter{}, []string{"crowd_count 1.0.0", "face-detect 2.0.0", "face-detect 1.0.0", "plate-ocr 1.0.0"}},
+		{"by name", Filter{Name: "face-detect"}, []string{"face-detect 2.0.0", "face-detect 1.0.0"}},
+		{"by status", Filter{Status: StatusActive}, []string{"face-detect 1.0.0"}},
+		{"by keyword in description", Filter{Keyword: "faster"}, []string{"face-detect 2.0.0"}},
+		{"by keyword in image", Filter{Keyword: "registry.local/plate"}, []string{"plate-ocr 1.0.0"}},
+		{"keyword wildcards are literal", Filter{Keyword: "100%"}, []string{"plate-ocr 1.0.0"}},
+		{"keyword underscore is literal", Filter{Keyword: "_"}, []string{"crowd_count 1.0.0"}},
+		{"name and status", Filter{Name: "face-detect", Status: StatusInactive}, []string{"face-detect 2.0.0"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, total, err := m.List(test.filter, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != int64(len(test.expect)) || len(items) != len(test.expect) {
+				t.Fatalf("expect %v, got %d items of %d", test.expect, len(items), total)
+			}
+			for i, item := range items {
+				if actual := item.Name + " " + item.Version; actual != test.expect[i] {
+					t.Fatalf("expect %s at %d, got %s", test.expect[i], i, actual)
+				}
+			}
+		})
+	}
+
+	items, total, err := m.List(Filter{}, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 || len(items) != 2 || items[0].Version != "2.0.0" {
+		t.Fatalf("unexpected page: %d %+v", total, items)
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go-zero-api/common/database"
+	"go-zero-api/common/migrate"
+
+	"gorm.io/gorm"
+)
+
+const migrationTable = "algorithm_schema_migrations"
+
+// migrations are applied in order, never change an applied migration, add a new one instead.
+var migrations = []migrate.Migration{
+	{Version: 1, Name: "create algorithms", Up: createAlgorithms},
+}
+
+// Migrate applies the pending migrations of the algorithm-api to db.
+func Migrate(db *gorm.DB) error {
+	return migrate.Migrate(db, migrationTable, migrations)
+}
+
+// MustOpen opens the database of dsn and migrates it to the latest version, exits on error.
+func MustOpen(dsn string) *gorm.DB {
+	db, err := database.Open(dsn, nil)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect database: %w", err))
+	}
+
+	if err := Migrate(db); err != nil {
+		panic(fmt.Errorf("failed to migrate database: %w", err))
+	}
+
+	return db
+}
+
+type algorithmV1 struct {
+	Id           int64     `gorm:"primaryKey"`
+	Name         string    `gorm:"size:64;not null;uniqueIndex:idx_algorithms_name_version"`
+	Version      string    `gorm:"size:64;not null;uniqueIndex:idx_algorithms_name_version"`
+	Image        string    `gorm:"size:255;not null"`
+	Description  string    `gorm:"size:1024;not null;default:''"`
+	InputSchema  string    `gorm:"type:text;not null"`
+	OutputSchema string    `gorm:"type:text;not null"`
+	Status       string    `gorm:"size:16;not null;index:idx_algorithms_status"`
+	CreateTime   time.Time `gorm:"not null"`
+	UpdateTime   time.Time `gorm:"not null"`
+}
+
+func (algorithmV1) TableName() string {
+	return "algorithms"
+}
+
+func createAlgorithms(tx *gorm.DB) error {
+	return migrate.CreateTables(tx, &algorithmV1{})
+}
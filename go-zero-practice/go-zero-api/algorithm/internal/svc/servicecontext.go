@@ -0,0 +1,32 @@
+package svc
+
+import (
+	"go-zero-api/algorithm/internal/config"
+	"go-zero-api/algorithm/internal/middleware"
+	"go-zero-api/algorithm/internal/model"
+
+	"github.com/tal-tech/go-zero/rest"
+	"gorm.io/gorm"
+)
+
+type ServiceContext struct {
+	Config         config.Config
+	DB             *gorm.DB
+	AlgorithmModel model.AlgorithmModel
+	// AdminOnly guards the routes that change the algorithms
+	AdminOnly rest.Middleware
+}
+
+func NewServiceContext(c config.Config) *ServiceContext {
+	if len(c.Auth.AccessSecret) == 0 {
+		panic("Auth.AccessSecret is empty, set ACCESS_SECRET")
+	}
+
+	db := model.MustOpen(c.Database.DataSource)
+	return &ServiceContext{
+		Config:         c,
+		DB:             db,
+		AlgorithmModel: model.NewAlgorithmModel(db),
+		AdminOnly:      middleware.NewAdminOnlyMiddleware().Handle,
+	}
+}
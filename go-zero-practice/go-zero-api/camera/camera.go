@@ -0,0 +1,40 @@
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+
+	"go-zero-api/camera/internal/config"
+	"go-zero-api/camera/internal/handler"
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/common/apidoc"
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/core/conf"
+	"github.com/tal-tech/go-zero/rest"
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+var configFile = flag.String("f", "etc/camera-api.yaml", "the config file")
+
+//go:embed camera.api
+var api []byte
+
+func main() {
+	flag.Parse()
+
+	var c config.Config
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
+
+	ctx := svc.NewServiceContext(c)
+	server := rest.MustNewServer(c.RestConf)
+	defer server.Stop()
+
+	handler.RegisterHandlers(server, ctx)
+	apidoc.MustRegisterHandlers(server, api)
+	httpx.SetErrorHandler(errorx.Handler)
+
+	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
+	server.Start()
+}
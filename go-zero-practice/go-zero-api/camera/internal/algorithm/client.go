@@ -0,0 +1,101 @@
+package algorithm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// StatusDeprecated is the status of the algorithm versions that can not be assigned.
+	StatusDeprecated = "deprecated"
+
+	// serviceUser is the user of the tokens of the camera-api
+	serviceUser   = "camera-api"
+	tokenDuration = time.Minute
+)
+
+// ErrNotFound means the algorithm-api has no algorithm with the id.
+var ErrNotFound = errors.New("algorithm not found")
+
+type (
+	// Algorithm is the part of the algorithm-api response the cameras need.
+	Algorithm struct {
+		Id      int64  `json:"id"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Status  string `json:"status"`
+	}
+
+	// Client reads the algorithms of the algorithm-api.
+	Client interface {
+		Get(ctx context.Context, id int64) (*Algorithm, error)
+	}
+
+	httpClient struct {
+		endpoint string
+		secret   string
+		client   *http.Client
+	}
+)
+
+// NewClient returns a Client of the algorithm-api at endpoint,
+// the requests carry tokens signed with secret, the access secret shared by the services.
+func NewClient(endpoint, secret string, timeout time.Duration) Client {
+	return &httpClient{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		secret:   secret,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *httpClient) Get(ctx context.Context, id int64) (*Algorithm, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/algorithms/%d", c.endpoint, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("algorithm-api responded %s", resp.Status)
+	}
+
+	var algorithm Algorithm
+	if err := json.NewDecoder(resp.Body).Decode(&algorithm); err != nil {
+		return nil, err
+	}
+
+	return &algorithm, nil
+}
+
+func (c *httpClient) token() (string, error) {
+	now := time.Now().Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId": serviceUser,
+		"type":   "access",
+		"iat":    now,
+		"exp":    now + int64(tokenDuration/time.Second),
+	})
+	return token.SignedString([]byte(c.secret))
+}
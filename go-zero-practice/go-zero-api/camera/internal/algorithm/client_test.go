@@ -0,0 +1,64 @@
+package algorithm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const testSecret = "secret"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return []byte(testSecret), nil
+		})
+		if err != nil || !token.Valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/algorithms/1":
+			w.Write([]byte(`{"id":1,"name":"face-detect","version":"1.0.0","image":"face:1","status":"active"}`))
+		case "/algorithms/2":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClientGet(t *testing.T) {
+	server := newTestServer(t)
+	client := NewClient(server.URL+"/", testSecret, time.Second)
+
+	algorithm, err := client.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if algorithm.Id != 1 || algorithm.Name != "face-detect" || algorithm.Status != "active" {
+		t.Fatalf("unexpected algorithm: %+v", algorithm)
+	}
+
+	if _, err := client.Get(context.Background(), 3); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+	if _, err := client.Get(context.Background(), 2); err == nil || err == ErrNotFound {
+		t.Fatalf("expect server error, got %v", err)
+	}
+
+	wrongSecret := NewClient(server.URL, "wrong", time.Second)
+	if _, err := wrongSecret.Get(context.Background(), 1); err == nil {
+		t.Fatal("expect error with wrong secret")
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import "github.com/tal-tech/go-zero/rest"
+
+type Config struct {
+	rest.RestConf
+	Database struct {
+		// DataSource is the dsn of the database, its scheme chooses the driver:
+		// sqlite://, mysql:// or postgres://
+		DataSource string `json:",default=sqlite:///data/camera.db"`
+	}
+	// Auth accepts the access tokens of the user-api, AccessSecret is ${ACCESS_SECRET} of its environment
+	Auth struct {
+		AccessSecret string
+	}
+	// OfflineAfter is the seconds without heartbeat after which a camera is offline
+	OfflineAfter int64 `json:",default=90"`
+	// Algorithm is the algorithm-api that the assigned algorithms are checked against,
+	// the algorithms are not checked without Endpoint
+	Algorithm struct {
+		Endpoint string `json:",optional"`
+		Timeout  int64  `json:",default=3000"`
+	}
+}
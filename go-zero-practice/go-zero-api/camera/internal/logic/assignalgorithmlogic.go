@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go-zero-api/camera/internal/algorithm"
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type AssignAlgorithmLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAssignAlgorithmLogic(ctx context.Context, svcCtx *svc.ServiceContext) AssignAlgorithmLogic {
+	return AssignAlgorithmLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AssignAlgorithmLogic) AssignAlgorithm(req types.AssignAlgorithmRequest) (*types.Camera, error) {
+	// 0 takes the algorithm back from the camera
+	if req.AlgorithmId < 0 {
+		return nil, errorx.NewBadRequest("algorithmId must not be negative")
+	}
+	if req.AlgorithmId > 0 {
+		if err := l.checkAlgorithm(req.AlgorithmId); err != nil {
+			return nil, err
+		}
+	}
+
+	item, err := l.svcCtx.CameraModel.SetAlgorithm(req.Id, req.AlgorithmId)
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	return convert(l.svcCtx, item), nil
+}
+
+func (l *AssignAlgorithmLogic) checkAlgorithm(id int64) error {
+	if l.svcCtx.Algorithms == nil {
+		return nil
+	}
+
+	item, err := l.svcCtx.Algorithms.Get(l.ctx, id)
+	switch err {
+	case nil:
+	case algorithm.ErrNotFound:
+		return errorx.NewBadRequest(err.Error())
+	default:
+		l.Errorf("get algorithm %d failed: %v", id, err)
+		return errorx.New(http.StatusBadGateway, "failed to check the algorithm")
+	}
+
+	if item.Status == algorithm.StatusDeprecated {
+		return errorx.NewBadRequest(fmt.Sprintf("algorithm %s %s is deprecated", item.Name, item.Version))
+	}
+
+	return nil
+}
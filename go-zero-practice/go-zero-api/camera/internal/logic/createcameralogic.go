@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"go-zero-api/camera/internal/model"
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type CreateCameraLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateCameraLogic(ctx context.Context, svcCtx *svc.ServiceContext) CreateCameraLogic {
+	return CreateCameraLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateCameraLogic) CreateCamera(req types.CreateCameraRequest) (*types.Camera, error) {
+	item, err := newCamera(req.Name, req.StreamUrl, req.Location, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.CameraModel.Insert(item); err != nil {
+		return nil, modelError(err)
+	}
+
+	return convert(l.svcCtx, item), nil
+}
+
+// newCamera returns the camera of the validated fields.
+func newCamera(name, streamUrl, location string, tags []string) (*model.Camera, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateStreamUrl(streamUrl); err != nil {
+		return nil, err
+	}
+	location, err = validateLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	tags, err = normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Camera{
+		Name:      name,
+		StreamUrl: streamUrl,
+		Location:  location,
+		Tags:      tags,
+	}, nil
+}
+
+// onlineSince returns the oldest heartbeat of the online cameras.
+func onlineSince(svcCtx *svc.ServiceContext) time.Time {
+	return time.Now().Add(-time.Duration(svcCtx.Config.OfflineAfter) * time.Second)
+}
+
+func convert(svcCtx *svc.ServiceContext, item *model.Camera) *types.Camera {
+	resp := &types.Camera{
+		Id:          item.Id,
+		Name:        item.Name,
+		StreamUrl:   item.StreamUrl,
+		Location:    item.Location,
+		Tags:        item.Tags,
+		AlgorithmId: item.AlgorithmId,
+		Status:      item.Status(onlineSince(svcCtx)),
+		CreateTime:  item.CreateTime.Unix(),
+		UpdateTime:  item.UpdateTime.Unix(),
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	if item.LastHeartbeat != nil {
+		resp.LastHeartbeat = item.LastHeartbeat.Unix()
+	}
+
+	return resp
+}
+
+// modelError maps the errors of the model to the responses.
+func modelError(err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return errorx.NewNotFound(err.Error())
+	case model.ErrDuplicate:
+		return errorx.NewConflict(err.Error())
+	default:
+		return err
+	}
+}
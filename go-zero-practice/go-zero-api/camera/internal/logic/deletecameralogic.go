@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type DeleteCameraLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteCameraLogic(ctx context.Context, svcCtx *svc.ServiceContext) DeleteCameraLogic {
+	return DeleteCameraLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteCameraLogic) DeleteCamera(req types.CameraRequest) error {
+	if err := l.svcCtx.CameraModel.Delete(req.Id); err != nil {
+		return modelError(err)
+	}
+
+	l.Infof("camera %d deleted", req.Id)
+	return nil
+}
@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type GetCameraLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCameraLogic(ctx context.Context, svcCtx *svc.ServiceContext) GetCameraLogic {
+	return GetCameraLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCameraLogic) GetCamera(req types.CameraRequest) (*types.Camera, error) {
+	item, err := l.svcCtx.CameraModel.FindOne(req.Id)
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	return convert(l.svcCtx, item), nil
+}
@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type HeartbeatLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewHeartbeatLogic(ctx context.Context, svcCtx *svc.ServiceContext) HeartbeatLogic {
+	return HeartbeatLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *HeartbeatLogic) Heartbeat(req types.CameraRequest) (*types.Camera, error) {
+	item, err := l.svcCtx.CameraModel.Heartbeat(req.Id, time.Now())
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	return convert(l.svcCtx, item), nil
+}
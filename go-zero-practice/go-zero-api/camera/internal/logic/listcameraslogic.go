@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"strings"
+
+	"go-zero-api/camera/internal/model"
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ListCamerasLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCamerasLogic(ctx context.Context, svcCtx *svc.ServiceContext) ListCamerasLogic {
+	return ListCamerasLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListCamerasLogic) ListCameras(req types.ListCamerasRequest) (*types.ListCamerasResponse, error) {
+	filter := model.Filter{
+		Tag:         strings.ToLower(req.Tag),
+		Status:      req.Status,
+		OnlineSince: onlineSince(l.svcCtx),
+	}
+	items, total, err := l.svcCtx.CameraModel.List(filter, (req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.ListCamerasResponse{
+		Total:   total,
+		Cameras: make([]types.Camera, len(items)),
+	}
+	for i, item := range items {
+		resp.Cameras[i] = *convert(l.svcCtx, item)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/camera/internal/svc"
+	"go-zero-api/camera/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type UpdateCameraLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateCameraLogic(ctx context.Context, svcCtx *svc.ServiceContext) UpdateCameraLogic {
+	return UpdateCameraLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateCameraLogic) UpdateCamera(req types.UpdateCameraRequest) (*types.Camera, error) {
+	item, err := newCamera(req.Name, req.StreamUrl, req.Location, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Id = req.Id
+	if err := l.svcCtx.CameraModel.Update(item); err != nil {
+		return nil, modelError(err)
+	}
+
+	item, err = l.svcCtx.CameraModel.FindOne(req.Id)
+	if err != nil {
+		return nil, modelError(err)
+	}
+
+	return convert(l.svcCtx, item), nil
+}
@@ -0,0 +1,80 @@
+package logic
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go-zero-api/common/errorx"
+)
+
+const (
+	maxNameLength      = 64
+	maxStreamUrlLength = 1024
+	maxLocationLength  = 255
+	maxTags            = 16
+)
+
+var (
+	tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,31}$`)
+	// the schemes of the video streams
+	streamSchemes = map[string]bool{
+		"rtsp":  true,
+		"rtsps": true,
+		"rtmp":  true,
+		"http":  true,
+		"https": true,
+	}
+)
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || utf8.RuneCountInString(name) > maxNameLength {
+		return "", errorx.NewBadRequest(fmt.Sprintf("name must be 1 to %d characters", maxNameLength))
+	}
+
+	return name, nil
+}
+
+func validateStreamUrl(streamUrl string) error {
+	u, err := url.Parse(streamUrl)
+	if err != nil || len(streamUrl) > maxStreamUrlLength || !streamSchemes[strings.ToLower(u.Scheme)] || len(u.Hostname()) == 0 {
+		return errorx.NewBadRequest("streamUrl must be a rtsp, rtsps, rtmp, http or https url")
+	}
+
+	return nil
+}
+
+func validateLocation(location string) (string, error) {
+	location = strings.TrimSpace(location)
+	if utf8.RuneCountInString(location) > maxLocationLength {
+		return "", errorx.NewBadRequest(fmt.Sprintf("location must be at most %d characters", maxLocationLength))
+	}
+
+	return location, nil
+}
+
+// normalizeTags returns the sorted tags in lower case without duplicates.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	resp := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, errorx.NewBadRequest(fmt.Sprintf("tag %q must be 1 to 32 lower case letters, digits, dots, dashes or underscores", tag))
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			resp = append(resp, tag)
+		}
+	}
+	if len(resp) > maxTags {
+		return nil, errorx.NewBadRequest(fmt.Sprintf("at most %d tags", maxTags))
+	}
+
+	sort.Strings(resp)
+	return resp, nil
+}
@@ -0,0 +1,256 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"go-zero-api/common/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StatusOnline is the status of the cameras that sent a heartbeat lately.
+	StatusOnline = "online"
+	// StatusOffline is the status of the other cameras.
+	StatusOffline = "offline"
+)
+
+var (
+	// ErrNotFound means there is no camera with the id.
+	ErrNotFound = errors.New("camera not found")
+	// ErrDuplicate means the name is taken by another camera.
+	ErrDuplicate = errors.New("camera name is already taken")
+)
+
+type (
+	// Camera is a registered video source.
+	Camera struct {
+		Id            int64    `gorm:"primaryKey"`
+		Name          string   `gorm:"size:64;not null"`
+		StreamUrl     string   `gorm:"size:1024;not null"`
+		Location      string   `gorm:"size:255;not null;default:''"`
+		Tags          []string `gorm:"-"`
+		AlgorithmId   int64    `gorm:"not null;default:0"`
+		LastHeartbeat *time.Time
+		CreateTime    time.Time `gorm:"autoCreateTime"`
+		UpdateTime    time.Time `gorm:"autoUpdateTime"`
+	}
+
+	// CameraTag tags a camera, the tags are kept in their own table to filter by them.
+	CameraTag struct {
+		CameraId int64  `gorm:"primaryKey;autoIncrement:false"`
+		Tag      string `gorm:"primaryKey;size:32"`
+	}
+
+	// Filter selects the cameras to list, the zero fields select all.
+	Filter struct {
+		Tag    string
+		Status string
+		// OnlineSince is the oldest heartbeat of the online cameras
+		OnlineSince time.Time
+	}
+
+	// CameraModel keeps the cameras.
+	CameraModel interface {
+		Insert(data *Camera) error
+		FindOne(id int64) (*Camera, error)
+		List(filter Filter, offset, limit int64) ([]*Camera, int64, error)
+		// Update changes the name, the stream url, the location and the tags
+		Update(data *Camera) error
+		Delete(id int64) error
+		SetAlgorithm(id, algorithmId int64) (*Camera, error)
+		Heartbeat(id int64, at time.Time) (*Camera, error)
+	}
+
+	defaultCameraModel struct {
+		db *gorm.DB
+	}
+)
+
+// NewCameraModel returns a CameraModel that keeps the cameras in db.
+func NewCameraModel(db *gorm.DB) CameraModel {
+	return &defaultCameraModel{db: db}
+}
+
+// Status returns the status of the camera, it is online if the last heartbeat is after since.
+func (c *Camera) Status(since time.Time) string {
+	if c.LastHeartbeat != nil && !c.LastHeartbeat.Before(since) {
+		return StatusOnline
+	}
+
+	return StatusOffline
+}
+
+func (m *defaultCameraModel) Insert(data *Camera) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(data).Error; err != nil {
+			return err
+		}
+		return saveTags(tx, data.Id, data.Tags)
+	})
+	if database.IsDuplicate(err) {
+		return ErrDuplicate
+	}
+
+	return err
+}
+
+func (m *defaultCameraModel) FindOne(id int64) (*Camera, error) {
+	item, err := findOne(m.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadTags(m.db, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (m *defaultCameraModel) List(filter Filter, offset, limit int64) ([]*Camera, int64, error) {
+	query := m.db.Model(&Camera{})
+	if len(filter.Tag) > 0 {
+		query = query.Where("id IN (?)", m.db.Model(&CameraTag{}).Select("camera_id").Where("tag = ?", filter.Tag))
+	}
+	// sqlite compares the times as strings, the heartbeats are kept in utc
+	switch filter.Status {
+	case StatusOnline:
+		query = query.Where("last_heartbeat >= ?", filter.OnlineSince.UTC())
+	case StatusOffline:
+		query = query.Where("last_heartbeat IS NULL OR last_heartbeat < ?", filter.OnlineSince.UTC())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var resp []*Camera
+	err := query.Order("id").
+		Offset(int(offset)).
+		Limit(int(limit)).
+		Find(&resp).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := loadTags(m.db, resp...); err != nil {
+		return nil, 0, err
+	}
+
+	return resp, total, nil
+}
+
+func (m *defaultCameraModel) Update(data *Camera) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		// mysql counts the changed rows only, so the affected rows tell nothing
+		if _, err := findOne(tx, data.Id); err != nil {
+			return err
+		}
+
+		err := tx.Model(&Camera{Id: data.Id}).
+			Select("name", "stream_url", "location", "update_time").
+			Updates(data).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("camera_id = ?", data.Id).Delete(&CameraTag{}).Error; err != nil {
+			return err
+		}
+		return saveTags(tx, data.Id, data.Tags)
+	})
+	if database.IsDuplicate(err) {
+		return ErrDuplicate
+	}
+
+	return err
+}
+
+func (m *defaultCameraModel) Delete(id int64) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Camera{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return tx.Where("camera_id = ?", id).Delete(&CameraTag{}).Error
+	})
+}
+
+func (m *defaultCameraModel) SetAlgorithm(id, algorithmId int64) (*Camera, error) {
+	return m.update(id, map[string]interface{}{"algorithm_id": algorithmId})
+}
+
+func (m *defaultCameraModel) Heartbeat(id int64, at time.Time) (*Camera, error) {
+	// heartbeats do not change the update time, it tracks the changes of the settings
+	return m.update(id, map[string]interface{}{"last_heartbeat": at.UTC(), "update_time": gorm.Expr("update_time")})
+}
+
+func (m *defaultCameraModel) update(id int64, values map[string]interface{}) (*Camera, error) {
+	if _, err := findOne(m.db, id); err != nil {
+		return nil, err
+	}
+
+	if err := m.db.Model(&Camera{Id: id}).Updates(values).Error; err != nil {
+		return nil, err
+	}
+
+	return m.FindOne(id)
+}
+
+func findOne(db *gorm.DB, id int64) (*Camera, error) {
+	var resp Camera
+	err := db.Take(&resp, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func saveTags(tx *gorm.DB, id int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	rows := make([]CameraTag, len(tags))
+	for i, tag := range tags {
+		rows[i] = CameraTag{CameraId: id, Tag: tag}
+	}
+
+	return tx.Create(&rows).Error
+}
+
+func loadTags(db *gorm.DB, items ...*Camera) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(items))
+	byId := make(map[int64]*Camera, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+		byId[item.Id] = item
+		item.Tags = []string{}
+	}
+
+	var tags []CameraTag
+	if err := db.Where("camera_id IN ?", ids).Order("tag").Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		item := byId[tag.CameraId]
+		item.Tags = append(item.Tags, tag.Tag)
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-zero-api/common/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestModel(t *testing.T) CameraModel {
+	dsn := fmt.Sprintf("sqlite://file:%s?mode=memory&cache=shared", t.Name())
+	db, err := database.Open(dsn, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewCameraModel(db)
+}
+
+func mustInsert(t *testing.T, m CameraModel, name string, tags ...string) *Camera {
+	t.Helper()
+
+	item := &Camera{
+		Name:      name,
+		StreamUrl: "rtsp://10.0.0.1/" + name,
+		Location:  "gate",
+		Tags:      tags,
+	}
+	if err := m.Insert(item); err != nil {
+		t.Fatal(err)
+	}
+
+	return item
+}
+
+func TestInsertFindUpdateDelete(t *testing.T) {
+	m := newTestModel(t)
+
+	item := mustInsert(t, m, "gate-1", "entrance", "outdoor")
+	if item.Id == 0 || item.CreateTime.IsZero() {
+		t.Fatalf("unexpected inserted camera: %+v", item)
+	}
+	if err := m.Insert(&Camera{Name: "gate-1", StreamUrl: "rtsp://10.0.0.2/"}); err != ErrDuplicate {
+		t.Fatalf("expect ErrDuplicate, got %v", err)
+	}
+
+	found, err := m.FindOne(item.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Name != "gate-1" || !reflect.DeepEqual(found.Tags, []string{"entrance", "outdoor"}) {
+		t.Fatalf("unexpected camera: %+v", found)
+	}
+
+	found.Name = "gate-one"
+	found.Location = "north gate"
+	found.Tags = []string{"indoor"}
+	if err := m.Update(found); err != nil {
+		t.Fatal(err)
+	}
+	// updating without changes is fine
+	if err := m.Update(found); err != nil {
+		t.Fatal(err)
+	}
+	found, err = m.FindOne(item.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Name != "gate-one" || found.Location != "north gate" || !reflect.DeepEqual(found.Tags, []string{"indoor"}) {
+		t.Fatalf("unexpected updated camera: %+v", found)
+	}
+
+	other := mustInsert(t, m, "gate-2")
+	other.Name = "gate-one"
+	if err := m.Update(other); err != ErrDuplicate {
+		t.Fatalf("expect ErrDuplicate, got %v", err)
+	}
+	if err := m.Update(&Camera{Id: 0, Name: "none"}); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+
+	if err := m.Delete(item.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.FindOne(item.Id); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+	if err := m.Delete(item.Id); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+}
+
+func TestSetAlgorithmAndHeartbeat(t *testing.T) {
+	m := newTestModel(t)
+
+	item := mustInsert(t, m, "gate-1")
+	now := time.Now()
+	if item.Status(now.Add(-time.Minute)) != StatusOffline {
+		t.Fatal("expect new camera offline")
+	}
+
+	found, err := m.SetAlgorithm(item.Id, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.AlgorithmId != 7 {
+		t.Fatalf("expect algorithm 7, got %d", found.AlgorithmId)
+	}
+	// assigning the same algorithm again is fine
+	if _, err := m.SetAlgorithm(item.Id, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err = m.Heartbeat(item.Id, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.LastHeartbeat == nil || found.Status(now.Add(-time.Minute)) != StatusOnline {
+		t.Fatalf("expect online camera, got %+v", found)
+	}
+	if found.Status(now.Add(time.Minute)) != StatusOffline {
+		t.Fatal("expect camera offline after the timeout")
+	}
+
+	if _, err := m.SetAlgorithm(0, 7); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+	if _, err := m.Heartbeat(0, now); err != ErrNotFound {
+		t.Fatalf("expect ErrNotFound, got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	m := newTestModel(t)
+
+	now := time.Now()
+	gate1 := mustInsert(t, m, "gate-1", "entrance", "outdoor")
+	gate2 := mustInsert(t, m, "gate-2", "entrance")
+	mustInsert(t, m, "hall", "indoor")
+	if _, err := m.Heartbeat(gate1.Id, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Heartbeat(gate2.Id, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	since := now.Add(-time.Minute)
+	tests := []struct {
+		name   string
+		filter Filter
+		expect []string
+	}{
+		{"all", Filter{OnlineSince: since}, []string{"gate-1", "gate-2", "hall"}},
+		{"by tag", Filter{Tag: "entrance", OnlineSince: since}, []string{"gate-1", "gate-2"}},
+		{"online", Filter{Status: StatusOnline, OnlineSince: since}, []string{"gate-1"}},
+		{"offline", Filter{Status: StatusOffline, OnlineSince: since}, []string{"gate-2", "hall"}},
+		{"tag and status", Filter{Tag: "entrance", Status: StatusOffline, OnlineSince: since}, []string{"gate-2"}},
+		{"unknown tag", Filter{Tag: "roof", OnlineSince: since}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, total, err := m.List(test.filter, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != int64(len(test.expect)) || len(items) != len(test.expect) {
+				t.Fatalf("expect %v, got %d items of %d", test.expect, len(items), total)
+			}
+			for i, item := range items {
+				if item.Name != test.expect[i] {
+					t.Fatalf("expect %s at %d, got %s", test.expect[i], i, item.Name)
+				}
+			}
+		})
+	}
+
+	items, total, err := m.List(Filter{OnlineSince: since}, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(items) != 1 || items[0].Name != "gate-2" || !reflect.DeepEqual(items[0].Tags, []string{"entrance"}) {
+		t.Fatalf("unexpected page: %d %+v", total, items)
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go-zero-api/common/database"
+	"go-zero-api/common/migrate"
+
+	"gorm.io/gorm"
+)
+
+const migrationTable = "camera_schema_migrations"
+
+// migrations are applied in order, never change an applied migration, add a new one instead.
+var migrations = []migrate.Migration{
+	{Version: 1, Name: "create cameras", Up: createCameras},
+}
+
+// Migrate applies the pending migrations of the camera-api to db.
+func Migrate(db *gorm.DB) error {
+	return migrate.Migrate(db, migrationTable, migrations)
+}
+
+// MustOpen opens the database of dsn and migrates it to the latest version, exits on error.
+func MustOpen(dsn string) *gorm.DB {
+	db, err := database.Open(dsn, nil)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect database: %w", err))
+	}
+
+	if err := Migrate(db); err != nil {
+		panic(fmt.Errorf("failed to migrate database: %w", err))
+	}
+
+	return db
+}
+
+type (
+	cameraV1 struct {
+		Id            int64      `gorm:"primaryKey"`
+		Name          string     `gorm:"size:64;not null;uniqueIndex:idx_cameras_name"`
+		StreamUrl     string     `gorm:"size:1024;not null"`
+		Location      string     `gorm:"size:255;not null;default:''"`
+		AlgorithmId   int64      `gorm:"not null;default:0;index:idx_cameras_algorithm_id"`
+		LastHeartbeat *time.Time `gorm:"index:idx_cameras_last_heartbeat"`
+		CreateTime    time.Time  `gorm:"not null"`
+		UpdateTime    time.Time  `gorm:"not null"`
+	}
+
+	cameraTagV1 struct {
+		CameraId int64  `gorm:"primaryKey;autoIncrement:false"`
+		Tag      string `gorm:"primaryKey;size:32;index:idx_camera_tags_tag"`
+	}
+)
+
+func (cameraV1) TableName() string {
+	return "cameras"
+}
+
+func (cameraTagV1) TableName() string {
+	return "camera_tags"
+}
+
+func createCameras(tx *gorm.DB) error {
+	return migrate.CreateTables(tx, &cameraV1{}, &cameraTagV1{})
+}
@@ -0,0 +1,38 @@
+package svc
+
+import (
+	"time"
+
+	"go-zero-api/camera/internal/algorithm"
+	"go-zero-api/camera/internal/config"
+	"go-zero-api/camera/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ServiceContext struct {
+	Config      config.Config
+	DB          *gorm.DB
+	CameraModel model.CameraModel
+	// Algorithms is nil if the assigned algorithms are not checked
+	Algorithms algorithm.Client
+}
+
+func NewServiceContext(c config.Config) *ServiceContext {
+	if len(c.Auth.AccessSecret) == 0 {
+		panic("Auth.AccessSecret is empty, set ACCESS_SECRET")
+	}
+
+	db := model.MustOpen(c.Database.DataSource)
+	ctx := &ServiceContext{
+		Config:      c,
+		DB:          db,
+		CameraModel: model.NewCameraModel(db),
+	}
+	if len(c.Algorithm.Endpoint) > 0 {
+		ctx.Algorithms = algorithm.NewClient(c.Algorithm.Endpoint, c.Auth.AccessSecret,
+			time.Duration(c.Algorithm.Timeout)*time.Millisecond)
+	}
+
+	return ctx
+}
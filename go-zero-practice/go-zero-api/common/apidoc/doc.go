@@ -0,0 +1,4 @@
+// Package apidoc turns the goctl .api files into OpenAPI documents. The services embed
+// their .api file and serve its document, with a Swagger UI, so the clients see the
+// contract the binary was built with.
+package apidoc
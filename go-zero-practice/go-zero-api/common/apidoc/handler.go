@@ -0,0 +1,157 @@
+package apidoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/rest"
+)
+
+const (
+	// DocumentPath is the route of the OpenAPI document.
+	DocumentPath = "/swagger.json"
+	// UIPath is the route of the Swagger UI page.
+	UIPath = "/swagger"
+)
+
+type asset struct {
+	name        string
+	contentType string
+}
+
+// uiAssets are served under UIPath, the page loads them from there
+var uiAssets = []asset{
+	{"swagger-ui.css", "text/css; charset=utf-8"},
+	{"swagger-ui-bundle.js", "application/javascript; charset=utf-8"},
+}
+
+var uiPage = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="{{.Base}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{.Base}}/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({url: {{.Url}}, dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`))
+
+// Marshal parses the .api file content api and returns its OpenAPI document as json.
+func Marshal(api []byte) ([]byte, error) {
+	_, doc, err := marshal(api)
+	return doc, err
+}
+
+func marshal(api []byte) (*Spec, []byte, error) {
+	spec, err := Parse(api)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := Generate(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	return spec, data, err
+}
+
+// Routes returns the routes that serve the OpenAPI document of the .api file
+// content api, and the Swagger UI of it when its assets are embedded.
+func Routes(api []byte) ([]rest.Route, error) {
+	return routes(api, uiFiles)
+}
+
+func routes(api []byte, files fs.FS) ([]rest.Route, error) {
+	spec, doc, err := marshal(api)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    DocumentPath,
+			Handler: serve("application/json; charset=utf-8", doc),
+		},
+	}
+
+	ui, err := uiRoutes(spec, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(routes, ui...), nil
+}
+
+// uiRoutes returns the routes of the Swagger UI page and its assets, none if
+// the assets are not embedded.
+func uiRoutes(spec *Spec, files fs.FS) ([]rest.Route, error) {
+	var page bytes.Buffer
+	if err := uiPage.Execute(&page, map[string]string{
+		"Title": spec.Info["title"] + " api",
+		"Base":  UIPath,
+		"Url":   DocumentPath,
+	}); err != nil {
+		return nil, err
+	}
+
+	routes := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    UIPath,
+			Handler: serve("text/html; charset=utf-8", page.Bytes()),
+		},
+	}
+	for _, asset := range uiAssets {
+		data, err := fs.ReadFile(files, path.Join("ui", asset.name))
+		if errors.Is(err, fs.ErrNotExist) {
+			logx.Infof("the swagger ui assets are not embedded, see common/apidoc/ui/README.md")
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		routes = append(routes, rest.Route{
+			Method:  http.MethodGet,
+			Path:    path.Join(UIPath, asset.name),
+			Handler: serve(asset.contentType, data),
+		})
+	}
+
+	return routes, nil
+}
+
+// MustRegisterHandlers adds the routes of the document and the ui to server,
+// it panics if the .api file content api is invalid.
+func MustRegisterHandlers(server *rest.Server, api []byte) {
+	routes, err := Routes(api)
+	if err != nil {
+		panic(fmt.Errorf("generate openapi document: %w", err))
+	}
+
+	server.AddRoutes(routes)
+}
+
+func serve(contentType string, body []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
@@ -0,0 +1,68 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRoutes(t *testing.T) {
+	files := fstest.MapFS{
+		"ui/swagger-ui.css":       {Data: []byte("body {}")},
+		"ui/swagger-ui-bundle.js": {Data: []byte("var SwaggerUIBundle;")},
+	}
+	routes, err := routes([]byte(testApi), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 4 || routes[0].Path != DocumentPath || routes[1].Path != UIPath ||
+		routes[2].Path != "/swagger/swagger-ui.css" || routes[3].Path != "/swagger/swagger-ui-bundle.js" {
+		t.Fatalf("unexpected routes: %+v", routes)
+	}
+
+	w := httptest.NewRecorder()
+	routes[0].Handler(w, httptest.NewRequest(http.MethodGet, DocumentPath, nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("unexpected response: %d %v", w.Code, w.Header())
+	}
+	var doc Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title != "test" || len(doc.Paths) != 2 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	w = httptest.NewRecorder()
+	routes[1].Handler(w, httptest.NewRequest(http.MethodGet, UIPath, nil))
+	if body := w.Body.String(); !strings.Contains(body, `"/swagger.json"`) || !strings.Contains(body, "<title>test api</title>") {
+		t.Fatalf("unexpected page: %s", body)
+	}
+	// the page loads no third-party code
+	if body := w.Body.String(); strings.Contains(body, "https://") {
+		t.Fatalf("expect the assets served by the service, got %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	routes[3].Handler(w, httptest.NewRequest(http.MethodGet, routes[3].Path, nil))
+	if w.Body.String() != "var SwaggerUIBundle;" || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/javascript") {
+		t.Fatalf("unexpected asset: %v %s", w.Header(), w.Body.String())
+	}
+
+	if _, err := Routes([]byte("nonsense\n")); err == nil {
+		t.Fatal("expect an error for an invalid api")
+	}
+}
+
+func TestRoutesWithoutAssets(t *testing.T) {
+	routes, err := routes([]byte(testApi), fstest.MapFS{"ui/README.md": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Path != DocumentPath {
+		t.Fatalf("expect the document only, got %+v", routes)
+	}
+}
@@ -0,0 +1,130 @@
+// fetchui downloads the Swagger UI assets of a pinned swagger-ui-dist version into a
+// directory. The tarball is checked against the sha512 integrity the npm registry
+// publishes for the version, the assets are committed and reviewed after that.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const registry = "https://registry.npmjs.org/swagger-ui-dist/"
+
+var (
+	version = flag.String("version", "", "the exact swagger-ui-dist version")
+	dir     = flag.String("dir", "ui", "the directory of the assets")
+	// the files of the package that are kept, the license goes with the assets
+	files = []string{"swagger-ui.css", "swagger-ui-bundle.js", "LICENSE"}
+)
+
+func main() {
+	flag.Parse()
+	if err := fetch(*version, *dir); err != nil {
+		fmt.Fprintln(os.Stderr, "fetchui:", err)
+		os.Exit(1)
+	}
+}
+
+func fetch(version, dir string) error {
+	if len(version) == 0 || strings.ContainsAny(version, "^~*x ") {
+		return fmt.Errorf("an exact version is required, got %q", version)
+	}
+
+	var meta struct {
+		Dist struct {
+			Tarball   string `json:"tarball"`
+			Integrity string `json:"integrity"`
+		} `json:"dist"`
+	}
+	data, err := get(registry + version)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(meta.Dist.Integrity, "sha512-") {
+		return fmt.Errorf("no sha512 integrity for %s", version)
+	}
+
+	tarball, err := get(meta.Dist.Tarball)
+	if err != nil {
+		return err
+	}
+	sum := sha512.Sum512(tarball)
+	if integrity := "sha512-" + base64.StdEncoding.EncodeToString(sum[:]); integrity != meta.Dist.Integrity {
+		return fmt.Errorf("the tarball has the integrity %s, want %s", integrity, meta.Dist.Integrity)
+	}
+
+	return extract(tarball, dir)
+}
+
+func get(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// extract writes the kept files of the npm tarball, whose entries are under package/.
+func extract(tarball []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(files))
+	for _, file := range files {
+		wanted[file] = true
+	}
+
+	r := tar.NewReader(gz)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(path.Clean(header.Name), "package/")
+		if header.Typeflag != tar.TypeReg || !wanted[name] {
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+		delete(wanted, name)
+	}
+
+	for file := range wanted {
+		return fmt.Errorf("the package has no %s", file)
+	}
+
+	return nil
+}
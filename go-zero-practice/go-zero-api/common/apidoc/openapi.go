@@ -0,0 +1,560 @@
+package apidoc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	// the version of the documents whose info block has none
+	defaultVersion = "1.0.0"
+
+	bearerAuth  = "bearerAuth"
+	errorSchema = "Error"
+	jsonMime    = "application/json"
+)
+
+type (
+	// Document is an OpenAPI 3 document.
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Tags       []Tag                `json:"tags,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components Components           `json:"components"`
+	}
+
+	// Info is the metadata of the api.
+	Info struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description,omitempty"`
+		Version     string   `json:"version"`
+		Contact     *Contact `json:"contact,omitempty"`
+	}
+
+	// Contact is the author of the api.
+	Contact struct {
+		Name  string `json:"name,omitempty"`
+		Email string `json:"email,omitempty"`
+	}
+
+	// Tag groups the operations of a service.
+	Tag struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem holds the operations of a path by lower case method.
+	PathItem map[string]*Operation
+
+	// Operation is a route.
+	Operation struct {
+		OperationId string                `json:"operationId"`
+		Summary     string                `json:"summary,omitempty"`
+		Tags        []string              `json:"tags,omitempty"`
+		Parameters  []*Parameter          `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+		// Middlewares are the go-zero middlewares of the route, like the permission checks
+		Middlewares []string `json:"x-middlewares,omitempty"`
+	}
+
+	// Parameter is a path, query or header parameter.
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	// RequestBody is the json body of a request.
+	RequestBody struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+
+	// Response is a response of an operation.
+	Response struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType is the schema of a body.
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	// Schema is a json schema, Ref excludes the other properties.
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Default              interface{}        `json:"default,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+		Example              interface{}        `json:"example,omitempty"`
+	}
+
+	// Components are the shared schemas and security schemes.
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme is the authentication of the routes.
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+	}
+)
+
+var (
+	pathParam  = regexp.MustCompile(`:(\w+)`)
+	rangeValue = regexp.MustCompile(`^([\[(])([^:]*):([^\])]*)([\])])$`)
+)
+
+// Generate returns the OpenAPI document of spec. The json fields of the types
+// are the schemas, the path, form and header fields are the parameters.
+func Generate(spec *Spec) (*Document, error) {
+	g := &generator{
+		spec: spec,
+		doc: &Document{
+			OpenAPI: openAPIVersion,
+			Info: Info{
+				Title:       spec.Info["title"],
+				Description: spec.Info["desc"],
+				Version:     spec.Info["version"],
+			},
+			Paths: map[string]*PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{
+					errorSchema: {
+						Type: "object",
+						Properties: map[string]*Schema{
+							"status":  {Type: "string", Description: "the http status code", Example: "400"},
+							"message": {Type: "string"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if g.doc.Info.Version == "" {
+		g.doc.Info.Version = defaultVersion
+	}
+	if author, email := spec.Info["author"], spec.Info["email"]; author != "" || email != "" {
+		g.doc.Info.Contact = &Contact{Name: author, Email: email}
+	}
+
+	// the schemas refer to each other, so the types with a body are found first
+	g.bodies = map[string]bool{}
+	for _, t := range spec.Types {
+		fields, err := g.fields(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			if parseTag(field).In == "body" {
+				g.bodies[t.Name] = true
+			}
+		}
+	}
+	for _, t := range spec.Types {
+		if err := g.addSchema(t); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := map[string]bool{}
+	for _, group := range spec.Groups {
+		if !tags[group.Service] {
+			tags[group.Service] = true
+			g.doc.Tags = append(g.doc.Tags, Tag{Name: group.Service})
+		}
+		for _, route := range group.Routes {
+			if err := g.addRoute(group, route); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+			}
+		}
+	}
+
+	return g.doc, nil
+}
+
+type generator struct {
+	spec *Spec
+	doc  *Document
+	// bodies holds the types that have json fields
+	bodies map[string]bool
+}
+
+// fieldInfo is the parsed tag of a field.
+type fieldInfo struct {
+	// In is body, path, query or header
+	In       string
+	Name     string
+	Optional bool
+	Default  string
+	Options  []string
+	Range    string
+}
+
+// fields returns the fields of t with the fields of the embedded types inlined.
+func (g *generator) fields(t *Type) ([]*Field, error) {
+	var fields []*Field
+	for _, field := range t.Fields {
+		if field.Name != "" {
+			fields = append(fields, field)
+			continue
+		}
+
+		embedded := g.spec.Type(strings.TrimPrefix(field.Type, "*"))
+		if embedded == nil {
+			return nil, fmt.Errorf("type %s embeds undefined %s", t.Name, field.Type)
+		}
+		inlined, err := g.fields(embedded)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, inlined...)
+	}
+
+	return fields, nil
+}
+
+// addSchema adds the body schema of t, the types without json fields have none.
+func (g *generator) addSchema(t *Type) error {
+	fields, err := g.fields(t)
+	if err != nil {
+		return err
+	}
+
+	schema := &Schema{Type: "object", Description: t.Doc, Properties: map[string]*Schema{}}
+	for _, field := range fields {
+		info := parseTag(field)
+		if info.In != "body" {
+			continue
+		}
+
+		property, err := g.fieldSchema(field, info)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name, field.Name, err)
+		}
+		schema.Properties[info.Name] = property
+		if !info.Optional {
+			schema.Required = append(schema.Required, info.Name)
+		}
+	}
+	if g.bodies[t.Name] {
+		g.doc.Components.Schemas[t.Name] = schema
+	}
+
+	return nil
+}
+
+func (g *generator) addRoute(group *Group, route *Route) error {
+	op := &Operation{
+		OperationId: lowerFirst(route.Handler),
+		Summary:     route.Doc,
+		Tags:        []string{group.Service},
+		Responses: map[string]*Response{
+			"default": {
+				Description: "the error",
+				Content:     jsonContent(&Schema{Ref: schemaRef(errorSchema)}),
+			},
+		},
+	}
+
+	if group.Server["jwt"] != "" {
+		if g.doc.Components.SecuritySchemes == nil {
+			g.doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			}
+		}
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+		// the jwt middleware of go-zero answers without a body
+		op.Responses["401"] = &Response{Description: "the token is missing or invalid"}
+	}
+	if middlewares := group.Server["middleware"]; middlewares != "" {
+		for _, name := range strings.Split(middlewares, ",") {
+			op.Middlewares = append(op.Middlewares, strings.TrimSpace(name))
+		}
+	}
+
+	if route.Request != "" {
+		if err := g.addRequest(op, route.Request); err != nil {
+			return err
+		}
+	}
+
+	ok := &Response{Description: "OK"}
+	if route.Response != "" {
+		schema, err := g.typeSchema(route.Response)
+		if err != nil {
+			return err
+		}
+		ok.Content = jsonContent(schema)
+	}
+	op.Responses["200"] = ok
+
+	path := pathParam.ReplaceAllString(route.Path, "{$1}")
+	item := g.doc.Paths[path]
+	if item == nil {
+		item = &PathItem{}
+		g.doc.Paths[path] = item
+	}
+	if (*item)[route.Method] != nil {
+		return fmt.Errorf("duplicate route")
+	}
+	(*item)[route.Method] = op
+
+	return nil
+}
+
+// addRequest adds the parameters and the body of the request type name to op.
+func (g *generator) addRequest(op *Operation, name string) error {
+	t := g.spec.Type(name)
+	fields, err := g.fields(t)
+	if err != nil {
+		return err
+	}
+
+	hasBody := false
+	for _, field := range fields {
+		info := parseTag(field)
+		if info.In == "body" {
+			hasBody = true
+			continue
+		}
+
+		schema, err := g.fieldSchema(field, info)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name, field.Name, err)
+		}
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        info.Name,
+			In:          info.In,
+			Description: field.Doc,
+			Required:    info.In == "path" || !info.Optional,
+			Schema:      schema,
+		})
+	}
+	if hasBody {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(&Schema{Ref: schemaRef(t.Name)}),
+		}
+	}
+
+	return nil
+}
+
+// fieldSchema returns the schema of field with the constraints of its tag.
+func (g *generator) fieldSchema(field *Field, info fieldInfo) (*Schema, error) {
+	schema, err := g.typeSchema(field.Type)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Ref != "" {
+		return schema, nil
+	}
+
+	schema.Description = field.Doc
+	for _, option := range info.Options {
+		value, err := typedValue(schema.Type, option)
+		if err != nil {
+			return nil, err
+		}
+		schema.Enum = append(schema.Enum, value)
+	}
+	if info.Default != "" {
+		if schema.Default, err = typedValue(schema.Type, info.Default); err != nil {
+			return nil, err
+		}
+	}
+	if info.Range != "" {
+		if err := setRange(schema, info.Range); err != nil {
+			return nil, err
+		}
+	}
+	if field.Example != "" {
+		target := schema
+		if schema.Type == "array" {
+			target = schema.Items
+		}
+		if target.Example, err = typedValue(target.Type, field.Example); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+// typeSchema returns the schema of a go type of the .api file.
+func (g *generator) typeSchema(typ string) (*Schema, error) {
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		return g.typeSchema(typ[1:])
+	case strings.HasPrefix(typ, "[]"):
+		items, err := g.typeSchema(typ[2:])
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case strings.HasPrefix(typ, "map[string]"):
+		values, err := g.typeSchema(strings.TrimPrefix(typ, "map[string]"))
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	}
+
+	switch typ {
+	case "string":
+		return &Schema{Type: "string"}, nil
+	case "bool":
+		return &Schema{Type: "boolean"}, nil
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}, nil
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}, nil
+	case "interface{}":
+		return &Schema{}, nil
+	}
+
+	if g.spec.Type(typ) == nil {
+		return nil, fmt.Errorf("undefined type %s", typ)
+	}
+	if !g.bodies[typ] {
+		return &Schema{Type: "object"}, nil
+	}
+
+	return &Schema{Ref: schemaRef(typ)}, nil
+}
+
+// parseTag reads the httpx tag of field, the fields without one are json fields.
+func parseTag(field *Field) fieldInfo {
+	for _, key := range []string{"path", "form", "header", "json"} {
+		value, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		parts := strings.Split(value, ",")
+		info := fieldInfo{Name: parts[0]}
+		switch key {
+		case "form":
+			info.In = "query"
+		case "json":
+			info.In = "body"
+		default:
+			info.In = key
+		}
+		for _, option := range parts[1:] {
+			switch {
+			case option == "optional", option == "omitempty":
+				info.Optional = true
+			case strings.HasPrefix(option, "default="):
+				info.Default = strings.TrimPrefix(option, "default=")
+				info.Optional = true
+			case strings.HasPrefix(option, "options="):
+				info.Options = strings.Split(strings.TrimPrefix(option, "options="), "|")
+			case strings.HasPrefix(option, "range="):
+				info.Range = strings.TrimPrefix(option, "range=")
+			}
+		}
+		return info
+	}
+
+	return fieldInfo{In: "body", Name: field.Name}
+}
+
+// setRange sets the bounds of a range like [1:100] or (0:] to schema.
+func setRange(schema *Schema, value string) error {
+	m := rangeValue.FindStringSubmatch(value)
+	if m == nil {
+		return fmt.Errorf("bad range %q", value)
+	}
+
+	if m[2] != "" {
+		min, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return fmt.Errorf("bad range %q", value)
+		}
+		schema.Minimum = &min
+		schema.ExclusiveMinimum = m[1] == "("
+	}
+	if m[3] != "" {
+		max, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return fmt.Errorf("bad range %q", value)
+		}
+		schema.Maximum = &max
+		schema.ExclusiveMaximum = m[4] == ")"
+	}
+
+	return nil
+}
+
+// typedValue converts the value of a tag or an example to the schema type.
+func typedValue(typ, value string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad integer %q", value)
+		}
+		return v, nil
+	case "number":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", value)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("bad boolean %q", value)
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{jsonMime: {Schema: schema}}
+}
+
+func schemaRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
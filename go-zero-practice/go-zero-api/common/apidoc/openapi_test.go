@@ -0,0 +1,111 @@
+package apidoc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	spec, err := Parse([]byte(testApi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != "test" || doc.Info.Version != "2.0.0" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	// the embedded fields are inlined, the optional fields are not required
+	item := doc.Components.Schemas["Item"]
+	if item == nil || len(item.Properties) != 4 {
+		t.Fatalf("unexpected Item schema: %+v", item)
+	}
+	if !reflect.DeepEqual(item.Required, []string{"id", "name"}) {
+		t.Fatalf("unexpected required: %v", item.Required)
+	}
+	if id := item.Properties["id"]; id.Type != "integer" || id.Format != "int64" || id.Example != int64(7) {
+		t.Fatalf("unexpected id: %+v", id)
+	}
+	if tags := item.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+	items := doc.Components.Schemas["ListResponse"].Properties["items"]
+	if items.Items.Ref != "#/components/schemas/Item" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	// the types without a body have no schema
+	if doc.Components.Schemas["ListRequest"] != nil || doc.Components.Schemas["ItemRequest"] != nil {
+		t.Fatal("expect no schemas of the parameter types")
+	}
+
+	create := (*doc.Paths["/items"])["post"]
+	if create.OperationId != "createItem" || create.Summary != "create an item" || create.Security != nil {
+		t.Fatalf("unexpected operation: %+v", create)
+	}
+	if create.RequestBody.Content[jsonMime].Schema.Ref != "#/components/schemas/Item" {
+		t.Fatalf("unexpected body: %+v", create.RequestBody)
+	}
+
+	list := (*doc.Paths["/items"])["get"]
+	if list.RequestBody != nil || len(list.Parameters) != 4 {
+		t.Fatalf("unexpected parameters: %+v", list.Parameters)
+	}
+	status, page, ratio, agent := list.Parameters[0], list.Parameters[1], list.Parameters[2], list.Parameters[3]
+	if status.In != "query" || status.Required || status.Description != "the status of the items" ||
+		!reflect.DeepEqual(status.Schema.Enum, []interface{}{"on", "off"}) {
+		t.Fatalf("unexpected status: %+v %+v", status, status.Schema)
+	}
+	if page.Required || page.Schema.Default != int64(1) || *page.Schema.Minimum != 1 || page.Schema.Maximum != nil {
+		t.Fatalf("unexpected page: %+v %+v", page, page.Schema)
+	}
+	if !ratio.Required || !ratio.Schema.ExclusiveMinimum || ratio.Schema.ExclusiveMaximum || *ratio.Schema.Maximum != 1 {
+		t.Fatalf("unexpected ratio: %+v %+v", ratio, ratio.Schema)
+	}
+	if agent.In != "header" || agent.Name != "User-Agent" {
+		t.Fatalf("unexpected agent: %+v", agent)
+	}
+
+	del := (*doc.Paths["/items/{id}"])["delete"]
+	if del == nil {
+		t.Fatal("expect the path parameters in braces")
+	}
+	if !reflect.DeepEqual(del.Security, []map[string][]string{{bearerAuth: {}}}) || del.Responses["401"] == nil {
+		t.Fatalf("expect the jwt security, got %+v", del)
+	}
+	if !reflect.DeepEqual(del.Middlewares, []string{"Authorize", "Audit"}) {
+		t.Fatalf("unexpected middlewares: %v", del.Middlewares)
+	}
+	if id := del.Parameters[0]; id.In != "path" || !id.Required {
+		t.Fatalf("unexpected id: %+v", id)
+	}
+	if ok := del.Responses["200"]; ok.Content != nil {
+		t.Fatalf("expect no content, got %+v", ok)
+	}
+	if doc.Components.SecuritySchemes[bearerAuth].Scheme != "bearer" {
+		t.Fatalf("unexpected security schemes: %+v", doc.Components.SecuritySchemes)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := map[string]string{
+		"undefined field type": "type A {\nB Missing `json:\"b\"`\n}\n",
+		"undefined embedded":   "type A {\nMissing\n}\n",
+		"bad default":          "type A {\nB int `form:\"b,default=x\"`\n}\nservice a {\n@handler A\nget /a (A)\n}\n",
+		"bad range":            "type A {\nB int `form:\"b,range=1:2\"`\n}\nservice a {\n@handler A\nget /a (A)\n}\n",
+		"bad example":          "type A {\nB bool `json:\"b\"` // example: maybe\n}\n",
+		"duplicate route":      "service a {\n@handler A\nget /a\n@handler B\nget /a\n}\n",
+	}
+	for name, src := range tests {
+		spec, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := Generate(spec); err == nil {
+			t.Errorf("%s: expect an error", name)
+		}
+	}
+}
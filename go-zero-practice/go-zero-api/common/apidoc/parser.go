@@ -0,0 +1,325 @@
+package apidoc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Spec is the content of an .api file.
+	Spec struct {
+		// Info holds the properties of the info block, like title and desc
+		Info   map[string]string
+		Types  []*Type
+		Groups []*Group
+	}
+
+	// Type is a type declaration of an .api file.
+	Type struct {
+		Name   string
+		Doc    string
+		Fields []*Field
+	}
+
+	// Field is a field of a Type, the embedded types have no Name.
+	Field struct {
+		Name string
+		Type string
+		Tag  reflect.StructTag
+		// Example is given by an "// example: value" comment after the field
+		Example string
+		Doc     string
+	}
+
+	// Group is a service block with the properties of its @server block.
+	Group struct {
+		Service string
+		Server  map[string]string
+		Routes  []*Route
+	}
+
+	// Route is a route of a service block, Request and Response are type names.
+	Route struct {
+		Method   string
+		Path     string
+		Handler  string
+		Request  string
+		Response string
+		Doc      string
+	}
+)
+
+var (
+	typeLine    = regexp.MustCompile(`^type\s+(\w+)\s*(?:struct\s*)?\{\s*(\})?$`)
+	groupType   = regexp.MustCompile(`^(\w+)\s*(?:struct\s*)?\{\s*(\})?$`)
+	fieldLine   = regexp.MustCompile("^(\\w+)(?:\\s+([^`/\\s]+))?\\s*(`[^`]*`)?\\s*(?://\\s*(.*))?$")
+	serviceLine = regexp.MustCompile(`^service\s+([\w-]+)\s*\{$`)
+	routeLine   = regexp.MustCompile(`^(get|head|post|put|patch|delete|options)\s+([^\s(]+)\s*(?:\(\s*(\w+)\s*\))?\s*(?:returns\s*\(\s*([\w\[\]*]+)\s*\))?$`)
+	propLine    = regexp.MustCompile(`^([\w-]+)\s*:\s*(.*)$`)
+)
+
+// Type returns the type of name, nil if none.
+func (s *Spec) Type(name string) *Type {
+	for _, t := range s.Types {
+		if t.Name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// Parse parses the .api file content src. Only the syntax the services use is
+// supported: the info, type, @server and service blocks, @doc and @handler.
+func Parse(src []byte) (*Spec, error) {
+	p := &parser{
+		scanner: bufio.NewScanner(bytes.NewReader(src)),
+		spec:    &Spec{Info: map[string]string{}},
+	}
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.line, err)
+	}
+	if err := p.spec.check(); err != nil {
+		return nil, err
+	}
+
+	return p.spec, nil
+}
+
+type parser struct {
+	scanner *bufio.Scanner
+	line    int
+	spec    *Spec
+	// the comments before a declaration are its doc
+	comments []string
+}
+
+// next returns the next trimmed line, false at the end.
+func (p *parser) next() (string, bool) {
+	if !p.scanner.Scan() {
+		return "", false
+	}
+	p.line++
+
+	return strings.TrimSpace(p.scanner.Text()), true
+}
+
+func (p *parser) parse() error {
+	var server map[string]string
+	for {
+		line, ok := p.next()
+		if !ok {
+			return p.scanner.Err()
+		}
+
+		switch {
+		case line == "":
+			p.comments = nil
+		case strings.HasPrefix(line, "//"):
+			p.comments = append(p.comments, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+		case strings.HasPrefix(line, "syntax"):
+		case strings.HasPrefix(line, "import"):
+			return fmt.Errorf("imports are not supported")
+		case line == "info(" || line == "info (":
+			props, err := p.props()
+			if err != nil {
+				return err
+			}
+			p.spec.Info = props
+		case line == "type (":
+			if err := p.typeGroup(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "type "):
+			m := typeLine.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("bad type declaration %q", line)
+			}
+			if err := p.typeBody(m[1], m[2] != ""); err != nil {
+				return err
+			}
+		case line == "@server(" || line == "@server (":
+			props, err := p.props()
+			if err != nil {
+				return err
+			}
+			server = props
+		case strings.HasPrefix(line, "service "):
+			m := serviceLine.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("bad service declaration %q", line)
+			}
+			if err := p.service(m[1], server); err != nil {
+				return err
+			}
+			server = nil
+		default:
+			return fmt.Errorf("unexpected %q", line)
+		}
+	}
+}
+
+// props parses the key: value lines of a block till the closing parenthesis.
+func (p *parser) props() (map[string]string, error) {
+	props := map[string]string{}
+	for {
+		line, ok := p.next()
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("missing )")
+		case line == ")":
+			return props, nil
+		case line == "" || strings.HasPrefix(line, "//"):
+		default:
+			m := propLine.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("bad property %q", line)
+			}
+			props[m[1]] = unquote(m[2])
+		}
+	}
+}
+
+func (p *parser) typeGroup() error {
+	for {
+		line, ok := p.next()
+		switch {
+		case !ok:
+			return fmt.Errorf("missing )")
+		case line == ")":
+			return nil
+		case line == "":
+			p.comments = nil
+		case strings.HasPrefix(line, "//"):
+			p.comments = append(p.comments, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+		default:
+			m := groupType.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("bad type declaration %q", line)
+			}
+			if err := p.typeBody(m[1], m[2] != ""); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *parser) typeBody(name string, closed bool) error {
+	if p.spec.Type(name) != nil {
+		return fmt.Errorf("duplicate type %s", name)
+	}
+
+	t := &Type{Name: name, Doc: strings.Join(p.comments, " ")}
+	p.comments = nil
+	p.spec.Types = append(p.spec.Types, t)
+	if closed {
+		return nil
+	}
+
+	var docs []string
+	for {
+		line, ok := p.next()
+		switch {
+		case !ok:
+			return fmt.Errorf("missing } of type %s", name)
+		case line == "}":
+			return nil
+		case line == "":
+			docs = nil
+		case strings.HasPrefix(line, "//"):
+			docs = append(docs, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+		default:
+			m := fieldLine.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("bad field %q", line)
+			}
+
+			field := &Field{Name: m[1], Type: m[2], Doc: strings.Join(docs, " ")}
+			docs = nil
+			// an embedded type has only the type name
+			if field.Type == "" {
+				field.Name, field.Type = "", m[1]
+			}
+			if m[3] != "" {
+				field.Tag = reflect.StructTag(strings.Trim(m[3], "`"))
+			}
+			if comment := m[4]; strings.HasPrefix(comment, "example:") {
+				field.Example = strings.TrimSpace(strings.TrimPrefix(comment, "example:"))
+			}
+			t.Fields = append(t.Fields, field)
+		}
+	}
+}
+
+func (p *parser) service(name string, server map[string]string) error {
+	g := &Group{Service: name, Server: server}
+	p.spec.Groups = append(p.spec.Groups, g)
+
+	var doc, handler string
+	for {
+		line, ok := p.next()
+		switch {
+		case !ok:
+			return fmt.Errorf("missing } of service %s", name)
+		case line == "}":
+			return nil
+		case line == "" || strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "@doc("), strings.HasPrefix(line, "@doc ("):
+			props, err := p.props()
+			if err != nil {
+				return err
+			}
+			doc = props["summary"]
+		case strings.HasPrefix(line, "@doc"):
+			doc = unquote(strings.TrimSpace(strings.TrimPrefix(line, "@doc")))
+		case strings.HasPrefix(line, "@handler"):
+			handler = strings.TrimSpace(strings.TrimPrefix(line, "@handler"))
+		default:
+			m := routeLine.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("bad route %q", line)
+			}
+			if handler == "" {
+				return fmt.Errorf("missing @handler of %s %s", m[1], m[2])
+			}
+
+			g.Routes = append(g.Routes, &Route{
+				Method:   m[1],
+				Path:     m[2],
+				Handler:  handler,
+				Request:  m[3],
+				Response: m[4],
+				Doc:      doc,
+			})
+			doc, handler = "", ""
+		}
+	}
+}
+
+// check makes sure that the routes refer to the declared types.
+func (s *Spec) check() error {
+	for _, g := range s.Groups {
+		for _, r := range g.Routes {
+			for _, name := range []string{r.Request, r.Response} {
+				if name != "" && s.Type(strings.TrimLeft(name, "[]*")) == nil {
+					return fmt.Errorf("%s %s: undefined type %s", r.Method, r.Path, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func unquote(s string) string {
+	if v, err := strconv.Unquote(s); err == nil {
+		return v
+	}
+
+	return s
+}
@@ -0,0 +1,168 @@
+package apidoc
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const testApi = `syntax = "v1"
+
+info(
+	title: "test"
+	desc: "the test api"
+	version: "2.0.0"
+)
+
+// Base is embedded
+type Base {
+	Id int64 ` + "`json:\"id\"`" + ` // example: 7
+}
+
+type (
+	Item {
+		Base
+		Name string   ` + "`json:\"name\"`" + ` // example: first
+		Tags []string ` + "`json:\"tags,optional\"`" + `
+		Note *string  ` + "`json:\"note,omitempty\"`" + `
+	}
+
+	ItemRequest {
+		Id int64 ` + "`path:\"id\"`" + `
+	}
+)
+
+type ListRequest {
+	// the status of the items
+	Status   string ` + "`form:\"status,optional,options=on|off\"`" + `
+	Page     int64  ` + "`form:\"page,default=1,range=[1:]\"`" + `
+	Ratio    float64 ` + "`form:\"ratio,range=(0:1]\"`" + `
+	Agent    string ` + "`header:\"User-Agent,optional\"`" + `
+}
+
+type ListResponse {
+	Items []Item ` + "`json:\"items\"`" + `
+}
+
+service test-api {
+	@doc "create an item"
+	@handler CreateItem
+	post /items (Item) returns(Item)
+	
+	@handler ListItems
+	get /items (ListRequest) returns (ListResponse)
+}
+
+@server(
+	jwt: Auth
+	middleware: Authorize, Audit
+)
+service test-api {
+	@doc(
+		summary: "delete an item"
+	)
+	@handler DeleteItem
+	delete /items/:id(ItemRequest)
+}
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(testApi))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Info["title"] != "test" || spec.Info["version"] != "2.0.0" {
+		t.Fatalf("unexpected info: %v", spec.Info)
+	}
+	if len(spec.Types) != 5 {
+		t.Fatalf("expect 5 types, got %d", len(spec.Types))
+	}
+
+	base := spec.Type("Base")
+	if base.Doc != "Base is embedded" || base.Fields[0].Example != "7" {
+		t.Fatalf("unexpected type: %+v %+v", base, base.Fields[0])
+	}
+	item := spec.Type("Item")
+	if len(item.Fields) != 4 || item.Fields[0].Name != "" || item.Fields[0].Type != "Base" {
+		t.Fatalf("expect the embedded Base, got %+v", item.Fields[0])
+	}
+	if item.Fields[1].Tag.Get("json") != "name" || item.Fields[3].Type != "*string" {
+		t.Fatalf("unexpected fields: %+v %+v", item.Fields[1], item.Fields[3])
+	}
+	if doc := spec.Type("ListRequest").Fields[0].Doc; doc != "the status of the items" {
+		t.Fatalf("unexpected field doc: %q", doc)
+	}
+
+	if len(spec.Groups) != 2 {
+		t.Fatalf("expect 2 groups, got %d", len(spec.Groups))
+	}
+	create := spec.Groups[0].Routes[0]
+	if create.Method != "post" || create.Path != "/items" || create.Handler != "CreateItem" ||
+		create.Request != "Item" || create.Response != "Item" || create.Doc != "create an item" {
+		t.Fatalf("unexpected route: %+v", create)
+	}
+	if list := spec.Groups[0].Routes[1]; list.Response != "ListResponse" || list.Doc != "" {
+		t.Fatalf("unexpected route: %+v", list)
+	}
+
+	secured := spec.Groups[1]
+	if secured.Server["jwt"] != "Auth" || secured.Server["middleware"] != "Authorize, Audit" {
+		t.Fatalf("unexpected server: %v", secured.Server)
+	}
+	del := secured.Routes[0]
+	if del.Path != "/items/:id" || del.Request != "ItemRequest" || del.Response != "" || del.Doc != "delete an item" {
+		t.Fatalf("unexpected route: %+v", del)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"undefined type":    "service a {\n@handler A\nget /a (Missing)\n}\n",
+		"missing handler":   "service a {\nget /a\n}\n",
+		"bad route":         "service a {\n@handler A\nfetch /a\n}\n",
+		"duplicate type":    "type A {\n}\ntype A {\n}\n",
+		"unclosed type":     "type A {\nName string\n",
+		"import":            "import \"other.api\"\n",
+		"unexpected":        "something\n",
+		"unclosed info":     "info(\ntitle: a\n",
+		"bad type":          "type A = B\n",
+		"bad field":         "type A {\nName string `json:\"name\"` junk\n}\n",
+		"unclosed service":  "service a {\n",
+		"bad service":       "service {\n",
+		"bad property":      "info(\ntitle\n)\n",
+		"unclosed group":    "type (\nA {\n}\n",
+		"bad type in group": "type (\nA = B\n)\n",
+	}
+	for name, src := range tests {
+		if _, err := Parse([]byte(src)); err == nil {
+			t.Errorf("%s: expect an error", name)
+		}
+	}
+}
+
+// the api files of the services must stay documentable
+func TestParseServices(t *testing.T) {
+	for _, file := range []string{"../../service/user.api", "../../algorithm/algorithm.api", "../../camera/camera.api"} {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec, err := Parse(src)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		doc, err := Generate(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		operations := 0
+		for _, item := range doc.Paths {
+			operations += len(*item)
+		}
+		if handlers := strings.Count(string(src), "@handler"); operations != handlers {
+			t.Fatalf("%s: expect %d operations, got %d", file, handlers, operations)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package apidoc
+
+import "embed"
+
+// uiFiles are the Swagger UI assets, see ui/README.md.
+//
+//go:generate go run ./internal/fetchui -version 5.17.14 -dir ui
+//go:embed ui
+var uiFiles embed.FS
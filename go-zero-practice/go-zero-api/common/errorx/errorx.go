@@ -0,0 +1,72 @@
+package errorx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+// internalErrorMessage is the message of the unexpected errors, their causes are only logged.
+const internalErrorMessage = "internal server error"
+
+type (
+	// CodeError is an error that carries the http status code of the response.
+	CodeError struct {
+		Code int
+		Msg  string
+	}
+
+	// CodeErrorResponse is the body of a CodeError response.
+	CodeErrorResponse struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+)
+
+// New returns a CodeError with code and msg.
+func New(code int, msg string) error {
+	return &CodeError{Code: code, Msg: msg}
+}
+
+// NewBadRequest returns a 400 CodeError.
+func NewBadRequest(msg string) error {
+	return New(http.StatusBadRequest, msg)
+}
+
+// NewNotFound returns a 404 CodeError.
+func NewNotFound(msg string) error {
+	return New(http.StatusNotFound, msg)
+}
+
+// NewConflict returns a 409 CodeError.
+func NewConflict(msg string) error {
+	return New(http.StatusConflict, msg)
+}
+
+func (e *CodeError) Error() string {
+	return e.Msg
+}
+
+// Data returns the body of the response.
+func (e *CodeError) Data() *CodeErrorResponse {
+	return &CodeErrorResponse{
+		Status:  strconv.Itoa(e.Code),
+		Message: e.Msg,
+	}
+}
+
+// Handler is the error handler of httpx, it answers CodeErrors with their code.
+// The other errors are unexpected, like a failed database query, they are logged
+// and answered with a 500 that doesn't leak the cause to the client.
+func Handler(err error) (int, interface{}) {
+	if e, ok := err.(*CodeError); ok {
+		return e.Code, e.Data()
+	}
+
+	logx.Errorf("internal error: %v", err)
+	return http.StatusInternalServerError, &CodeErrorResponse{
+		Status:  strconv.Itoa(http.StatusInternalServerError),
+		Message: internalErrorMessage,
+	}
+}
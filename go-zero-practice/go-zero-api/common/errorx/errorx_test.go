@@ -0,0 +1,28 @@
+package errorx
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+func init() {
+	logx.Disable()
+}
+
+func TestHandler(t *testing.T) {
+	code, body := Handler(NewConflict("user exists"))
+	if resp, ok := body.(*CodeErrorResponse); code != http.StatusConflict || !ok ||
+		resp.Status != "409" || resp.Message != "user exists" {
+		t.Fatalf("unexpected response of a CodeError: %d %+v", code, body)
+	}
+
+	// the causes of the unexpected errors are not sent to the clients
+	code, body = Handler(errors.New("dial tcp 10.0.0.5:3306: connection refused"))
+	if resp, ok := body.(*CodeErrorResponse); code != http.StatusInternalServerError || !ok ||
+		resp.Status != "500" || resp.Message != internalErrorMessage {
+		t.Fatalf("unexpected response of an internal error: %d %+v", code, body)
+	}
+}
@@ -0,0 +1,81 @@
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type (
+	// Migration changes the schema from the previous version to Version.
+	Migration struct {
+		Version int
+		Name    string
+		Up      func(tx *gorm.DB) error
+	}
+
+	// SchemaMigration records an applied Migration.
+	SchemaMigration struct {
+		Version   int `gorm:"primaryKey;autoIncrement:false"`
+		Name      string
+		AppliedAt time.Time
+	}
+)
+
+// Migrate applies the pending migrations to db in order and records them in table,
+// the services that share a database keep their histories in different tables.
+func Migrate(db *gorm.DB, table string, migrations []Migration) error {
+	if err := db.Table(table).AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	version, err := Version(db, table)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Table(table).Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Version returns the version of the latest migration applied to db, 0 if none.
+func Version(db *gorm.DB, table string) (int, error) {
+	var version int
+	err := db.Table(table).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// CreateTables creates the tables of models that do not exist yet.
+func CreateTables(tx *gorm.DB, models ...interface{}) error {
+	migrator := tx.Migrator()
+	for _, model := range models {
+		if migrator.HasTable(model) {
+			continue
+		}
+		if err := migrator.CreateTable(model); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-zero-api/common/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type note struct {
+	Id   int64
+	Text string
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("sqlite://file:%s?mode=memory&cache=shared", t.Name())
+	db, err := database.Open(dsn, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	return db
+}
+
+func assertVersion(t *testing.T, db *gorm.DB, table string, expect int) {
+	t.Helper()
+
+	version, err := Version(db, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != expect {
+		t.Fatalf("expect version %d of %s, got %d", expect, table, version)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db := newTestDB(t)
+
+	var applied []int
+	migrations := []Migration{
+		{Version: 1, Name: "create notes", Up: func(tx *gorm.DB) error {
+			applied = append(applied, 1)
+			return CreateTables(tx, &note{})
+		}},
+		{Version: 2, Name: "seed notes", Up: func(tx *gorm.DB) error {
+			applied = append(applied, 2)
+			return tx.Create(&note{Text: "hello"}).Error
+		}},
+	}
+
+	if err := Migrate(db, "schema_migrations", migrations[:1]); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, db, "schema_migrations", 1)
+
+	if err := Migrate(db, "schema_migrations", migrations); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db, "schema_migrations", migrations); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, db, "schema_migrations", 2)
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Fatalf("expect every migration applied once in order, got %v", applied)
+	}
+
+	// another history in the same database starts from scratch
+	if err := Migrate(db, "other_migrations", nil); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(t, db, "other_migrations", 0)
+}
+
+func TestMigrateFailure(t *testing.T) {
+	db := newTestDB(t)
+
+	errBroken := errors.New("broken")
+	migrations := []Migration{
+		{Version: 1, Name: "create notes", Up: func(tx *gorm.DB) error {
+			return CreateTables(tx, &note{})
+		}},
+		{Version: 2, Name: "broken", Up: func(tx *gorm.DB) error {
+			if err := tx.Create(&note{Text: "half"}).Error; err != nil {
+				return err
+			}
+			return errBroken
+		}},
+	}
+
+	if err := Migrate(db, "schema_migrations", migrations); !errors.Is(err, errBroken) {
+		t.Fatalf("expect errBroken, got %v", err)
+	}
+	assertVersion(t, db, "schema_migrations", 1)
+
+	// the failed migration is rolled back
+	var count int64
+	if err := db.Model(&note{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expect no notes, got %d", count)
+	}
+}
@@ -3,9 +3,14 @@ module go-zero-api
 go 1.16
 
 require (
+	github.com/golang-jwt/jwt v3.2.1+incompatible
 	github.com/jinzhu/gorm v1.9.16
-	github.com/jinzhu/now v1.1.3 // indirect
 	github.com/tal-tech/go-zero v1.2.2
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	gorm.io/driver/mysql v1.3.4
+	gorm.io/driver/postgres v1.3.5
 	gorm.io/driver/sqlite v1.2.4
-	gorm.io/gorm v1.22.3
+	gorm.io/gorm v1.23.4
 )
@@ -4,4 +4,46 @@ import "github.com/tal-tech/go-zero/rest"
 
 type Config struct {
 	rest.RestConf
+	Database struct {
+		// DataSource is the dsn of the database, its scheme chooses the driver:
+		// sqlite://, mysql:// or postgres://
+		DataSource string `json:",default=sqlite:///data/go-zero.db"`
+	}
+	// Auth takes the secrets from the environment, ${ACCESS_SECRET} and ${REFRESH_SECRET}
+	// in user-api.yaml, the other services verify the access tokens with the same ACCESS_SECRET
+	Auth struct {
+		AccessSecret string
+		AccessExpire int64
+		// the refresh tokens are signed with another secret,
+		// so they are never accepted as access tokens
+		RefreshSecret string
+		RefreshExpire int64
+	}
+	// TrustedProxies are the CIDRs of the reverse proxies in front of the api, like 10.0.0.0/8.
+	// The login audit log takes the client address from the last hop of X-Forwarded-For
+	// only when the peer is one of them, the clients can forge the header otherwise
+	TrustedProxies []string `json:",optional"`
+	// Lockout locks the users out for Duration seconds after MaxFailures failed logins in a row
+	Lockout struct {
+		MaxFailures int   `json:",default=5"`
+		Duration    int64 `json:",default=900"`
+	}
+	// Sender delivers the phone codes and the password reset tokens. Gateway is the url of the sms
+	// gateway and Token its bearer token, ${SMS_GATEWAY} and ${SMS_TOKEN} in user-api.yaml.
+	// Log writes the messages to the log instead, only for local runs, the log would leak the tokens
+	Sender struct {
+		Gateway string `json:",optional"`
+		Token   string `json:",optional"`
+		// Timeout is in seconds
+		Timeout int64 `json:",default=5"`
+		Log     bool  `json:",optional"`
+	}
+	// Verification configures the phone verification codes and the password reset tokens,
+	// the durations are in seconds
+	Verification struct {
+		CodeExpire  int64 `json:",default=300"`
+		MaxAttempts int   `json:",default=5"`
+		ResendAfter int64 `json:",default=60"`
+		ResetExpire int64 `json:",default=1800"`
+	}
 }
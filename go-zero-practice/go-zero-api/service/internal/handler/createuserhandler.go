@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"go-zero-api/common/errorx"
 	"go-zero-api/service/internal/logic"
 	"go-zero-api/service/internal/svc"
 	"go-zero-api/service/internal/types"
@@ -14,7 +15,7 @@ func CreateUserHandler(ctx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.RegisterRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.Error(w, err)
+			httpx.Error(w, errorx.NewBadRequest(err.Error()))
 			return
 		}
 
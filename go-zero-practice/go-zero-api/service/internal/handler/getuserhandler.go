@@ -3,16 +3,24 @@ package handler
 import (
 	"net/http"
 
-	"github.com/tal-tech/go-zero/rest/httpx"
+	"go-zero-api/common/errorx"
 	"go-zero-api/service/internal/logic"
 	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
 )
 
 func GetUserHandler(ctx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetUserRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, errorx.NewBadRequest(err.Error()))
+			return
+		}
 
 		l := logic.NewGetUserLogic(r.Context(), ctx)
-		resp, err := l.GetUser()
+		resp, err := l.GetUser(req)
 		if err != nil {
 			httpx.Error(w, err)
 		} else {
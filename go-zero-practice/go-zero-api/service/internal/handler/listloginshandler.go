@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-zero-api/common/errorx"
+	"go-zero-api/service/internal/logic"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func ListLoginsHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ListLoginsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, errorx.NewBadRequest(err.Error()))
+			return
+		}
+
+		l := logic.NewListLoginsLogic(r.Context(), ctx)
+		resp, err := l.ListLogins(req)
+		if err != nil {
+			httpx.Error(w, err)
+		} else {
+			httpx.OkJson(w, resp)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
+	"go-zero-api/common/errorx"
 	"go-zero-api/service/internal/logic"
 	"go-zero-api/service/internal/svc"
 	"go-zero-api/service/internal/types"
@@ -14,12 +16,13 @@ func LoginHandler(ctx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.LoginRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.Error(w, err)
+			httpx.Error(w, errorx.NewBadRequest(err.Error()))
 			return
 		}
 
 		l := logic.NewLoginLogic(r.Context(), ctx)
-		resp, err := l.Login(req)
+		// the client address is not part of the request body, the audit log needs it
+		resp, err := l.Login(req, r.RemoteAddr, strings.Join(r.Header.Values("X-Forwarded-For"), ","))
 		if err != nil {
 			httpx.Error(w, err)
 		} else {
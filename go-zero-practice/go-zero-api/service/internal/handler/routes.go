@@ -23,10 +23,78 @@ func RegisterHandlers(engine *rest.Server, serverCtx *svc.ServiceContext) {
 				Handler: CreateUserHandler(serverCtx),
 			},
 			{
-				Method:  http.MethodGet,
-				Path:    "/users/:userId",
-				Handler: GetUserHandler(serverCtx),
+				Method:  http.MethodPost,
+				Path:    "/user/refresh",
+				Handler: RefreshHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/user/password/forgot",
+				Handler: ForgotPasswordHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/user/password/reset",
+				Handler: ResetPasswordHandler(serverCtx),
 			},
 		},
 	)
+
+	engine.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.Session},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/users/:userId",
+					Handler: GetUserHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/user/phone/code",
+					Handler: SendPhoneCodeHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/user/phone/verify",
+					Handler: VerifyPhoneHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+	)
+
+	engine.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.Session, serverCtx.Authorize},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/admin/users",
+					Handler: ListUsersHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/admin/users/:userId/role",
+					Handler: ChangeRoleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/admin/users/:userId/deactivate",
+					Handler: DeactivateHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/admin/users/:userId/logins",
+					Handler: ListLoginsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/admin/users/:userId/unlock",
+					Handler: UnlockHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+	)
 }
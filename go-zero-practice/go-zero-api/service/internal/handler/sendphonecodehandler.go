@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-zero-api/service/internal/logic"
+	"go-zero-api/service/internal/svc"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func SendPhoneCodeHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewSendPhoneCodeLogic(r.Context(), ctx)
+		resp, err := l.SendPhoneCode()
+		if err != nil {
+			httpx.Error(w, err)
+		} else {
+			httpx.OkJson(w, resp)
+		}
+	}
+}
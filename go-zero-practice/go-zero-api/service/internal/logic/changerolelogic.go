@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ChangeRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewChangeRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) ChangeRoleLogic {
+	return ChangeRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ChangeRoleLogic) ChangeRole(req types.ChangeRoleRequest) (*types.UserInfo, error) {
+	// admins can not lock themselves out
+	if req.UserId == userFromContext(l.ctx) {
+		return nil, errorx.NewBadRequest("can not change your own role")
+	}
+
+	user, err := userInfo(l.svcCtx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch err := l.svcCtx.Rbac.SetRole(req.UserId, req.Role); err {
+	case nil:
+	case rbac.ErrUnknownRole:
+		return nil, errorx.NewBadRequest(err.Error())
+	default:
+		return nil, err
+	}
+
+	l.Infof("role of %s changed to %s by %s", req.UserId, req.Role, userFromContext(l.ctx))
+	user.Role = req.Role
+	return user, nil
+}
+
+// GrantAdmin makes a registered user an admin, the first admin is seeded with it
+// from the command line. Unregistered usernames are refused, so that nobody can
+// register a name that was made an admin in advance.
+func GrantAdmin(svcCtx *svc.ServiceContext, username string) error {
+	switch _, err := svcCtx.UserModel.FindOneByUsername(username); err {
+	case nil:
+	case m.ErrUserNotFound:
+		return fmt.Errorf("user %s is not registered", username)
+	default:
+		return err
+	}
+
+	return svcCtx.Rbac.SetRole(username, rbac.RoleAdmin)
+}
@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"testing"
+
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
+)
+
+func TestGrantAdmin(t *testing.T) {
+	ctx := newTestServiceContext(t)
+
+	// nobody can register a name that is granted in advance
+	if err := GrantAdmin(ctx, "alice"); err == nil {
+		t.Fatal("expect an unregistered user refused")
+	}
+	if account, err := ctx.Rbac.Account("alice"); err != nil || account.Role != rbac.RoleUser {
+		t.Fatalf("expect no role granted, got %+v, %v", account, err)
+	}
+
+	if err := ctx.UserModel.Insert(&m.User{Username: "alice", PasswordHash: "hash", Phonenumber: "13800000000"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := GrantAdmin(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if account, err := ctx.Rbac.Account("alice"); err != nil || account.Role != rbac.RoleAdmin {
+		t.Fatalf("expect alice an admin, got %+v, %v", account, err)
+	}
+}
@@ -3,6 +3,7 @@ package logic
 import (
 	"context"
 
+	"go-zero-api/common/errorx"
 	m "go-zero-api/service/internal/model"
 	"go-zero-api/service/internal/svc"
 	"go-zero-api/service/internal/types"
@@ -25,18 +26,38 @@ func NewCreateUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) CreateU
 }
 
 func (l *CreateUserLogic) CreateUser(req types.RegisterRequest) (*types.RegisterResponse, error) {
-	// todo: add your logic here and delete this line
-
-	
+	if err := validateUsername(req.Username); err != nil {
+		return nil, err
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return nil, err
+	}
+	if err := validatePhone(req.Phonenumber); err != nil {
+		return nil, err
+	}
 
-	userName := req.Username
-	passWord := req.Password
+	hashed, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
 
-	m.CreateUser(userName, passWord)
+	user := &m.User{
+		Username:     req.Username,
+		PasswordHash: hashed,
+		Phonenumber:  req.Phonenumber,
+	}
+	switch err := l.svcCtx.UserModel.Insert(user); err {
+	case nil:
+	case m.ErrUserExists:
+		return nil, errorx.NewConflict(err.Error())
+	default:
+		l.Errorf("create user %s failed: %v", req.Username, err)
+		return nil, err
+	}
 
 	return &types.RegisterResponse{
 		Status:  "200",
 		Message: "create user successful",
-		Data:    userName,
+		Data:    req.Username,
 	}, nil
 }
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/common/errorx"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type DeactivateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeactivateLogic(ctx context.Context, svcCtx *svc.ServiceContext) DeactivateLogic {
+	return DeactivateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeactivateLogic) Deactivate(req types.DeactivateRequest) (*types.UserInfo, error) {
+	if req.UserId == userFromContext(l.ctx) {
+		return nil, errorx.NewBadRequest("can not deactivate yourself")
+	}
+
+	user, err := userInfo(l.svcCtx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.Rbac.SetDeactivated(req.UserId, true); err != nil {
+		return nil, err
+	}
+
+	l.Infof("%s deactivated by %s", req.UserId, userFromContext(l.ctx))
+	user.Deactivated = true
+	return user, nil
+}
@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ForgotPasswordLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewForgotPasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) ForgotPasswordLogic {
+	return ForgotPasswordLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ForgotPassword sends a reset token to the phone number of the user. The
+// response is the same whether the user exists or not, so it can not be
+// used to find the registered usernames.
+func (l *ForgotPasswordLogic) ForgotPassword(req types.ForgotPasswordRequest) (*types.ActionResponse, error) {
+	resp := &types.ActionResponse{
+		Status:  "200",
+		Message: "a reset token is sent to the phone number of the user if the user exists",
+	}
+
+	user, err := l.svcCtx.UserModel.FindOneByUsername(req.Username)
+	switch err {
+	case nil:
+	case m.ErrUserNotFound:
+		return resp, nil
+	default:
+		return nil, err
+	}
+
+	account, err := l.svcCtx.Rbac.Account(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if account.Deactivated {
+		l.Infof("password reset of deactivated %s ignored", req.Username)
+		return resp, nil
+	}
+
+	now := time.Now()
+	ok, err := canResend(l.svcCtx, req.Username, m.PurposeReset, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return resp, nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expire := l.svcCtx.Config.Verification.ResetExpire
+	if err := l.svcCtx.TokenModel.Issue(&m.OneTimeToken{
+		Username:   req.Username,
+		Purpose:    m.PurposeReset,
+		TokenHash:  m.HashToken(token),
+		Target:     user.Phonenumber,
+		ExpireTime: now.Add(time.Duration(expire) * time.Second),
+	}); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Your password reset token is %s, it expires in %d minutes.", token, expire/60)
+	if err := l.svcCtx.Sender.Send(l.ctx, user.Phonenumber, message); err != nil {
+		l.Errorf("send reset token to %s failed: %v", req.Username, err)
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -2,7 +2,11 @@ package logic
 
 import (
 	"context"
+	"net/http"
 
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
 	"go-zero-api/service/internal/svc"
 	"go-zero-api/service/internal/types"
 
@@ -23,8 +27,48 @@ func NewGetUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) GetUserLog
 	}
 }
 
-func (l *GetUserLogic) GetUser() (*types.RegisterRequest, error) {
-	// todo: add your logic here and delete this line
+func (l *GetUserLogic) GetUser(req types.GetUserRequest) (*types.UserResponse, error) {
+	if userId := userFromContext(l.ctx); userId != req.UserId {
+		allowed, err := l.svcCtx.Rbac.Allowed(userId, rbac.PermReadUsers)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errorx.New(http.StatusForbidden, "not allowed to read other users")
+		}
+	}
+
+	user, err := l.svcCtx.UserModel.FindOneByUsername(req.UserId)
+	switch err {
+	case nil:
+	case m.ErrUserNotFound:
+		return nil, errorx.New(http.StatusNotFound, err.Error())
+	default:
+		return nil, err
+	}
 
-	return &types.RegisterRequest{}, nil
+	account, err := l.svcCtx.Rbac.Account(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	verified, err := l.svcCtx.UserModel.PhoneVerified(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserResponse(user, account, verified), nil
+}
+
+// toUserResponse maps the user to the response, the password hash is never exposed.
+func toUserResponse(user *m.User, account rbac.Account, phoneVerified bool) *types.UserResponse {
+	return &types.UserResponse{
+		Id:            user.Id,
+		Username:      user.Username,
+		Phonenumber:   user.Phonenumber,
+		PhoneVerified: phoneVerified,
+		Role:          account.Role,
+		Deactivated:   account.Deactivated,
+		CreateTime:    user.CreateTime.Unix(),
+		UpdateTime:    user.UpdateTime.Unix(),
+	}
 }
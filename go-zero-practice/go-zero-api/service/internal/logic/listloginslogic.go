@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ListLoginsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListLoginsLogic(ctx context.Context, svcCtx *svc.ServiceContext) ListLoginsLogic {
+	return ListLoginsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListLogins returns the latest login attempts of the username, the
+// attempts of the unknown usernames are kept too.
+func (l *ListLoginsLogic) ListLogins(req types.ListLoginsRequest) (*types.ListLoginsResponse, error) {
+	attempts, err := l.svcCtx.LoginModel.Attempts(req.UserId, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.ListLoginsResponse{
+		Logins: make([]types.LoginAttempt, len(attempts)),
+	}
+	for i, attempt := range attempts {
+		resp.Logins[i] = types.LoginAttempt{
+			Ip:         attempt.Ip,
+			UserAgent:  attempt.UserAgent,
+			Success:    attempt.Success,
+			Reason:     attempt.Reason,
+			CreateTime: attempt.CreateTime.Unix(),
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"context"
+	"net/http"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ListUsersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) ListUsersLogic {
+	return ListUsersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListUsersLogic) ListUsers(req types.ListUsersRequest) (*types.ListUsersResponse, error) {
+	users, total, err := l.svcCtx.UserModel.List((req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make([]string, len(users))
+	for i, user := range users {
+		usernames[i] = user.Username
+	}
+	accounts, err := l.svcCtx.Rbac.Accounts(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.ListUsersResponse{
+		Total: total,
+		Users: make([]types.UserInfo, len(users)),
+	}
+	for i, user := range users {
+		resp.Users[i] = *toUserInfo(user, accounts[user.Username])
+	}
+
+	return resp, nil
+}
+
+// userInfo returns the user with the role and the state of the account.
+func userInfo(svcCtx *svc.ServiceContext, userId string) (*types.UserInfo, error) {
+	user, err := svcCtx.UserModel.FindOneByUsername(userId)
+	switch err {
+	case nil:
+	case m.ErrUserNotFound:
+		return nil, errorx.New(http.StatusNotFound, err.Error())
+	default:
+		return nil, err
+	}
+
+	account, err := svcCtx.Rbac.Account(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserInfo(user, account), nil
+}
+
+// toUserInfo maps the user to the admin view, the password hash is never exposed.
+func toUserInfo(user *m.User, account rbac.Account) *types.UserInfo {
+	return &types.UserInfo{
+		Username:    user.Username,
+		Phonenumber: user.Phonenumber,
+		Role:        account.Role,
+		Deactivated: account.Deactivated,
+	}
+}
@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-zero-api/service/internal/config"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+const testPassword = "Passw0rd!"
+
+func init() {
+	logx.Disable()
+}
+
+// newTestServiceContext returns a ServiceContext on its own in-memory database.
+func newTestServiceContext(t *testing.T) *svc.ServiceContext {
+	var c config.Config
+	c.Database.DataSource = fmt.Sprintf("sqlite://file:%s?mode=memory&cache=shared", t.Name())
+	c.Auth.AccessSecret = "access-secret"
+	c.Auth.AccessExpire = 3600
+	c.Auth.RefreshSecret = "refresh-secret"
+	c.Auth.RefreshExpire = 86400
+	c.Lockout.MaxFailures = 3
+	c.Lockout.Duration = 900
+	c.Verification.CodeExpire = 300
+	c.Verification.MaxAttempts = 3
+	c.Verification.ResendAfter = 60
+	c.Verification.ResetExpire = 1800
+	c.Sender.Log = true
+
+	ctx := svc.NewServiceContext(c)
+	ctx.Sender = &recordSender{last: make(map[string]string)}
+	sqlDB, err := ctx.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	return ctx
+}
+
+// newTestUser registers username with testPassword and the phone number.
+func newTestUser(t *testing.T, ctx *svc.ServiceContext, username, phoneNumber string) {
+	l := NewCreateUserLogic(context.Background(), ctx)
+	_, err := l.CreateUser(types.RegisterRequest{
+		Username:    username,
+		Password:    testPassword,
+		Phonenumber: phoneNumber,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sentToken returns the token or the code of the last message sent to phoneNumber,
+// the messages read "<prefix> <token>, it expires in ...".
+func sentToken(t *testing.T, ctx *svc.ServiceContext, phoneNumber, prefix string) string {
+	message, ok := ctx.Sender.(*recordSender).last[phoneNumber]
+	if !ok || !strings.HasPrefix(message, prefix+" ") {
+		t.Fatalf("expect a message of %q sent to %s, got %q", prefix, phoneNumber, message)
+	}
+
+	return strings.SplitN(strings.TrimPrefix(message, prefix+" "), ",", 2)[0]
+}
+
+// recordSender keeps the last message of every phone number instead of sending it.
+type recordSender struct {
+	last map[string]string
+}
+
+func (s *recordSender) Send(ctx context.Context, phoneNumber, message string) error {
+	s.last[phoneNumber] = message
+	return nil
+}
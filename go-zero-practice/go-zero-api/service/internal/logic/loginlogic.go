@@ -2,13 +2,31 @@ package logic
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
 
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
 	"go-zero-api/service/internal/svc"
 	"go-zero-api/service/internal/types"
 
 	"github.com/tal-tech/go-zero/core/logx"
 )
 
+const (
+	// the reasons of the failed login attempts in the audit log
+	reasonLocked       = "locked"
+	reasonInvalidLogin = "invalid login"
+	reasonDeactivated  = "deactivated"
+	reasonError        = "error"
+
+	maxIpLength        = 64
+	maxUserAgentLength = 255
+)
+
 type LoginLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -23,8 +41,110 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) LoginLogic {
 	}
 }
 
-func (l *LoginLogic) Login(req types.LoginRequest) (*types.LoginResponse, error) {
-	// todo: add your logic here and delete this line
+// Login returns the tokens of the user, remoteAddr is the peer address of the request
+// and forwardedFor its X-Forwarded-For header, they are audited with the attempt.
+func (l *LoginLogic) Login(req types.LoginRequest, remoteAddr, forwardedFor string) (*types.LoginResponse, error) {
+	resp, reason, err := l.login(req, time.Now())
+
+	// every attempt is audited, the failures to audit do not fail the login
+	attempt := &m.LoginAttempt{
+		Username:  req.Username,
+		Ip:        truncate(clientIp(remoteAddr, forwardedFor, l.svcCtx.TrustedProxies), maxIpLength),
+		UserAgent: truncate(req.UserAgent, maxUserAgentLength),
+		Success:   err == nil,
+		Reason:    reason,
+	}
+	if err := l.svcCtx.LoginModel.Record(attempt); err != nil {
+		l.Errorf("record login attempt of %s failed: %v", req.Username, err)
+	}
+
+	return resp, err
+}
+
+// login returns the tokens of the user, or the reason of the failure.
+func (l *LoginLogic) login(req types.LoginRequest, now time.Time) (*types.LoginResponse, string, error) {
+	lockedUntil, err := l.svcCtx.LoginModel.LockedUntil(req.Username, now)
+	if err != nil {
+		return nil, reasonError, err
+	}
+	if !lockedUntil.IsZero() {
+		return nil, reasonLocked, errLocked(lockedUntil)
+	}
+
+	user, err := l.svcCtx.UserModel.FindOneByUsername(req.Username)
+	if err != nil && err != m.ErrUserNotFound {
+		return nil, reasonError, err
+	}
+	// the unknown users fail like the wrong passwords, so the usernames are not told
+	if user == nil || !user.CheckPassword(req.Password) {
+		lockout := l.svcCtx.Config.Lockout
+		lockedUntil, err := l.svcCtx.LoginModel.Fail(req.Username, now, lockout.MaxFailures,
+			time.Duration(lockout.Duration)*time.Second)
+		if err != nil {
+			return nil, reasonInvalidLogin, err
+		}
+		if !lockedUntil.IsZero() {
+			l.Infof("%s locked out until %s", req.Username, lockedUntil.Format(time.RFC3339))
+			return nil, reasonInvalidLogin, errLocked(lockedUntil)
+		}
+		return nil, reasonInvalidLogin, errorx.New(http.StatusUnauthorized, m.ErrInvalidLogin.Error())
+	}
+
+	// the deactivation is only told to the users who know the password
+	account, err := l.svcCtx.Rbac.Account(req.Username)
+	if err != nil {
+		return nil, reasonError, err
+	}
+	if account.Deactivated {
+		return nil, reasonDeactivated, errorx.New(http.StatusForbidden, "the account is deactivated")
+	}
+
+	if err := l.svcCtx.LoginModel.Unlock(req.Username); err != nil {
+		return nil, reasonError, err
+	}
+
+	resp, err := issueTokens(l.svcCtx, user)
+	if err != nil {
+		return nil, reasonError, err
+	}
+
+	return resp, "", nil
+}
+
+func errLocked(until time.Time) error {
+	return errorx.New(http.StatusLocked,
+		fmt.Sprintf("too many failed logins, try again after %s", until.Format(time.RFC3339)))
+}
+
+// clientIp returns the address of the client. It is the peer address, or the last hop of
+// forwardedFor when the peer is a trusted proxy: the proxy appends the address it got the
+// request from, the former hops come from the client and can be forged.
+func clientIp(remoteAddr, forwardedFor string, proxies []*net.IPNet) string {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	last := strings.TrimSpace(hops[len(hops)-1])
+	if len(last) == 0 {
+		return host
+	}
+
+	ip := net.ParseIP(host)
+	for _, proxy := range proxies {
+		if ip != nil && proxy.Contains(ip) {
+			return last
+		}
+	}
+
+	return host
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
 
-	return &types.LoginResponse{}, nil
+	return s[:n]
 }
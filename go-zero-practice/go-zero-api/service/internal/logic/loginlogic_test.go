@@ -0,0 +1,132 @@
+package logic
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-zero-api/common/errorx"
+	"go-zero-api/service/internal/types"
+)
+
+func expectCode(t *testing.T, err error, code int) {
+	t.Helper()
+	e, ok := err.(*errorx.CodeError)
+	if !ok || e.Code != code {
+		t.Fatalf("expect a %d error, got %v", code, err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	l := NewLoginLogic(context.Background(), ctx)
+
+	resp, err := l.Login(types.LoginRequest{Username: "alice", Password: testPassword, UserAgent: "test"},
+		"192.0.2.1:1234", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.AccessToken) == 0 || len(resp.RefreshToken) == 0 {
+		t.Fatalf("expect the tokens, got %+v", resp)
+	}
+
+	// the unknown users fail like the wrong passwords
+	_, err = l.Login(types.LoginRequest{Username: "alice", Password: "wrong"}, "192.0.2.1:1234", "")
+	expectCode(t, err, http.StatusUnauthorized)
+	_, err = l.Login(types.LoginRequest{Username: "nobody", Password: testPassword}, "192.0.2.1:1234", "")
+	expectCode(t, err, http.StatusUnauthorized)
+
+	attempts, err := ctx.LoginModel.Attempts("alice", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[0].Success || !attempts[1].Success ||
+		attempts[1].Ip != "192.0.2.1" || attempts[1].UserAgent != "test" {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	l := NewLoginLogic(context.Background(), ctx)
+
+	for i := 1; i < ctx.Config.Lockout.MaxFailures; i++ {
+		_, err := l.Login(types.LoginRequest{Username: "alice", Password: "wrong"}, "192.0.2.1:1234", "")
+		expectCode(t, err, http.StatusUnauthorized)
+	}
+	_, err := l.Login(types.LoginRequest{Username: "alice", Password: "wrong"}, "192.0.2.1:1234", "")
+	expectCode(t, err, http.StatusLocked)
+
+	// the right password does not unlock the user
+	_, err = l.Login(types.LoginRequest{Username: "alice", Password: testPassword}, "192.0.2.1:1234", "")
+	expectCode(t, err, http.StatusLocked)
+}
+
+func TestLoginDeactivated(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	if err := ctx.Rbac.SetDeactivated("alice", true); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoginLogic(context.Background(), ctx)
+	_, err := l.Login(types.LoginRequest{Username: "alice", Password: testPassword}, "192.0.2.1:1234", "")
+	expectCode(t, err, http.StatusForbidden)
+}
+
+func TestClientIp(t *testing.T) {
+	_, proxy, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxies := []*net.IPNet{proxy}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expect       string
+	}{
+		{"direct", "192.0.2.1:1234", "", "192.0.2.1"},
+		{"forged by a client", "192.0.2.1:1234", "198.51.100.1", "192.0.2.1"},
+		{"trusted proxy", "10.0.0.1:1234", "198.51.100.1", "198.51.100.1"},
+		{"forged before the proxy", "10.0.0.1:1234", "203.0.113.1, 198.51.100.1", "198.51.100.1"},
+		{"trusted proxy without header", "10.0.0.1:1234", "", "10.0.0.1"},
+		{"no port", "192.0.2.1", "", "192.0.2.1"},
+		{"ipv6", "[2001:db8::1]:1234", "", "2001:db8::1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ip := clientIp(test.remoteAddr, test.forwardedFor, proxies); ip != test.expect {
+				t.Fatalf("expect %s, got %s", test.expect, ip)
+			}
+		})
+	}
+}
+
+func TestLoginTruncatesIp(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	_, proxy, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.TrustedProxies = []*net.IPNet{proxy}
+
+	l := NewLoginLogic(context.Background(), ctx)
+	forged := strings.Repeat("x", 100)
+	_, err = l.Login(types.LoginRequest{Username: "alice", Password: testPassword}, "10.0.0.1:1234", forged)
+	expectCode(t, err, http.StatusUnauthorized)
+
+	attempts, err := ctx.LoginModel.Attempts("alice", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].Ip != forged[:maxIpLength] {
+		t.Fatalf("expect the ip truncated to %d, got %+v", maxIpLength, attempts)
+	}
+}
@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+)
+
+const (
+	codeDigits = 6
+	tokenBytes = 24
+)
+
+// randomCode returns a random code of codeDigits digits for the text messages.
+func randomCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
+
+// randomToken returns a random url safe token.
+func randomToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// canResend tells whether a new token of purpose can be sent to the user,
+// the tokens are sent at most once in ResendAfter seconds.
+func canResend(svcCtx *svc.ServiceContext, username, purpose string, now time.Time) (bool, error) {
+	latest, err := svcCtx.TokenModel.Latest(username, purpose)
+	if err != nil || latest == nil {
+		return err == nil, err
+	}
+
+	resendAfter := time.Duration(svcCtx.Config.Verification.ResendAfter) * time.Second
+	return now.Sub(latest.CreateTime) >= resendAfter, nil
+}
+
+// tokenError maps the errors of the one-time tokens to the responses.
+func tokenError(err error) error {
+	switch err {
+	case m.ErrTokenInvalid:
+		return errorx.NewBadRequest(err.Error())
+	case m.ErrTooManyAttempts:
+		return errorx.New(http.StatusTooManyRequests, err.Error())
+	default:
+		return err
+	}
+}
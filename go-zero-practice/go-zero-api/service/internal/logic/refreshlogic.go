@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"net/http"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type RefreshLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRefreshLogic(ctx context.Context, svcCtx *svc.ServiceContext) RefreshLogic {
+	return RefreshLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RefreshLogic) Refresh(req types.RefreshRequest) (*types.LoginResponse, error) {
+	userId, version, err := parseRefreshToken(l.svcCtx.Config.Auth.RefreshSecret, req.RefreshToken)
+	if err != nil {
+		return nil, errorx.New(http.StatusUnauthorized, err.Error())
+	}
+
+	// the user may have been removed or reset the password since the token was issued
+	user, err := l.svcCtx.UserModel.FindOneByUsername(userId)
+	switch err {
+	case nil:
+	case m.ErrUserNotFound:
+		return nil, errorx.New(http.StatusUnauthorized, errInvalidToken.Error())
+	default:
+		return nil, err
+	}
+	if user.TokenVersion != version {
+		return nil, errorx.New(http.StatusUnauthorized, errInvalidToken.Error())
+	}
+
+	return issueTokens(l.svcCtx, user)
+}
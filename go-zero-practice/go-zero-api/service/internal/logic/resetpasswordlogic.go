@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ResetPasswordLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewResetPasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) ResetPasswordLogic {
+	return ResetPasswordLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResetPasswordLogic) ResetPassword(req types.ResetPasswordRequest) (*types.ActionResponse, error) {
+	// a weak password must not use up the token
+	if err := validatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	token, err := l.svcCtx.TokenModel.Take(m.PurposeReset, m.HashToken(strings.TrimSpace(req.Token)), time.Now())
+	if err != nil {
+		return nil, tokenError(err)
+	}
+
+	hashed, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	switch err := l.svcCtx.UserModel.SetPassword(token.Username, hashed); err {
+	case nil:
+	case m.ErrUserNotFound:
+		return nil, errorx.NewBadRequest(m.ErrTokenInvalid.Error())
+	default:
+		return nil, err
+	}
+
+	if err := l.svcCtx.LoginModel.Unlock(token.Username); err != nil {
+		return nil, err
+	}
+
+	l.Infof("password of %s reset", token.Username)
+	return &types.ActionResponse{
+		Status:  "200",
+		Message: "password reset",
+	}, nil
+}
@@ -0,0 +1,94 @@
+package logic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-zero-api/service/internal/types"
+)
+
+const resetPrefix = "Your password reset token is"
+
+func TestForgotPassword(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	l := NewForgotPasswordLogic(context.Background(), ctx)
+
+	// the unknown users get the same response
+	known, err := l.ForgotPassword(types.ForgotPasswordRequest{Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := l.ForgotPassword(types.ForgotPasswordRequest{Username: "nobody"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *known != *unknown {
+		t.Fatalf("expect the same responses, got %+v and %+v", known, unknown)
+	}
+	token := sentToken(t, ctx, "13800000000", resetPrefix)
+
+	// a second request within ResendAfter sends no new token
+	if _, err := l.ForgotPassword(types.ForgotPasswordRequest{Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if again := sentToken(t, ctx, "13800000000", resetPrefix); again != token {
+		t.Fatal("expect no new token within ResendAfter")
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	login := NewLoginLogic(context.Background(), ctx)
+	tokens, err := login.Login(types.LoginRequest{Username: "alice", Password: testPassword}, "192.0.2.1:1234", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forgot := NewForgotPasswordLogic(context.Background(), ctx)
+	if _, err := forgot.ForgotPassword(types.ForgotPasswordRequest{Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	token := sentToken(t, ctx, "13800000000", resetPrefix)
+
+	reset := NewResetPasswordLogic(context.Background(), ctx)
+	// a weak password does not use up the token
+	_, err = reset.ResetPassword(types.ResetPasswordRequest{Token: token, Password: "weak"})
+	expectBadRequest(t, "weak", err)
+	if _, err := reset.ResetPassword(types.ResetPasswordRequest{Token: token, Password: "N3w-Passw0rd"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = reset.ResetPassword(types.ResetPasswordRequest{Token: token, Password: "N3w-Passw0rd"})
+	expectBadRequest(t, token, err)
+
+	_, err = login.Login(types.LoginRequest{Username: "alice", Password: testPassword}, "192.0.2.1:1234", "")
+	expectCode(t, err, http.StatusUnauthorized)
+	if _, err := login.Login(types.LoginRequest{Username: "alice", Password: "N3w-Passw0rd"}, "192.0.2.1:1234", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tokens issued before the reset are revoked
+	refresh := NewRefreshLogic(context.Background(), ctx)
+	_, err = refresh.Refresh(types.RefreshRequest{RefreshToken: tokens.RefreshToken})
+	expectCode(t, err, http.StatusUnauthorized)
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	login := NewLoginLogic(context.Background(), ctx)
+	tokens, err := login.Login(types.LoginRequest{Username: "alice", Password: testPassword}, "192.0.2.1:1234", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := NewRefreshLogic(context.Background(), ctx)
+	if _, err := refresh.Refresh(types.RefreshRequest{RefreshToken: tokens.RefreshToken}); err != nil {
+		t.Fatal(err)
+	}
+	// the access tokens are signed with another secret
+	_, err = refresh.Refresh(types.RefreshRequest{RefreshToken: tokens.AccessToken})
+	expectCode(t, err, http.StatusUnauthorized)
+}
@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type SendPhoneCodeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSendPhoneCodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) SendPhoneCodeLogic {
+	return SendPhoneCodeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SendPhoneCodeLogic) SendPhoneCode() (*types.ActionResponse, error) {
+	userId := userFromContext(l.ctx)
+	user, err := userInfo(l.svcCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := l.svcCtx.UserModel.PhoneVerified(userId)
+	if err != nil {
+		return nil, err
+	}
+	if verified {
+		return nil, errorx.NewConflict("the phone number is verified already")
+	}
+
+	now := time.Now()
+	ok, err := canResend(l.svcCtx, userId, m.PurposePhone, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errorx.New(http.StatusTooManyRequests, "a code was sent recently, try again later")
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return nil, err
+	}
+
+	expire := l.svcCtx.Config.Verification.CodeExpire
+	if err := l.svcCtx.TokenModel.Issue(&m.OneTimeToken{
+		Username:   userId,
+		Purpose:    m.PurposePhone,
+		TokenHash:  m.HashToken(code),
+		Target:     user.Phonenumber,
+		ExpireTime: now.Add(time.Duration(expire) * time.Second),
+	}); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Your verification code is %s, it expires in %d minutes.", code, expire/60)
+	if err := l.svcCtx.Sender.Send(l.ctx, user.Phonenumber, message); err != nil {
+		l.Errorf("send verification code to %s failed: %v", userId, err)
+		return nil, err
+	}
+
+	return &types.ActionResponse{
+		Status:  "200",
+		Message: "verification code sent",
+	}, nil
+}
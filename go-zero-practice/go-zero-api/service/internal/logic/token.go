@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// the claims are put into the request context by the jwt middleware
+	claimUserId = "userId"
+	claimRole   = "role"
+	claimType   = "type"
+	// claimTokenVersion is the token version of the user, a password reset revokes the older tokens
+	claimTokenVersion = "ver"
+
+	tokenAccess  = "access"
+	tokenRefresh = "refresh"
+)
+
+var errInvalidToken = errors.New("invalid refresh token")
+
+// issueTokens returns a new pair of access and refresh tokens of the user,
+// the deactivated users get none.
+func issueTokens(svcCtx *svc.ServiceContext, user *m.User) (*types.LoginResponse, error) {
+	userId := user.Username
+	account, err := svcCtx.Rbac.Account(userId)
+	if err != nil {
+		return nil, err
+	}
+	if account.Deactivated {
+		return nil, errorx.New(http.StatusForbidden, "the account is deactivated")
+	}
+
+	auth := svcCtx.Config.Auth
+	now := time.Now().Unix()
+	accessToken, err := newToken(auth.AccessSecret, now, auth.AccessExpire, jwt.MapClaims{
+		claimUserId:       userId,
+		claimRole:         account.Role,
+		claimType:         tokenAccess,
+		claimTokenVersion: user.TokenVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newToken(auth.RefreshSecret, now, auth.RefreshExpire, jwt.MapClaims{
+		claimUserId:       userId,
+		claimType:         tokenRefresh,
+		claimTokenVersion: user.TokenVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.LoginResponse{
+		Status:       "200",
+		Message:      "login successful",
+		AccessToken:  accessToken,
+		AccessExpire: now + auth.AccessExpire,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func newToken(secret string, iat, seconds int64, claims jwt.MapClaims) (string, error) {
+	claims["iat"] = iat
+	claims["exp"] = iat + seconds
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// parseRefreshToken returns the user and the token version of a valid refresh token.
+func parseRefreshToken(secret, tokenString string) (string, int64, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errInvalidToken
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return "", 0, errInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims[claimType] != tokenRefresh {
+		return "", 0, errInvalidToken
+	}
+
+	userId, ok := claims[claimUserId].(string)
+	if !ok || len(userId) == 0 {
+		return "", 0, errInvalidToken
+	}
+
+	// the tokens issued before the token versions have none, they are of version 0
+	var version int64
+	if v, ok := claims[claimTokenVersion].(float64); ok {
+		version = int64(v)
+	}
+
+	return userId, version, nil
+}
+
+// userFromContext returns the user of the access token.
+func userFromContext(ctx context.Context) string {
+	userId, _ := ctx.Value(claimUserId).(string)
+	return userId
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type UnlockLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUnlockLogic(ctx context.Context, svcCtx *svc.ServiceContext) UnlockLogic {
+	return UnlockLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UnlockLogic) Unlock(req types.UnlockRequest) (*types.ActionResponse, error) {
+	if _, err := userInfo(l.svcCtx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.LoginModel.Unlock(req.UserId); err != nil {
+		return nil, err
+	}
+
+	l.Infof("%s unlocked by %s", req.UserId, userFromContext(l.ctx))
+	return &types.ActionResponse{
+		Status:  "200",
+		Message: "account unlocked",
+	}, nil
+}
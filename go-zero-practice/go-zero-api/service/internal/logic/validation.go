@@ -0,0 +1,93 @@
+package logic
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go-zero-api/common/errorx"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores the bytes after the 72nd
+	maxPasswordLength = 72
+)
+
+var (
+	// passwordCost is the bcrypt cost of the password hashes, lowered in tests.
+	passwordCost = bcrypt.DefaultCost
+
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{2,31}$`)
+	// reservedUsernames can not be registered, they look like the accounts of the operators
+	reservedUsernames = map[string]bool{
+		"admin": true, "administrator": true, "root": true, "system": true, "superuser": true,
+	}
+	// international numbers with the country code, or mainland china mobile numbers
+	phonePattern = regexp.MustCompile(`^(\+[1-9]\d{6,14}|1[3-9]\d{9})$`)
+)
+
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return errorx.NewBadRequest("username must be 3 to 32 letters, digits or underscores, starting with a letter")
+	}
+	if reservedUsernames[strings.ToLower(username)] {
+		return errorx.NewBadRequest("username is reserved")
+	}
+
+	return nil
+}
+
+// validatePassword requires at least 8 characters from at least 3 of
+// lower case letters, upper case letters, digits and symbols.
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return errorx.NewBadRequest("password must be 8 to 72 characters")
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	var classes int
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return errorx.NewBadRequest("password must mix at least 3 of lower case, upper case, digits and symbols")
+	}
+
+	return nil
+}
+
+func validatePhone(phone string) error {
+	if !phonePattern.MatchString(phone) {
+		return errorx.NewBadRequest("phone number must be +<country code><number> or an 11 digit mobile number")
+	}
+
+	return nil
+}
+
+// hashPassword returns the bcrypt hash of a validated password.
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
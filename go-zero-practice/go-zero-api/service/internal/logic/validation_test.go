@@ -0,0 +1,93 @@
+package logic
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-zero-api/common/errorx"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func init() {
+	// the default cost makes every hash take a noticeable time
+	passwordCost = bcrypt.MinCost
+}
+
+func expectBadRequest(t *testing.T, value string, err error) {
+	t.Helper()
+	if e, ok := err.(*errorx.CodeError); !ok || e.Code != http.StatusBadRequest {
+		t.Fatalf("%q: expect a bad request, got %v", value, err)
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	for _, username := range []string{"bob", "alice_01", "A" + strings.Repeat("b", 31)} {
+		if err := validateUsername(username); err != nil {
+			t.Fatalf("%q: %v", username, err)
+		}
+	}
+
+	for _, username := range []string{"", "ab", "1bob", "_bob", "bob smith", "bób", "A" + strings.Repeat("b", 32), "admin", "Root"} {
+		expectBadRequest(t, username, validateUsername(username))
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	for _, password := range []string{"Secret-1", "secret-123", "SECRET123a", "密码Secret1", strings.Repeat("Aa1", 24)} {
+		if err := validatePassword(password); err != nil {
+			t.Fatalf("%q: %v", password, err)
+		}
+	}
+
+	for _, password := range []string{
+		"",
+		"Sec-1",                         // too short
+		strings.Repeat("Aa1", 24) + "x", // longer than bcrypt hashes
+		"secretsecret",                  // one class
+		"secret123456",                  // two classes
+	} {
+		expectBadRequest(t, password, validatePassword(password))
+	}
+}
+
+func TestValidatePhone(t *testing.T) {
+	for _, phone := range []string{"13800000000", "+8613800000000", "+14155550100"} {
+		if err := validatePhone(phone); err != nil {
+			t.Fatalf("%q: %v", phone, err)
+		}
+	}
+
+	for _, phone := range []string{"", "12800000000", "1380000000", "+0123456789", "138-0000-0000", "+1234"} {
+		expectBadRequest(t, phone, validatePhone(phone))
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	hashed, err := hashPassword("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(hashed, "Secret-123") {
+		t.Fatal("expect the password not stored in the hash")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte("Secret-123")); err != nil {
+		t.Fatalf("expect the hash to match the password: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte("Secret-124")); err == nil {
+		t.Fatal("expect the hash to reject another password")
+	}
+	if cost, err := bcrypt.Cost([]byte(hashed)); err != nil || cost != passwordCost {
+		t.Fatalf("expect cost %d, got %d, %v", passwordCost, cost, err)
+	}
+
+	// the same password gets a new salt every time
+	again, err := hashPassword("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == hashed {
+		t.Fatal("expect a different salt for every hash")
+	}
+}
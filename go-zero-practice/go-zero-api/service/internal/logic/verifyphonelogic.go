@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-zero-api/common/errorx"
+	m "go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/svc"
+	"go-zero-api/service/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type VerifyPhoneLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewVerifyPhoneLogic(ctx context.Context, svcCtx *svc.ServiceContext) VerifyPhoneLogic {
+	return VerifyPhoneLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *VerifyPhoneLogic) VerifyPhone(req types.VerifyPhoneRequest) (*types.ActionResponse, error) {
+	userId := userFromContext(l.ctx)
+	token, err := l.svcCtx.TokenModel.Check(userId, m.PurposePhone, m.HashToken(strings.TrimSpace(req.Code)),
+		time.Now(), l.svcCtx.Config.Verification.MaxAttempts)
+	if err != nil {
+		return nil, tokenError(err)
+	}
+
+	user, err := userInfo(l.svcCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+	// the code proves the number it was sent to only
+	if token.Target != user.Phonenumber {
+		return nil, errorx.NewBadRequest("the phone number has changed, request a new code")
+	}
+
+	if err := l.svcCtx.UserModel.VerifyPhone(userId, token.Target); err != nil {
+		return nil, err
+	}
+
+	return &types.ActionResponse{
+		Status:  "200",
+		Message: "phone number verified",
+	}, nil
+}
@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-zero-api/service/internal/types"
+)
+
+const codePrefix = "Your verification code is"
+
+func TestVerifyPhone(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	userCtx := context.WithValue(context.Background(), claimUserId, "alice")
+	send := NewSendPhoneCodeLogic(userCtx, ctx)
+
+	if _, err := send.SendPhoneCode(); err != nil {
+		t.Fatal(err)
+	}
+	code := sentToken(t, ctx, "13800000000", codePrefix)
+	_, err := send.SendPhoneCode()
+	expectCode(t, err, http.StatusTooManyRequests)
+
+	l := NewVerifyPhoneLogic(userCtx, ctx)
+	_, err = l.VerifyPhone(types.VerifyPhoneRequest{Code: "000000x"})
+	expectBadRequest(t, "000000x", err)
+	if _, err := l.VerifyPhone(types.VerifyPhoneRequest{Code: " " + code + " "}); err != nil {
+		t.Fatal(err)
+	}
+	if verified, err := ctx.UserModel.PhoneVerified("alice"); err != nil || !verified {
+		t.Fatalf("expect the phone number verified, got %v, %v", verified, err)
+	}
+
+	// the code is used up
+	_, err = l.VerifyPhone(types.VerifyPhoneRequest{Code: code})
+	expectBadRequest(t, code, err)
+	_, err = send.SendPhoneCode()
+	expectCode(t, err, http.StatusConflict)
+}
+
+func TestVerifyPhoneTooManyAttempts(t *testing.T) {
+	ctx := newTestServiceContext(t)
+	newTestUser(t, ctx, "alice", "13800000000")
+	userCtx := context.WithValue(context.Background(), claimUserId, "alice")
+	send := NewSendPhoneCodeLogic(userCtx, ctx)
+
+	if _, err := send.SendPhoneCode(); err != nil {
+		t.Fatal(err)
+	}
+	code := sentToken(t, ctx, "13800000000", codePrefix)
+
+	l := NewVerifyPhoneLogic(userCtx, ctx)
+	for i := 1; i < ctx.Config.Verification.MaxAttempts; i++ {
+		_, err := l.VerifyPhone(types.VerifyPhoneRequest{Code: "000000x"})
+		expectBadRequest(t, "000000x", err)
+	}
+	_, err := l.VerifyPhone(types.VerifyPhoneRequest{Code: "000000x"})
+	expectCode(t, err, http.StatusTooManyRequests)
+
+	// the code is dropped after MaxAttempts wrong ones, the right code is refused too
+	_, err = l.VerifyPhone(types.VerifyPhoneRequest{Code: code})
+	expectBadRequest(t, code, err)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-zero-api/common/errorx"
+
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/core/search"
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+type (
+	// Checker tells whether a user has a permission.
+	Checker interface {
+		Allowed(username, permission string) (bool, error)
+	}
+
+	// RoutePermission declares the permission needed by a route.
+	RoutePermission struct {
+		Method     string
+		Path       string
+		Permission string
+	}
+
+	AuthorizeMiddleware struct {
+		checker Checker
+		trees   map[string]*search.Tree
+	}
+)
+
+// NewAuthorizeMiddleware returns an AuthorizeMiddleware that checks the permissions of routes,
+// the requests to the routes that declare no permission are forbidden.
+func NewAuthorizeMiddleware(checker Checker, routes []RoutePermission) *AuthorizeMiddleware {
+	trees := make(map[string]*search.Tree)
+	for _, route := range routes {
+		tree, ok := trees[route.Method]
+		if !ok {
+			tree = search.NewTree()
+			trees[route.Method] = tree
+		}
+		if err := tree.Add(route.Path, route.Permission); err != nil {
+			panic(fmt.Errorf("%s %s: %w", route.Method, route.Path, err))
+		}
+	}
+
+	return &AuthorizeMiddleware{
+		checker: checker,
+		trees:   trees,
+	}
+}
+
+func (m *AuthorizeMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the jwt middleware puts the claims into the context before
+		userId, _ := r.Context().Value("userId").(string)
+		if len(userId) == 0 {
+			httpx.Error(w, errorx.New(http.StatusUnauthorized, "not logged in"))
+			return
+		}
+
+		permission, ok := m.permission(r)
+		if !ok {
+			httpx.Error(w, errorx.New(http.StatusForbidden, "no permission declared for the route"))
+			return
+		}
+
+		allowed, err := m.checker.Allowed(userId, permission)
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("check permission %s of %s failed: %v", permission, userId, err)
+			httpx.Error(w, errorx.New(http.StatusInternalServerError, "failed to check the permission"))
+			return
+		}
+		if !allowed {
+			httpx.Error(w, errorx.New(http.StatusForbidden, fmt.Sprintf("permission %s is required", permission)))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (m *AuthorizeMiddleware) permission(r *http.Request) (string, bool) {
+	tree, ok := m.trees[r.Method]
+	if !ok {
+		return "", false
+	}
+
+	result, ok := tree.Search(r.URL.Path)
+	if !ok {
+		return "", false
+	}
+
+	return result.Item.(string), true
+}
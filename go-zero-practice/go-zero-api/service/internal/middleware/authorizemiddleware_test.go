@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-zero-api/common/errorx"
+	"go-zero-api/service/internal/rbac"
+	"go-zero-api/service/internal/rbac/rbactest"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func newTestStore(t *testing.T) *rbac.Store {
+	store, err := rbac.NewStore(rbactest.NewDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+type errChecker struct{}
+
+func (errChecker) Allowed(string, string) (bool, error) {
+	return false, fmt.Errorf("store is down")
+}
+
+func TestAuthorizeMiddleware(t *testing.T) {
+	httpx.SetErrorHandler(errorx.Handler)
+
+	store := newTestStore(t)
+	if err := store.SetRole("root", rbac.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetRole("gone", rbac.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetDeactivated("gone", true); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewAuthorizeMiddleware(store, Permissions).Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		user   string
+		method string
+		path   string
+		expect int
+	}{
+		{"admin lists users", "root", http.MethodGet, "/admin/users", http.StatusOK},
+		{"admin changes role", "root", http.MethodPut, "/admin/users/alice/role", http.StatusOK},
+		{"admin deactivates", "root", http.MethodPost, "/admin/users/alice/deactivate", http.StatusOK},
+		{"user lists users", "alice", http.MethodGet, "/admin/users", http.StatusForbidden},
+		{"user changes role", "alice", http.MethodPut, "/admin/users/alice/role", http.StatusForbidden},
+		{"deactivated admin", "gone", http.MethodGet, "/admin/users", http.StatusForbidden},
+		{"no user", "", http.MethodGet, "/admin/users", http.StatusUnauthorized},
+		{"undeclared path", "root", http.MethodGet, "/admin/secrets", http.StatusForbidden},
+		{"undeclared method", "root", http.MethodDelete, "/admin/users", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			if len(test.user) > 0 {
+				r = r.WithContext(context.WithValue(r.Context(), "userId", test.user))
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+			if w.Code != test.expect {
+				t.Fatalf("expect %d, got %d: %s", test.expect, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthorizeMiddlewareStoreError(t *testing.T) {
+	httpx.SetErrorHandler(errorx.Handler)
+
+	handler := NewAuthorizeMiddleware(errChecker{}, Permissions).Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	r = r.WithContext(context.WithValue(r.Context(), "userId", "root"))
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expect 500, got %d", w.Code)
+	}
+}
+
+func TestNewAuthorizeMiddlewareDuplicateRoute(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expect panic on duplicate route")
+		}
+	}()
+
+	NewAuthorizeMiddleware(errChecker{}, []RoutePermission{
+		{Method: http.MethodGet, Path: "/admin/users", Permission: rbac.PermListUsers},
+		{Method: http.MethodGet, Path: "/admin/users", Permission: rbac.PermReadUsers},
+	})
+}
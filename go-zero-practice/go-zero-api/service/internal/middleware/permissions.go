@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-zero-api/service/internal/rbac"
+)
+
+// Permissions are the permissions of the routes behind the Authorize middleware,
+// keep them in sync with user.api.
+var Permissions = []RoutePermission{
+	{Method: http.MethodGet, Path: "/admin/users", Permission: rbac.PermListUsers},
+	{Method: http.MethodPut, Path: "/admin/users/:userId/role", Permission: rbac.PermChangeRole},
+	{Method: http.MethodPost, Path: "/admin/users/:userId/deactivate", Permission: rbac.PermDeactivate},
+	{Method: http.MethodGet, Path: "/admin/users/:userId/logins", Permission: rbac.PermAudit},
+	{Method: http.MethodPost, Path: "/admin/users/:userId/unlock", Permission: rbac.PermUnlock},
+}
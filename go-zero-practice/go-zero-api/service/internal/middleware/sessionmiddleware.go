@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-zero-api/common/errorx"
+	"go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
+
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+type (
+	// Users finds the users of the tokens.
+	Users interface {
+		FindOneByUsername(username string) (*model.User, error)
+	}
+
+	// Accounts finds the accounts of the users, which tell whether they are deactivated.
+	Accounts interface {
+		Account(username string) (rbac.Account, error)
+	}
+
+	SessionMiddleware struct {
+		users    Users
+		accounts Accounts
+	}
+)
+
+// NewSessionMiddleware returns a SessionMiddleware that rejects the access tokens
+// of the removed and the deactivated users and the tokens revoked by a password reset.
+func NewSessionMiddleware(users Users, accounts Accounts) *SessionMiddleware {
+	return &SessionMiddleware{
+		users:    users,
+		accounts: accounts,
+	}
+}
+
+func (m *SessionMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the jwt middleware puts the claims into the context before
+		userId, _ := r.Context().Value("userId").(string)
+		if len(userId) == 0 {
+			httpx.Error(w, errorx.New(http.StatusUnauthorized, "not logged in"))
+			return
+		}
+
+		user, err := m.users.FindOneByUsername(userId)
+		switch err {
+		case nil:
+		case model.ErrUserNotFound:
+			httpx.Error(w, errorx.New(http.StatusUnauthorized, "the token is revoked"))
+			return
+		default:
+			logx.WithContext(r.Context()).Errorf("find user %s failed: %v", userId, err)
+			httpx.Error(w, errorx.New(http.StatusInternalServerError, "failed to check the token"))
+			return
+		}
+
+		if tokenVersion(r.Context().Value("ver")) != user.TokenVersion {
+			httpx.Error(w, errorx.New(http.StatusUnauthorized, "the token is revoked"))
+			return
+		}
+
+		// the tokens issued before the deactivation are still valid otherwise
+		account, err := m.accounts.Account(userId)
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("find account %s failed: %v", userId, err)
+			httpx.Error(w, errorx.New(http.StatusInternalServerError, "failed to check the token"))
+			return
+		}
+		if account.Deactivated {
+			httpx.Error(w, errorx.New(http.StatusUnauthorized, "the account is deactivated"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// tokenVersion returns the version claim, the jwt middleware keeps the numbers as
+// json.Number. The tokens issued before the token versions have none, they are of version 0.
+func tokenVersion(claim interface{}) int64 {
+	switch v := claim.(type) {
+	case json.Number:
+		version, err := v.Int64()
+		if err != nil {
+			return -1
+		}
+		return version
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-zero-api/common/errorx"
+	"go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+type testUsers map[string]int64
+
+func (u testUsers) FindOneByUsername(username string) (*model.User, error) {
+	switch username {
+	case "broken":
+		return nil, fmt.Errorf("database is down")
+	}
+
+	version, ok := u[username]
+	if !ok {
+		return nil, model.ErrUserNotFound
+	}
+
+	return &model.User{Username: username, TokenVersion: version}, nil
+}
+
+func TestSessionMiddleware(t *testing.T) {
+	httpx.SetErrorHandler(errorx.Handler)
+
+	store := newTestStore(t)
+	handler := NewSessionMiddleware(testUsers{"alice": 0, "bob": 2, "dave": 0}, store).Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	// dave's token is issued before the deactivation
+	if err := store.SetDeactivated("dave", true); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		version interface{}
+		expect  int
+	}{
+		{"current version", "bob", json.Number("2"), http.StatusOK},
+		{"reset password", "bob", json.Number("1"), http.StatusUnauthorized},
+		{"token before the versions", "alice", nil, http.StatusOK},
+		{"removed user", "carol", json.Number("0"), http.StatusUnauthorized},
+		{"no user", "", nil, http.StatusUnauthorized},
+		{"store error", "broken", json.Number("0"), http.StatusInternalServerError},
+		{"deactivated", "dave", json.Number("0"), http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(test.user) > 0 {
+				ctx = context.WithValue(ctx, "userId", test.user)
+			}
+			if test.version != nil {
+				ctx = context.WithValue(ctx, "ver", test.version)
+			}
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/users/bob", nil).WithContext(ctx))
+			if w.Code != test.expect {
+				t.Fatalf("expect %d, got %d: %s", test.expect, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSessionMiddlewareAccountError(t *testing.T) {
+	httpx.SetErrorHandler(errorx.Handler)
+
+	handler := NewSessionMiddleware(testUsers{"alice": 0}, errAccounts{}).Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	ctx := context.WithValue(context.Background(), "userId", "alice")
+	handler(w, httptest.NewRequest(http.MethodGet, "/users/alice", nil).WithContext(ctx))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expect %d, got %d: %s", http.StatusInternalServerError, w.Code, w.Body.String())
+	}
+}
+
+type errAccounts struct{}
+
+func (errAccounts) Account(string) (rbac.Account, error) {
+	return rbac.Account{}, fmt.Errorf("store is down")
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// LoginAttempt is an entry of the login audit log.
+	LoginAttempt struct {
+		Id         int64     `gorm:"primaryKey"`
+		Username   string    `gorm:"size:64;not null"`
+		Ip         string    `gorm:"size:64;not null;default:''"`
+		UserAgent  string    `gorm:"size:255;not null;default:''"`
+		Success    bool      `gorm:"not null"`
+		Reason     string    `gorm:"size:64;not null;default:''"`
+		CreateTime time.Time `gorm:"autoCreateTime"`
+	}
+
+	// LoginFailure counts the failed logins of a user since the last lock or success.
+	LoginFailure struct {
+		Username    string `gorm:"primaryKey;size:64"`
+		Failures    int    `gorm:"not null;default:0"`
+		LockedUntil *time.Time
+		UpdateTime  time.Time `gorm:"autoUpdateTime"`
+	}
+
+	// LoginModel keeps the login audit log and the lockouts.
+	LoginModel interface {
+		// Record adds attempt to the audit log
+		Record(attempt *LoginAttempt) error
+		// Attempts returns the latest attempts of the user, the latest first
+		Attempts(username string, limit int) ([]*LoginAttempt, error)
+		// LockedUntil returns the end of the lock of the user, zero if not locked at now
+		LockedUntil(username string, now time.Time) (time.Time, error)
+		// Fail counts a failed login, the user is locked for lockFor after maxFailures,
+		// the end of the lock is returned, zero if not locked
+		Fail(username string, now time.Time, maxFailures int, lockFor time.Duration) (time.Time, error)
+		// Unlock forgets the failed logins and the lock of the user
+		Unlock(username string) error
+	}
+
+	defaultLoginModel struct {
+		db *gorm.DB
+	}
+)
+
+// NewLoginModel returns a LoginModel that keeps the attempts and the lockouts in db.
+func NewLoginModel(db *gorm.DB) LoginModel {
+	return &defaultLoginModel{db: db}
+}
+
+func (m *defaultLoginModel) Record(attempt *LoginAttempt) error {
+	return m.db.Create(attempt).Error
+}
+
+func (m *defaultLoginModel) Attempts(username string, limit int) ([]*LoginAttempt, error) {
+	var resp []*LoginAttempt
+	err := m.db.Where("username = ?", username).
+		Order("id DESC").
+		Limit(limit).
+		Find(&resp).Error
+	return resp, err
+}
+
+func (m *defaultLoginModel) LockedUntil(username string, now time.Time) (time.Time, error) {
+	var failure LoginFailure
+	err := m.db.Where("username = ?", username).Take(&failure).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if failure.LockedUntil == nil || !failure.LockedUntil.After(now) {
+		return time.Time{}, nil
+	}
+
+	return *failure.LockedUntil, nil
+}
+
+func (m *defaultLoginModel) Fail(username string, now time.Time, maxFailures int, lockFor time.Duration) (time.Time, error) {
+	var lockedUntil time.Time
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		failure := LoginFailure{Username: username}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).Take(&failure).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		failure.Failures++
+		if failure.Failures >= maxFailures {
+			// the count starts again after the lock
+			lockedUntil = now.Add(lockFor)
+			failure.Failures = 0
+			failure.LockedUntil = &lockedUntil
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "username"}},
+			DoUpdates: clause.AssignmentColumns([]string{"failures", "locked_until", "update_time"}),
+		}).Create(&failure).Error
+	})
+
+	return lockedUntil, err
+}
+
+func (m *defaultLoginModel) Unlock(username string) error {
+	return m.db.Where("username = ?", username).Delete(&LoginFailure{}).Error
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLoginModel(t *testing.T) LoginModel {
+	db := newTestDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewLoginModel(db)
+}
+
+func TestLoginModelLockout(t *testing.T) {
+	logins := newTestLoginModel(t)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		until, err := logins.Fail("alice", now, 3, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !until.IsZero() {
+			t.Fatalf("expect not locked after %d failures", i+1)
+		}
+	}
+	until, err := logins.Fail("alice", now, 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !until.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expect locked until %s, got %s", now.Add(time.Minute), until)
+	}
+
+	if until, err := logins.LockedUntil("alice", now); err != nil || until.IsZero() {
+		t.Fatalf("expect alice locked, got %s, %v", until, err)
+	}
+	if until, err := logins.LockedUntil("alice", now.Add(2*time.Minute)); err != nil || !until.IsZero() {
+		t.Fatalf("expect the lock expired, got %s, %v", until, err)
+	}
+	if until, err := logins.LockedUntil("bob", now); err != nil || !until.IsZero() {
+		t.Fatalf("expect bob not locked, got %s, %v", until, err)
+	}
+
+	if err := logins.Unlock("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if until, err := logins.LockedUntil("alice", now); err != nil || !until.IsZero() {
+		t.Fatalf("expect alice unlocked, got %s, %v", until, err)
+	}
+}
+
+func TestLoginModelAttempts(t *testing.T) {
+	logins := newTestLoginModel(t)
+
+	for _, success := range []bool{false, false, true} {
+		if err := logins.Record(&LoginAttempt{
+			Username: "alice",
+			Ip:       "127.0.0.1",
+			Success:  success,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logins.Record(&LoginAttempt{Username: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	attempts, err := logins.Attempts("alice", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expect 2 attempts, got %d", len(attempts))
+	}
+	if !attempts[0].Success || attempts[1].Success {
+		t.Fatalf("expect the latest attempt first, got %+v, %+v", attempts[0], attempts[1])
+	}
+}
@@ -0,0 +1,243 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go-zero-api/common/migrate"
+
+	"gorm.io/gorm"
+)
+
+const migrationTable = "schema_migrations"
+
+// migrations are applied in order, never change an applied migration, add a new one instead.
+// The migrations use their own structs, so that changing the types does not change the history.
+var migrations = []migrate.Migration{
+	{Version: 1, Name: "create users", Up: createUsers},
+	{Version: 2, Name: "create roles", Up: createRoles},
+	{Version: 3, Name: "create account lifecycle", Up: createAccountLifecycle},
+	{Version: 4, Name: "move users to the users table", Up: moveUsers},
+	{Version: 5, Name: "add token versions", Up: addTokenVersions},
+}
+
+// Migrate applies the pending migrations of the user-api to db.
+func Migrate(db *gorm.DB) error {
+	return migrate.Migrate(db, migrationTable, migrations)
+}
+
+type userV1 struct {
+	Username    string `gorm:"size:64;uniqueIndex:idx_register_requests_username"`
+	Password    string `gorm:"size:255"`
+	Phonenumber string `gorm:"size:32;uniqueIndex:idx_register_requests_phonenumber"`
+}
+
+func (userV1) TableName() string {
+	return "register_requests"
+}
+
+// createUsers creates the register_requests table, which keeps the users until
+// moveUsers. The databases of the former AutoMigrate already have the table, but
+// without the unique indexes, and may hold duplicate rows: the former CreateUser
+// inserted the same fixed user on every request. They are deduplicated first.
+func createUsers(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&userV1{}) {
+		return migrator.CreateTable(&userV1{})
+	}
+
+	if err := dedupeUsersV1(tx); err != nil {
+		return err
+	}
+	for _, index := range []string{"idx_register_requests_username", "idx_register_requests_phonenumber"} {
+		if migrator.HasIndex(&userV1{}, index) {
+			continue
+		}
+		if err := migrator.CreateIndex(&userV1{}, index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dedupeUsersV1 merges the rows of register_requests that have the same username, password
+// and phone number into one row of these columns. Other columns a former schema may have added
+// are not compared, the merged row gets their defaults. The rows that only share a username or
+// a phone number can't be merged safely, they are reported and the migration fails, so that
+// the operator decides which rows to keep.
+func dedupeUsersV1(tx *gorm.DB) error {
+	var copies []userV1
+	err := tx.Model(&userV1{}).Select("username, password, phonenumber").
+		Group("username, password, phonenumber").Having("COUNT(*) > 1").Scan(&copies).Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range copies {
+		err := tx.Where("username = ? AND password = ? AND phonenumber = ?", user.Username, user.Password, user.Phonenumber).
+			Delete(&userV1{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+	}
+
+	var usernames, phonenumbers []string
+	for column, values := range map[string]*[]string{"username": &usernames, "phonenumber": &phonenumbers} {
+		err := tx.Model(&userV1{}).Select(column).Group(column).Having("COUNT(*) > 1").Order(column).Scan(values).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(usernames) > 0 || len(phonenumbers) > 0 {
+		return fmt.Errorf("register_requests has different rows of the usernames %q and the phone numbers %q, "+
+			"delete or change the extra rows so that every username and phone number is registered once, then restart",
+			usernames, phonenumbers)
+	}
+
+	return nil
+}
+
+type (
+	roleV2 struct {
+		Name string `gorm:"primaryKey;size:32"`
+	}
+
+	rolePermissionV2 struct {
+		Role       string `gorm:"primaryKey;size:32"`
+		Permission string `gorm:"primaryKey;size:64"`
+	}
+
+	accountV2 struct {
+		Username    string `gorm:"primaryKey;size:64"`
+		Role        string `gorm:"size:32;not null"`
+		Deactivated bool   `gorm:"not null;default:false"`
+		UpdatedAt   time.Time
+	}
+)
+
+func (roleV2) TableName() string {
+	return "roles"
+}
+
+func (rolePermissionV2) TableName() string {
+	return "role_permissions"
+}
+
+func (accountV2) TableName() string {
+	return "accounts"
+}
+
+// createRoles creates the tables of the rbac store, they were created by AutoMigrate before.
+func createRoles(tx *gorm.DB) error {
+	return migrate.CreateTables(tx, &roleV2{}, &rolePermissionV2{}, &accountV2{})
+}
+
+type (
+	oneTimeTokenV3 struct {
+		Id         int64     `gorm:"primaryKey"`
+		Username   string    `gorm:"size:64;not null;uniqueIndex:idx_one_time_tokens_username_purpose"`
+		Purpose    string    `gorm:"size:16;not null;uniqueIndex:idx_one_time_tokens_username_purpose"`
+		TokenHash  string    `gorm:"size:64;not null;index:idx_one_time_tokens_token_hash"`
+		Target     string    `gorm:"size:255;not null;default:''"`
+		Attempts   int       `gorm:"not null;default:0"`
+		ExpireTime time.Time `gorm:"not null"`
+		CreateTime time.Time `gorm:"not null"`
+	}
+
+	loginAttemptV3 struct {
+		Id         int64     `gorm:"primaryKey"`
+		Username   string    `gorm:"size:64;not null;index:idx_login_attempts_username"`
+		Ip         string    `gorm:"size:64;not null;default:''"`
+		UserAgent  string    `gorm:"size:255;not null;default:''"`
+		Success    bool      `gorm:"not null"`
+		Reason     string    `gorm:"size:64;not null;default:''"`
+		CreateTime time.Time `gorm:"not null"`
+	}
+
+	loginFailureV3 struct {
+		Username    string `gorm:"primaryKey;size:64"`
+		Failures    int    `gorm:"not null;default:0"`
+		LockedUntil *time.Time
+		UpdateTime  time.Time `gorm:"not null"`
+	}
+
+	phoneVerificationV3 struct {
+		Username    string    `gorm:"primaryKey;size:64"`
+		Phonenumber string    `gorm:"size:32;not null"`
+		VerifyTime  time.Time `gorm:"not null"`
+	}
+)
+
+func (oneTimeTokenV3) TableName() string {
+	return "one_time_tokens"
+}
+
+func (loginAttemptV3) TableName() string {
+	return "login_attempts"
+}
+
+func (loginFailureV3) TableName() string {
+	return "login_failures"
+}
+
+func (phoneVerificationV3) TableName() string {
+	return "phone_verifications"
+}
+
+// createAccountLifecycle creates the tables of the verifications, the password resets,
+// the lockouts and the login audit log.
+func createAccountLifecycle(tx *gorm.DB) error {
+	return migrate.CreateTables(tx, &oneTimeTokenV3{}, &loginAttemptV3{}, &loginFailureV3{}, &phoneVerificationV3{})
+}
+
+type userV4 struct {
+	Id           int64     `gorm:"primaryKey"`
+	Username     string    `gorm:"size:64;not null;uniqueIndex:idx_users_username"`
+	PasswordHash string    `gorm:"size:255;not null"`
+	Phonenumber  string    `gorm:"size:32;not null;uniqueIndex:idx_users_phonenumber"`
+	CreateTime   time.Time `gorm:"not null"`
+	UpdateTime   time.Time `gorm:"not null"`
+}
+
+func (userV4) TableName() string {
+	return "users"
+}
+
+// moveUsers moves the users from the table of the request type to the users table,
+// which has an id and timestamps. The former users get the time of the migration.
+func moveUsers(tx *gorm.DB) error {
+	if err := migrate.CreateTables(tx, &userV4{}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err := tx.Exec("INSERT INTO users (username, password_hash, phonenumber, create_time, update_time) "+
+		"SELECT username, password, phonenumber, ?, ? FROM register_requests ORDER BY username", now, now).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropTable(&userV1{})
+}
+
+type userV5 struct {
+	TokenVersion int64 `gorm:"not null;default:0"`
+}
+
+func (userV5) TableName() string {
+	return "users"
+}
+
+// addTokenVersions adds the token version of the users, the tokens of an older version
+// are revoked. The former users start at 0, like the tokens issued before.
+func addTokenVersions(tx *gorm.DB) error {
+	if tx.Migrator().HasColumn(&userV5{}, "TokenVersion") {
+		return nil
+	}
+
+	return tx.Migrator().AddColumn(&userV5{}, "TokenVersion")
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"go-zero-api/common/migrate"
+	"go-zero-api/service/internal/types"
+)
+
+func TestMigrate(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	version, err := migrate.Version(db, migrationTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != migrations[len(migrations)-1].Version {
+		t.Fatalf("expect version %d, got %d", migrations[len(migrations)-1].Version, version)
+	}
+
+	// migrating again applies nothing
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.Table(migrationTable).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != int64(len(migrations)) {
+		t.Fatalf("expect %d applied migrations, got %d", len(migrations), count)
+	}
+
+	for _, table := range []string{"users", "roles", "role_permissions", "accounts"} {
+		if !db.Migrator().HasTable(table) {
+			t.Fatalf("expect table %s", table)
+		}
+	}
+}
+
+func TestMigrateAutoMigratedDatabase(t *testing.T) {
+	db := newTestDB(t)
+
+	// the databases before the migrations were created by AutoMigrate on the request type
+	if err := db.AutoMigrate(&types.RegisterRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&types.RegisterRequest{Username: "alice", Password: "hash", Phonenumber: "13800000000"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// the users are moved to the users table
+	if db.Migrator().HasTable(&userV1{}) {
+		t.Fatal("expect the register_requests table dropped")
+	}
+	for _, index := range []string{"idx_users_username", "idx_users_phonenumber"} {
+		if !db.Migrator().HasIndex(&userV4{}, index) {
+			t.Fatalf("expect index %s", index)
+		}
+	}
+
+	users := NewUserModel(db)
+	user, err := users.FindOneByUsername("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id == 0 || user.PasswordHash != "hash" || user.Phonenumber != "13800000000" || user.CreateTime.IsZero() {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if err := users.Insert(&User{Username: "alice", PasswordHash: "hash", Phonenumber: "13900000000"}); err != ErrUserExists {
+		t.Fatalf("expect ErrUserExists, got %v", err)
+	}
+}
+
+func TestMigrateDuplicateUsers(t *testing.T) {
+	db := newTestDB(t)
+
+	// the former CreateUser inserted the same fixed user on every request
+	if err := db.AutoMigrate(&types.RegisterRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := db.Create(&types.RegisterRequest{Username: "name", Password: "passWord", Phonenumber: "123"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&types.RegisterRequest{Username: "alice", Password: "hash", Phonenumber: "13800000000"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var usernames []string
+	if err := db.Model(&User{}).Order("username").Pluck("username", &usernames).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(usernames, ",") != "alice,name" {
+		t.Fatalf("expect the identical rows merged, got %v", usernames)
+	}
+}
+
+func TestMigrateConflictingUsers(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.AutoMigrate(&types.RegisterRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, phone := range []string{"13800000000", "13900000000"} {
+		if err := db.Create(&types.RegisterRequest{Username: "alice", Password: "hash", Phonenumber: phone}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the rows differ, the operator has to choose the one to keep
+	err := Migrate(db)
+	if err == nil || !strings.Contains(err.Error(), `usernames ["alice"]`) || !strings.Contains(err.Error(), "delete or change the extra rows") {
+		t.Fatalf("expect the conflicting usernames reported, got %v", err)
+	}
+	if version, err := migrate.Version(db, migrationTable); err != nil || version != 0 {
+		t.Fatalf("expect nothing migrated, got version %d, %v", version, err)
+	}
+	var count int64
+	if err := db.Model(&userV1{}).Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("expect the rows kept, got %d, %v", count, err)
+	}
+}
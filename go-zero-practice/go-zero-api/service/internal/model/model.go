@@ -1,21 +1,23 @@
 package model
 
 import (
-	"go-zero-api/service/internal/types"
+	"fmt"
+
+	"go-zero-api/common/database"
 
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-var db *gorm.DB
-
-func init() {
-
-	//open a db connection
-	db, err := gorm.Open(sqlite.Open("/data/go-zero.db"), &gorm.Config{})
+// MustOpen opens the database of dsn and migrates it to the latest version, exits on error.
+func MustOpen(dsn string) *gorm.DB {
+	db, err := database.Open(dsn, nil)
 	if err != nil {
-		panic("failed to connect database")
+		panic(fmt.Errorf("failed to connect database: %w", err))
 	}
-	//Migrate the schema
-	db.AutoMigrate(&types.RegisterRequest{})
+
+	if err := Migrate(db); err != nil {
+		panic(fmt.Errorf("failed to migrate database: %w", err))
+	}
+
+	return db
 }
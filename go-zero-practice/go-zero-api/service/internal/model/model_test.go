@@ -0,0 +1,28 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"go-zero-api/common/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("sqlite://file:%s?mode=memory&cache=shared", t.Name())
+	db, err := database.Open(dsn, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	return db
+}
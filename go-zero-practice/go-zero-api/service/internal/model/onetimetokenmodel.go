@@ -0,0 +1,180 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// PurposePhone is the purpose of the codes that verify the phone numbers.
+	PurposePhone = "phone"
+	// PurposeReset is the purpose of the tokens that reset the passwords.
+	PurposeReset = "reset"
+)
+
+var (
+	// ErrTokenInvalid means the token is wrong, expired or used already.
+	ErrTokenInvalid = errors.New("invalid or expired token")
+	// ErrTooManyAttempts means the token was guessed wrong too often and is dropped.
+	ErrTooManyAttempts = errors.New("too many wrong attempts, request a new token")
+)
+
+type (
+	// OneTimeToken is a secret sent to a user, only its hash is kept.
+	OneTimeToken struct {
+		Id        int64  `gorm:"primaryKey"`
+		Username  string `gorm:"size:64;not null"`
+		Purpose   string `gorm:"size:16;not null"`
+		TokenHash string `gorm:"size:64;not null"`
+		// Target is where the token was sent to, like the phone number
+		Target     string    `gorm:"size:255;not null;default:''"`
+		Attempts   int       `gorm:"not null;default:0"`
+		ExpireTime time.Time `gorm:"not null"`
+		CreateTime time.Time `gorm:"autoCreateTime"`
+	}
+
+	// OneTimeTokenModel keeps the one-time tokens, a user has at most one per purpose.
+	OneTimeTokenModel interface {
+		// Issue replaces the token of the user and purpose with token
+		Issue(token *OneTimeToken) error
+		// Latest returns the token of the user and purpose, nil if none
+		Latest(username, purpose string) (*OneTimeToken, error)
+		// Check consumes the token of the user and purpose if its hash is hash,
+		// the wrong attempts are counted and the token is dropped after maxAttempts
+		Check(username, purpose, hash string, now time.Time, maxAttempts int) (*OneTimeToken, error)
+		// Take consumes the token of purpose whose hash is hash
+		Take(purpose, hash string, now time.Time) (*OneTimeToken, error)
+	}
+
+	defaultOneTimeTokenModel struct {
+		db *gorm.DB
+	}
+)
+
+// NewOneTimeTokenModel returns a OneTimeTokenModel that keeps the tokens in db.
+func NewOneTimeTokenModel(db *gorm.DB) OneTimeTokenModel {
+	return &defaultOneTimeTokenModel{db: db}
+}
+
+// HashToken returns the hash of token to keep.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *defaultOneTimeTokenModel) Issue(token *OneTimeToken) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ? AND purpose = ?", token.Username, token.Purpose).
+			Delete(&OneTimeToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (m *defaultOneTimeTokenModel) Latest(username, purpose string) (*OneTimeToken, error) {
+	var resp OneTimeToken
+	err := m.db.Where("username = ? AND purpose = ?", username, purpose).Take(&resp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (m *defaultOneTimeTokenModel) Check(username, purpose, hash string, now time.Time, maxAttempts int) (*OneTimeToken, error) {
+	var resp *OneTimeToken
+	var failure error
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var token OneTimeToken
+		// the parallel attempts wait for the row, so each of them sees the count of the others
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("username = ? AND purpose = ?", username, purpose).Take(&token).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			failure = ErrTokenInvalid
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !token.ExpireTime.After(now):
+			failure = ErrTokenInvalid
+		case token.TokenHash == hash:
+			resp = &token
+		default:
+			// the update checks the count again for the databases that don't lock the row
+			result := tx.Model(&token).Where("attempts < ?", maxAttempts-1).
+				Update("attempts", gorm.Expr("attempts + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				// the failures are kept, so they are not rolled back
+				failure = ErrTokenInvalid
+				return nil
+			}
+			failure = ErrTooManyAttempts
+		}
+
+		result := tx.Delete(&token)
+		if result.Error != nil {
+			return result.Error
+		}
+		// another attempt consumed or dropped the token since
+		if resp != nil && result.RowsAffected == 0 {
+			resp, failure = nil, ErrTokenInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if failure != nil {
+		return nil, failure
+	}
+
+	return resp, nil
+}
+
+func (m *defaultOneTimeTokenModel) Take(purpose, hash string, now time.Time) (*OneTimeToken, error) {
+	var resp OneTimeToken
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("purpose = ? AND token_hash = ?", purpose, hash).Take(&resp).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&resp)
+		if result.Error != nil {
+			return result.Error
+		}
+		// another request took the token since
+		if result.RowsAffected == 0 {
+			return ErrTokenInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the expired tokens are dropped too
+	if !resp.ExpireTime.After(now) {
+		return nil, ErrTokenInvalid
+	}
+
+	return &resp, nil
+}
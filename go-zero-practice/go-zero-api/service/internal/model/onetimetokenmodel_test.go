@@ -0,0 +1,160 @@
+package model
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestTokenModel(t *testing.T) OneTimeTokenModel {
+	db := newTestDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewOneTimeTokenModel(db)
+}
+
+func TestOneTimeTokenCheck(t *testing.T) {
+	tokens := newTestTokenModel(t)
+	now := time.Now()
+
+	issue := func(code string) {
+		if err := tokens.Issue(&OneTimeToken{
+			Username:   "alice",
+			Purpose:    PurposePhone,
+			TokenHash:  HashToken(code),
+			Target:     "13800000000",
+			ExpireTime: now.Add(time.Minute),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issue("111111")
+	// a new token replaces the old one
+	issue("222222")
+	if _, err := tokens.Check("alice", PurposePhone, HashToken("111111"), now, 3); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the replaced token, got %v", err)
+	}
+	token, err := tokens.Check("alice", PurposePhone, HashToken("222222"), now, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Target != "13800000000" {
+		t.Fatalf("unexpected target: %s", token.Target)
+	}
+	if _, err := tokens.Check("alice", PurposePhone, HashToken("222222"), now, 3); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the used token, got %v", err)
+	}
+
+	issue("333333")
+	for i := 0; i < 2; i++ {
+		if _, err := tokens.Check("alice", PurposePhone, HashToken("000000"), now, 3); err != ErrTokenInvalid {
+			t.Fatalf("expect ErrTokenInvalid for a wrong code, got %v", err)
+		}
+	}
+	if _, err := tokens.Check("alice", PurposePhone, HashToken("000000"), now, 3); err != ErrTooManyAttempts {
+		t.Fatalf("expect ErrTooManyAttempts, got %v", err)
+	}
+	if _, err := tokens.Check("alice", PurposePhone, HashToken("333333"), now, 3); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the dropped token, got %v", err)
+	}
+	if latest, err := tokens.Latest("alice", PurposePhone); err != nil || latest != nil {
+		t.Fatalf("expect the token dropped, got %+v, %v", latest, err)
+	}
+
+	issue("444444")
+	if _, err := tokens.Check("alice", PurposePhone, HashToken("444444"), now.Add(2*time.Minute), 3); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the expired token, got %v", err)
+	}
+}
+
+func TestOneTimeTokenCheckConcurrently(t *testing.T) {
+	tokens := newTestTokenModel(t)
+	now := time.Now()
+	const maxAttempts = 3
+	if err := tokens.Issue(&OneTimeToken{
+		Username:   "alice",
+		Purpose:    PurposePhone,
+		TokenHash:  HashToken("333333"),
+		ExpireTime: now.Add(time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// maxAttempts wrong codes at once use up the token like one after another
+	var wg sync.WaitGroup
+	errs := make(chan error, maxAttempts)
+	for i := 0; i < maxAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- checkRetrying(tokens, HashToken("000000"), now, maxAttempts)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var dropped int
+	for err := range errs {
+		switch err {
+		case ErrTooManyAttempts:
+			dropped++
+		case ErrTokenInvalid:
+		default:
+			t.Fatalf("expect ErrTokenInvalid or ErrTooManyAttempts, got %v", err)
+		}
+	}
+	if dropped != 1 {
+		t.Errorf("expect one ErrTooManyAttempts, got %d", dropped)
+	}
+	if _, err := tokens.Check("alice", PurposePhone, HashToken("333333"), now, maxAttempts); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the dropped token, got %v", err)
+	}
+}
+
+// checkRetrying checks the code of alice, retrying when the transactions
+// of sqlite collide instead of waiting for the row.
+func checkRetrying(tokens OneTimeTokenModel, hash string, now time.Time, maxAttempts int) error {
+	for i := 0; ; i++ {
+		_, err := tokens.Check("alice", PurposePhone, hash, now, maxAttempts)
+		if err == nil || err == ErrTokenInvalid || err == ErrTooManyAttempts || i == 100 {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOneTimeTokenTake(t *testing.T) {
+	tokens := newTestTokenModel(t)
+	now := time.Now()
+
+	for _, user := range []string{"alice", "bob"} {
+		if err := tokens.Issue(&OneTimeToken{
+			Username:   user,
+			Purpose:    PurposeReset,
+			TokenHash:  HashToken(user + "-token"),
+			ExpireTime: now.Add(time.Minute),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := tokens.Take(PurposePhone, HashToken("alice-token"), now); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for another purpose, got %v", err)
+	}
+	token, err := tokens.Take(PurposeReset, HashToken("alice-token"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Username != "alice" {
+		t.Fatalf("unexpected user: %s", token.Username)
+	}
+	if _, err := tokens.Take(PurposeReset, HashToken("alice-token"), now); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the used token, got %v", err)
+	}
+	if _, err := tokens.Take(PurposeReset, HashToken("bob-token"), now.Add(2*time.Minute)); err != ErrTokenInvalid {
+		t.Fatalf("expect ErrTokenInvalid for the expired token, got %v", err)
+	}
+}
@@ -2,42 +2,166 @@ package model
 
 import (
 	"errors"
-	"fmt"
-	"go-zero-api/service/internal/types"
+	"time"
 
-	"gorm.io/driver/sqlite"
+	"go-zero-api/common/database"
+
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	// ErrUserExists means the username or the phone number is already registered.
+	ErrUserExists = errors.New("username or phone number is already registered")
+	// ErrUserNotFound means there is no user with the username.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidLogin means the username or the password is not correct.
+	ErrInvalidLogin = errors.New("username or password is not correct")
+)
 
+type (
+	// User is a registered user, the users are identified by their usernames in the apis.
+	User struct {
+		Id           int64  `gorm:"primaryKey"`
+		Username     string `gorm:"size:64;not null"`
+		PasswordHash string `gorm:"size:255;not null"`
+		Phonenumber  string `gorm:"size:32;not null"`
+		// TokenVersion is put into the tokens, the tokens of an older version are revoked
+		TokenVersion int64     `gorm:"not null;default:0"`
+		CreateTime   time.Time `gorm:"autoCreateTime"`
+		UpdateTime   time.Time `gorm:"autoUpdateTime"`
+	}
 
-// CreateUser add a new user
-func CreateUser(name, passWord string) interface{} {
-	//open a db connection
-	db.Create(&types.RegisterRequest{
-		Username:    "name",
-		Password:    "passWord",
-		Phonenumber: "123",
-	})
-	return nil
+	// UserModel is the repository of the users.
+	UserModel interface {
+		// Insert adds a new user, ErrUserExists if the username or the phone number is taken
+		Insert(data *User) error
+		// FindOneByUsername returns the user, ErrUserNotFound if none
+		FindOneByUsername(username string) (*User, error)
+		// List returns a page of users ordered by username, and the number of all users
+		List(offset, limit int64) ([]*User, int64, error)
+		// SetPassword changes the password hash of the user and revokes the tokens of the user
+		SetPassword(username, passwordHash string) error
+		// VerifyPhone marks phoneNumber as the verified phone number of the user
+		VerifyPhone(username, phoneNumber string) error
+		// PhoneVerified tells whether the current phone number of the user is verified
+		PhoneVerified(username string) (bool, error)
+	}
+
+	// PhoneVerification records the verified phone number of a user.
+	PhoneVerification struct {
+		Username    string    `gorm:"primaryKey;size:64"`
+		Phonenumber string    `gorm:"size:32;not null"`
+		VerifyTime  time.Time `gorm:"not null"`
+	}
+
+	defaultUserModel struct {
+		db *gorm.DB
+	}
+)
+
+// CheckPassword tells whether password matches the password hash of the user.
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// NewUserModel returns a UserModel that keeps the users in db.
+func NewUserModel(db *gorm.DB) UserModel {
+	return &defaultUserModel{db: db}
+}
+
+func (m *defaultUserModel) Insert(data *User) error {
+	var count int64
+	err := m.db.Model(&User{}).
+		Where("username = ? OR phonenumber = ?", data.Username, data.Phonenumber).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUserExists
+	}
+
+	// the unique indexes catch the users registered since the check
+	err = m.db.Create(data).Error
+	if database.IsDuplicate(err) {
+		return ErrUserExists
+	}
+
+	return err
+}
+
+func (m *defaultUserModel) FindOneByUsername(username string) (*User, error) {
+	var resp User
+	err := m.db.Where("username = ?", username).Take(&resp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (m *defaultUserModel) List(offset, limit int64) ([]*User, int64, error) {
+	var total int64
+	if err := m.db.Model(&User{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*User
+	err := m.db.Order("username").
+		Offset(int(offset)).
+		Limit(int(limit)).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
 }
 
-// UserLogin login system
-func UserLogin(name, passWord string) error {
-	var user types.LoginRequest
-	db.Select("password").Where("username = ?", name).First(&user)
-	if user.Password != passWord {
-		return errors.New("password is not correct")
+func (m *defaultUserModel) SetPassword(username, passwordHash string) error {
+	result := m.db.Model(&User{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"password_hash": passwordHash,
+		"token_version": gorm.Expr("token_version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
 	}
-	//todo println("add 认证 鉴权 逻辑")
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
 	return nil
 }
 
-// GetUser get a single user
-func GetUser(userID string) (result types.RegisterRequest, err error) {
-	var user types.RegisterRequest
-	db.First(&user, userID)
+func (m *defaultUserModel) VerifyPhone(username, phoneNumber string) error {
+	return m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"phonenumber", "verify_time"}),
+	}).Create(&PhoneVerification{
+		Username:    username,
+		Phonenumber: phoneNumber,
+		VerifyTime:  time.Now(),
+	}).Error
+}
+
+func (m *defaultUserModel) PhoneVerified(username string) (bool, error) {
+	user, err := m.FindOneByUsername(username)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = m.db.Model(&PhoneVerification{}).
+		Where("username = ? AND phonenumber = ?", username, user.Phonenumber).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
 
-	resultUser := types.RegisterRequest{Username: user.Username, Password: user.Password}
-	return resultUser, nil
+	return count > 0, nil
 }
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestUserModel(t *testing.T) UserModel {
+	db := newTestDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewUserModel(db)
+}
+
+func TestUserModel(t *testing.T) {
+	users := newTestUserModel(t)
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("Secret-123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insert := func(name, phone string) error {
+		return users.Insert(&User{Username: name, PasswordHash: string(hashed), Phonenumber: phone})
+	}
+	if err := insert("alice", "13800000000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := insert("bob", "13900000000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := insert("alice", "13700000000"); err != ErrUserExists {
+		t.Fatalf("expect ErrUserExists for the username, got %v", err)
+	}
+	if err := insert("carol", "13800000000"); err != ErrUserExists {
+		t.Fatalf("expect ErrUserExists for the phone number, got %v", err)
+	}
+
+	alice, err := users.FindOneByUsername("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !alice.CheckPassword("Secret-123") {
+		t.Fatal("expect the password matched")
+	}
+	if alice.CheckPassword("wrong") {
+		t.Fatal("expect the wrong password rejected")
+	}
+
+	user, err := users.FindOneByUsername("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id == 0 || user.Username != "bob" || user.Phonenumber != "13900000000" || user.CreateTime.IsZero() {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if _, err := users.FindOneByUsername("nobody"); err != ErrUserNotFound {
+		t.Fatalf("expect ErrUserNotFound, got %v", err)
+	}
+
+	if err := users.SetPassword("bob", "new-hash"); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.SetPassword("nobody", "new-hash"); err != ErrUserNotFound {
+		t.Fatalf("expect ErrUserNotFound, got %v", err)
+	}
+
+	list, total, err := users.List(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(list) != 1 || list[0].Username != "bob" || list[0].PasswordHash != "new-hash" ||
+		list[0].TokenVersion != user.TokenVersion+1 {
+		t.Fatalf("unexpected list: %d %+v", total, list)
+	}
+}
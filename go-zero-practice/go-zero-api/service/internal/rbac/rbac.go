@@ -0,0 +1,179 @@
+package rbac
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// RoleAdmin is the role of the administrators.
+	RoleAdmin = "admin"
+	// RoleUser is the role of the users that have no account row yet.
+	RoleUser = "user"
+
+	// PermReadUsers allows to read the other users, everyone can read their own record.
+	PermReadUsers = "users:read"
+	// PermListUsers allows to list all users.
+	PermListUsers = "users:list"
+	// PermChangeRole allows to change the role of a user.
+	PermChangeRole = "users:role"
+	// PermDeactivate allows to deactivate a user.
+	PermDeactivate = "users:deactivate"
+	// PermAudit allows to read the login attempts of a user.
+	PermAudit = "users:audit"
+	// PermUnlock allows to unlock a user locked out after failed logins.
+	PermUnlock = "users:unlock"
+)
+
+var (
+	// ErrUnknownRole means the role is not in the roles table.
+	ErrUnknownRole = errors.New("unknown role")
+
+	// defaultPermissions are seeded when the roles table is created
+	defaultPermissions = map[string][]string{
+		RoleAdmin: {PermReadUsers, PermListUsers, PermChangeRole, PermDeactivate, PermAudit, PermUnlock},
+		RoleUser:  nil,
+	}
+)
+
+type (
+	// Role is a named set of permissions.
+	Role struct {
+		Name string `gorm:"primaryKey;size:32"`
+	}
+
+	// RolePermission grants a permission to a role.
+	RolePermission struct {
+		Role       string `gorm:"primaryKey;size:32"`
+		Permission string `gorm:"primaryKey;size:64"`
+	}
+
+	// Account keeps the role and the state of a user.
+	Account struct {
+		Username    string `gorm:"primaryKey;size:64"`
+		Role        string `gorm:"size:32;not null"`
+		Deactivated bool   `gorm:"not null;default:false"`
+		UpdatedAt   time.Time
+	}
+
+	// Store keeps the roles, the permissions and the accounts with gorm.
+	Store struct {
+		db *gorm.DB
+	}
+)
+
+// NewStore returns a Store on db, the default roles are created if missing.
+// The tables are created by the migrations of the model package.
+func NewStore(db *gorm.DB) (*Store, error) {
+	for role, permissions := range defaultPermissions {
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Role{Name: role}).Error; err != nil {
+			return nil, err
+		}
+		for _, permission := range permissions {
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&RolePermission{Role: role, Permission: permission}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Account returns the account of username, users without account row are active users.
+func (s *Store) Account(username string) (Account, error) {
+	account := Account{Username: username}
+	err := s.db.Where("username = ?", username).Take(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Account{Username: username, Role: RoleUser}, nil
+	}
+
+	return account, err
+}
+
+// Accounts returns the accounts of usernames, keyed by username.
+func (s *Store) Accounts(usernames []string) (map[string]Account, error) {
+	var accounts []Account
+	if len(usernames) > 0 {
+		if err := s.db.Where("username IN ?", usernames).Find(&accounts).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	resp := make(map[string]Account, len(usernames))
+	for _, username := range usernames {
+		resp[username] = Account{Username: username, Role: RoleUser}
+	}
+	for _, account := range accounts {
+		resp[account.Username] = account
+	}
+
+	return resp, nil
+}
+
+// SetRole changes the role of username.
+func (s *Store) SetRole(username, role string) error {
+	var count int64
+	if err := s.db.Model(&Role{}).Where("name = ?", role).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUnknownRole
+	}
+
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(&Account{Username: username, Role: role}).Error
+}
+
+// SetDeactivated deactivates or reactivates username.
+func (s *Store) SetDeactivated(username string, deactivated bool) error {
+	account, err := s.Account(username)
+	if err != nil {
+		return err
+	}
+
+	account.Deactivated = deactivated
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"deactivated", "updated_at"}),
+	}).Create(&account).Error
+}
+
+// Grant grants permission to role, the role is created if missing.
+func (s *Store) Grant(role, permission string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&Role{Name: role}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&RolePermission{Role: role, Permission: permission}).Error
+	})
+}
+
+// Revoke takes permission back from role.
+func (s *Store) Revoke(role, permission string) error {
+	return s.db.Where("role = ? AND permission = ?", role, permission).Delete(&RolePermission{}).Error
+}
+
+// Allowed tells whether username has permission, deactivated users have none.
+func (s *Store) Allowed(username, permission string) (bool, error) {
+	account, err := s.Account(username)
+	if err != nil || account.Deactivated {
+		return false, err
+	}
+
+	var count int64
+	err = s.db.Model(&RolePermission{}).
+		Where("role = ? AND permission = ?", account.Role, permission).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,168 @@
+package rbac
+
+import (
+	"testing"
+
+	"go-zero-api/service/internal/rbac/rbactest"
+)
+
+func newTestStore(t *testing.T) *Store {
+	store, err := NewStore(rbactest.NewDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func assertAllowed(t *testing.T, store *Store, username, permission string, expect bool) {
+	t.Helper()
+
+	allowed, err := store.Allowed(username, permission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allowed != expect {
+		t.Fatalf("Allowed(%q, %q) = %v, want %v", username, permission, allowed, expect)
+	}
+}
+
+func TestDefaultRoles(t *testing.T) {
+	store := newTestStore(t)
+
+	account, err := store.Account("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Role != RoleUser || account.Deactivated {
+		t.Fatalf("unexpected account without row: %+v", account)
+	}
+
+	for _, permission := range []string{PermReadUsers, PermListUsers, PermChangeRole, PermDeactivate} {
+		assertAllowed(t, store, "alice", permission, false)
+	}
+
+	if err := store.SetRole("root", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	for _, permission := range []string{PermReadUsers, PermListUsers, PermChangeRole, PermDeactivate} {
+		assertAllowed(t, store, "root", permission, true)
+	}
+	assertAllowed(t, store, "root", "users:unknown", false)
+}
+
+func TestNewStoreIsIdempotent(t *testing.T) {
+	store := newTestStore(t)
+	if _, err := NewStore(store.db); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	if err := store.db.Model(&RolePermission{}).Where("role = ?", RoleAdmin).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != int64(len(defaultPermissions[RoleAdmin])) {
+		t.Fatalf("expect %d admin permissions, got %d", len(defaultPermissions[RoleAdmin]), count)
+	}
+}
+
+func TestSetRole(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.SetRole("alice", "owner"); err != ErrUnknownRole {
+		t.Fatalf("expect ErrUnknownRole, got %v", err)
+	}
+
+	if err := store.SetRole("alice", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(t, store, "alice", PermListUsers, true)
+
+	if err := store.SetRole("alice", RoleUser); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(t, store, "alice", PermListUsers, false)
+}
+
+func TestGrantAndRevoke(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Grant("support", PermReadUsers); err != nil {
+		t.Fatal(err)
+	}
+	// granting twice is fine
+	if err := store.Grant("support", PermReadUsers); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetRole("bob", "support"); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(t, store, "bob", PermReadUsers, true)
+	assertAllowed(t, store, "bob", PermListUsers, false)
+
+	if err := store.Revoke("support", PermReadUsers); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(t, store, "bob", PermReadUsers, false)
+}
+
+func TestDeactivated(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.SetRole("root", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetDeactivated("root", true); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(t, store, "root", PermListUsers, false)
+
+	account, err := store.Account("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Role != RoleAdmin || !account.Deactivated {
+		t.Fatalf("unexpected deactivated account: %+v", account)
+	}
+
+	if err := store.SetDeactivated("root", false); err != nil {
+		t.Fatal(err)
+	}
+	assertAllowed(t, store, "root", PermListUsers, true)
+
+	// users without row can be deactivated too
+	if err := store.SetDeactivated("carol", true); err != nil {
+		t.Fatal(err)
+	}
+	account, err = store.Account("carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Role != RoleUser || !account.Deactivated {
+		t.Fatalf("unexpected deactivated account: %+v", account)
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.SetRole("root", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := store.Accounts([]string{"root", "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts["root"].Role != RoleAdmin || accounts["alice"].Role != RoleUser {
+		t.Fatalf("unexpected accounts: %+v", accounts)
+	}
+
+	accounts, err = store.Accounts(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 0 {
+		t.Fatalf("expect no accounts, got %+v", accounts)
+	}
+}
@@ -0,0 +1,38 @@
+// Package rbactest provides the database fixture of the rbac tests.
+package rbactest
+
+import (
+	"fmt"
+	"testing"
+
+	"go-zero-api/common/database"
+	"go-zero-api/service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewDB returns a migrated in-memory database of the user-api, every test gets its own.
+// It returns the database rather than a store, so that the tests of the rbac package can use it.
+func NewDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := database.Open("sqlite://"+dsn, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+
+	if err := model.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
@@ -0,0 +1,69 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+type (
+	// GatewaySender posts the messages to a sms gateway as json, the token of the gateway is
+	// sent as a bearer token. The messages carry the secrets, so they are never logged or kept.
+	GatewaySender struct {
+		url    string
+		token  string
+		client *http.Client
+	}
+
+	gatewayMessage struct {
+		PhoneNumber string `json:"phoneNumber"`
+		Message     string `json:"message"`
+	}
+)
+
+// NewGatewaySender returns a GatewaySender that posts to url and gives up after timeout.
+func NewGatewaySender(url, token string, timeout time.Duration) *GatewaySender {
+	return &GatewaySender{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *GatewaySender) Send(ctx context.Context, phoneNumber, message string) error {
+	body, err := json.Marshal(gatewayMessage{
+		PhoneNumber: phoneNumber,
+		Message:     message,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain a bit of the body, so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway answered %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGatewaySender(t *testing.T) {
+	var got gatewayMessage
+	var auth string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode the message: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := NewGatewaySender(server.URL, "gateway-token", time.Second)
+	if err := s.Send(context.Background(), "13800000000", "code 123456"); err != nil {
+		t.Fatal(err)
+	}
+	if got.PhoneNumber != "13800000000" || got.Message != "code 123456" || auth != "Bearer gateway-token" {
+		t.Fatalf("unexpected request: %+v, %q", got, auth)
+	}
+
+	status = http.StatusBadGateway
+	if err := s.Send(context.Background(), "13800000000", "code 123456"); err == nil {
+		t.Fatal("expect an error for a failed delivery")
+	}
+}
@@ -0,0 +1,28 @@
+package sender
+
+import (
+	"context"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type (
+	// Sender sends text messages to phone numbers.
+	Sender interface {
+		Send(ctx context.Context, phoneNumber, message string) error
+	}
+
+	// LogSender is a Sender for local runs, it logs the messages instead of sending them.
+	// The messages carry the codes and the reset tokens, so it must not be used in production.
+	LogSender struct{}
+)
+
+// NewLogSender returns a LogSender.
+func NewLogSender() LogSender {
+	return LogSender{}
+}
+
+func (LogSender) Send(ctx context.Context, phoneNumber, message string) error {
+	logx.WithContext(ctx).Infof("sms to %s: %s", phoneNumber, message)
+	return nil
+}
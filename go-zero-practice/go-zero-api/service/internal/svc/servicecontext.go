@@ -1,15 +1,87 @@
 package svc
 
 import (
+	"fmt"
+	"net"
+	"time"
+
 	"go-zero-api/service/internal/config"
+	"go-zero-api/service/internal/middleware"
+	"go-zero-api/service/internal/model"
+	"go-zero-api/service/internal/rbac"
+	"go-zero-api/service/internal/sender"
+
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/rest"
+	"gorm.io/gorm"
 )
 
 type ServiceContext struct {
 	Config config.Config
+	// TrustedProxies are the parsed Config.TrustedProxies
+	TrustedProxies []*net.IPNet
+	DB             *gorm.DB
+	UserModel      model.UserModel
+	// TokenModel keeps the phone verification codes and the password reset tokens
+	TokenModel model.OneTimeTokenModel
+	LoginModel model.LoginModel
+	// Sender delivers the codes and the tokens
+	Sender sender.Sender
+	Rbac   *rbac.Store
+	// Session rejects the revoked access tokens, it runs before Authorize
+	Session   rest.Middleware
+	Authorize rest.Middleware
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	// an empty secret would accept the tokens anyone can sign
+	if len(c.Auth.AccessSecret) == 0 || len(c.Auth.RefreshSecret) == 0 {
+		panic("Auth.AccessSecret and Auth.RefreshSecret must be set, see ACCESS_SECRET and REFRESH_SECRET")
+	}
+	if c.Auth.AccessSecret == c.Auth.RefreshSecret {
+		panic("Auth.RefreshSecret must differ from Auth.AccessSecret")
+	}
+
+	var proxies []*net.IPNet
+	for _, cidr := range c.TrustedProxies {
+		_, proxy, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("TrustedProxies: %v", err))
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	db := model.MustOpen(c.Database.DataSource)
+	users := model.NewUserModel(db)
+	store, err := rbac.NewStore(db)
+	if err != nil {
+		panic(err)
+	}
+
 	return &ServiceContext{
-		Config: c,
+		Config:         c,
+		TrustedProxies: proxies,
+		DB:             db,
+		UserModel:      users,
+		TokenModel:     model.NewOneTimeTokenModel(db),
+		LoginModel:     model.NewLoginModel(db),
+		Sender:         newSender(c),
+		Rbac:           store,
+		Session:        middleware.NewSessionMiddleware(users, store).Handle,
+		Authorize:      middleware.NewAuthorizeMiddleware(store, middleware.Permissions).Handle,
+	}
+}
+
+// newSender returns the sender of the config, there is no default,
+// a forgotten gateway must not end up logging the tokens.
+func newSender(c config.Config) sender.Sender {
+	switch {
+	case len(c.Sender.Gateway) > 0:
+		return sender.NewGatewaySender(c.Sender.Gateway, c.Sender.Token, time.Duration(c.Sender.Timeout)*time.Second)
+	case c.Sender.Log:
+		logx.Error("Sender.Log writes the phone codes and the reset tokens to the log, use it for local runs only")
+		return sender.NewLogSender()
+	default:
+		panic("Sender.Gateway must be set, see SMS_GATEWAY, or Sender.Log for local runs")
 	}
 }
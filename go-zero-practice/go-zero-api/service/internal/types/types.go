@@ -2,13 +2,25 @@
 package types
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	UserAgent string `header:"User-Agent,optional"`
 }
 
 type LoginResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	Status       string `json:"status"`
+	Message      string `json:"message"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	AccessExpire int64  `json:"accessExpire,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type GetUserRequest struct {
+	UserId string `path:"userId"`
 }
 
 type RegisterRequest struct {
@@ -17,6 +29,82 @@ type RegisterRequest struct {
 	Phonenumber string `json:"phonenumber"`
 }
 
+type UserInfo struct {
+	Username    string `json:"username"`
+	Phonenumber string `json:"phonenumber"`
+	Role        string `json:"role"`
+	Deactivated bool   `json:"deactivated"`
+}
+
+type UserResponse struct {
+	Id            int64  `json:"id"`
+	Username      string `json:"username"`
+	Phonenumber   string `json:"phonenumber"`
+	PhoneVerified bool   `json:"phoneVerified"`
+	Role          string `json:"role"`
+	Deactivated   bool   `json:"deactivated"`
+	CreateTime    int64  `json:"createTime"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+type ListUsersRequest struct {
+	Page     int64 `form:"page,default=1,range=[1:]"`
+	PageSize int64 `form:"pageSize,default=20,range=[1:100]"`
+}
+
+type ListUsersResponse struct {
+	Total int64      `json:"total"`
+	Users []UserInfo `json:"users"`
+}
+
+type ChangeRoleRequest struct {
+	UserId string `path:"userId"`
+	Role   string `json:"role"`
+}
+
+type DeactivateRequest struct {
+	UserId string `path:"userId"`
+}
+
+type ActionResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code"`
+}
+
+type ForgotPasswordRequest struct {
+	Username string `json:"username"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type LoginAttempt struct {
+	Ip         string `json:"ip"`
+	UserAgent  string `json:"userAgent"`
+	Success    bool   `json:"success"`
+	Reason     string `json:"reason"`
+	CreateTime int64  `json:"createTime"`
+}
+
+type ListLoginsRequest struct {
+	UserId string `path:"userId"`
+	Limit  int    `form:"limit,default=50,range=[1:500]"`
+}
+
+type ListLoginsResponse struct {
+	Logins []LoginAttempt `json:"logins"`
+}
+
+type UnlockRequest struct {
+	UserId string `path:"userId"`
+}
+
 type RegisterResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
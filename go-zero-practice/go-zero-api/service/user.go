@@ -1,30 +1,53 @@
 package main
 
 import (
+	_ "embed"
 	"flag"
 	"fmt"
+	"os"
 
+	"go-zero-api/common/apidoc"
+	"go-zero-api/common/errorx"
 	"go-zero-api/service/internal/config"
 	"go-zero-api/service/internal/handler"
+	"go-zero-api/service/internal/logic"
 	"go-zero-api/service/internal/svc"
 
 	"github.com/tal-tech/go-zero/core/conf"
 	"github.com/tal-tech/go-zero/rest"
+	"github.com/tal-tech/go-zero/rest/httpx"
 )
 
-var configFile = flag.String("f", "etc/user-api.yaml", "the config file")
+var (
+	configFile = flag.String("f", "etc/user-api.yaml", "the config file")
+	grantAdmin = flag.String("grant-admin", "", "grant the admin role to a registered user and exit")
+)
+
+//go:embed user.api
+var api []byte
 
 func main() {
 	flag.Parse()
 
 	var c config.Config
-	conf.MustLoad(*configFile, &c)
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
 
 	ctx := svc.NewServiceContext(c)
+	if len(*grantAdmin) > 0 {
+		if err := logic.GrantAdmin(ctx, *grantAdmin); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s is an admin now\n", *grantAdmin)
+		return
+	}
+
 	server := rest.MustNewServer(c.RestConf)
 	defer server.Stop()
 
 	handler.RegisterHandlers(server, ctx)
+	apidoc.MustRegisterHandlers(server, api)
+	httpx.SetErrorHandler(errorx.Handler)
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"go-zero-api/common/apidoc"
+)
+
+var (
+	apiFile = flag.String("f", "", "the .api file")
+	outFile = flag.String("o", "", "the output file, the standard output if empty")
+)
+
+// apidoc writes the OpenAPI document of an .api file, like
+//
+//	go run ./tools/apidoc -f service/user.api -o user-api.json
+func main() {
+	flag.Parse()
+	if *apiFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	api, err := ioutil.ReadFile(*apiFile)
+	if err != nil {
+		fail(err)
+	}
+	doc, err := apidoc.Marshal(api)
+	if err != nil {
+		fail(fmt.Errorf("%s: %w", *apiFile, err))
+	}
+
+	if *outFile == "" {
+		fmt.Println(string(doc))
+		return
+	}
+	if err := ioutil.WriteFile(*outFile, append(doc, '\n'), 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
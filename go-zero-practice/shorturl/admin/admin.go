@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"shorturl/admin/internal/config"
+	"shorturl/admin/internal/transfer"
+	"shorturl/common/urlcheck"
+	"shorturl/rpc/transform/model"
+
+	"github.com/tal-tech/go-zero/core/conf"
+)
+
+const usage = `usage: admin [-f config] <command> [flags]
+
+commands:
+  export  writes all links as csv or ndjson
+  import  loads links from csv or ndjson
+`
+
+var configFile = flag.String("f", "etc/admin.yaml", "the config file")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var c config.Config
+	conf.MustLoad(*configFile, &c)
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "export":
+		err = runExport(c, args)
+	case "import":
+		err = runImport(c, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runExport(c config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", transfer.FormatCsv, "the file format, csv or ndjson")
+	output := fs.String("o", "-", "the output file, - for stdout")
+	fs.Parse(args)
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	w, err := transfer.NewWriter(*format, out)
+	if err != nil {
+		return err
+	}
+
+	stats, err := transfer.Export(openModel(c), w, c.BatchSize, func(stats transfer.Stats) {
+		fmt.Fprintf(os.Stderr, "exported %d\n", stats.Read)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "done, exported %d links\n", stats.Read)
+	return nil
+}
+
+func runImport(c config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", transfer.FormatCsv, "the file format, csv or ndjson")
+	input := fs.String("i", "-", "the input file, - for stdin")
+	conflict := fs.String("conflict", transfer.ConflictFail, "what to do with existing keys: skip, overwrite or fail")
+	dryRun := fs.Bool("dry-run", false, "check and count the links without writing them")
+	fs.Parse(args)
+
+	var in io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	r, err := transfer.NewReader(*format, in)
+	if err != nil {
+		return err
+	}
+
+	report := func(stats transfer.Stats) {
+		fmt.Fprintf(os.Stderr, "read %d, created %d, overwritten %d, skipped %d\n",
+			stats.Read, stats.Created, stats.Overwritten, stats.Skipped)
+	}
+	checker, err := urlcheck.NewChecker(c.Url)
+	if err != nil {
+		return err
+	}
+	im, err := transfer.NewImporter(openModel(c), checker, *conflict, c.BatchSize,
+		transfer.WithDryRun(*dryRun), transfer.WithProgress(report))
+	if err != nil {
+		return err
+	}
+
+	stats, err := im.Import(r)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprint(os.Stderr, "dry run, nothing written: ")
+	} else {
+		fmt.Fprint(os.Stderr, "done: ")
+	}
+	report(stats)
+	return nil
+}
+
+// openModel opens the store like the transform service, the local cache
+// publishes the changed keys to the running instances.
+func openModel(c config.Config) model.ShorturlModel {
+	return model.NewLocalCachedShorturlModel(model.MustOpen(c.Driver, c.DataSource, c.Cache), c.LocalCache)
+}
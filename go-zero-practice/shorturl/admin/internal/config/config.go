@@ -0,0 +1,21 @@
+package config
+
+import (
+	"shorturl/common/localcache"
+	"shorturl/common/urlcheck"
+
+	"github.com/tal-tech/go-zero/core/stores/cache"
+)
+
+// Config points the admin tool to the store of the transform service,
+// it should use the same values as transform.yaml.
+type Config struct {
+	Driver     string `json:",default=mysql,options=mysql|sqlite|postgres|bolt"`
+	DataSource string
+	Cache      cache.CacheConf `json:",optional"`
+	// LocalCache tells the transform instances to drop the changed links
+	LocalCache localcache.Conf
+	// Url checks the imported links like the transform service does
+	Url       urlcheck.Conf
+	BatchSize int `json:",default=500"`
+}
@@ -0,0 +1,270 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"shorturl/rpc/transform/model"
+)
+
+const (
+	FormatCsv    = "csv"
+	FormatNdjson = "ndjson"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrMissingColumn = errors.New("missing column")
+	ErrEmptyShorten  = errors.New("shorten is empty")
+	ErrEmptyUrl      = errors.New("url is empty")
+	ErrNegativeCount = errors.New("max_clicks and clicks must not be negative")
+
+	csvColumns = []string{"shorten", "url", "owner", "disabled", "rules", "password",
+		"max_clicks", "clicks", "create_time", "update_time"}
+)
+
+type (
+	// A Record is a link in the exported files. Password is the bcrypt hash,
+	// the clicks and the times are kept on import, missing times are set to now.
+	Record struct {
+		Shorten    string    `json:"shorten"`
+		Url        string    `json:"url"`
+		Owner      string    `json:"owner,omitempty"`
+		Disabled   bool      `json:"disabled,omitempty"`
+		Rules      string    `json:"rules,omitempty"`
+		Password   string    `json:"password,omitempty"`
+		MaxClicks  int64     `json:"max_clicks,omitempty"`
+		Clicks     int64     `json:"clicks,omitempty"`
+		CreateTime time.Time `json:"create_time"`
+		UpdateTime time.Time `json:"update_time"`
+	}
+
+	// A Reader reads the records of a file, it returns io.EOF at the end.
+	Reader interface {
+		Read() (*Record, error)
+	}
+
+	// A Writer writes records to a file, Flush must be called after the last record.
+	Writer interface {
+		Write(record *Record) error
+		Flush() error
+	}
+
+	csvReader struct {
+		reader  *csv.Reader
+		columns map[string]int
+	}
+
+	csvWriter struct {
+		writer *csv.Writer
+		header bool
+	}
+
+	ndjsonReader struct {
+		decoder *json.Decoder
+	}
+
+	ndjsonWriter struct {
+		encoder *json.Encoder
+	}
+)
+
+// NewReader returns a Reader of format on r.
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCsv:
+		return newCsvReader(r)
+	case FormatNdjson:
+		return &ndjsonReader{decoder: json.NewDecoder(r)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// NewWriter returns a Writer of format on w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCsv:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case FormatNdjson:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+func newRecord(item *model.Shorturl) *Record {
+	return &Record{
+		Shorten:    item.Shorten,
+		Url:        item.Url,
+		Owner:      item.Owner,
+		Disabled:   item.Disabled,
+		Rules:      item.Rules,
+		Password:   item.Password,
+		MaxClicks:  item.MaxClicks,
+		Clicks:     item.Clicks,
+		CreateTime: item.CreateTime,
+		UpdateTime: item.UpdateTime,
+	}
+}
+
+func (r *Record) shorturl() model.Shorturl {
+	return model.Shorturl{
+		Shorten:    r.Shorten,
+		Url:        r.Url,
+		Owner:      r.Owner,
+		Disabled:   r.Disabled,
+		Rules:      r.Rules,
+		Password:   r.Password,
+		MaxClicks:  r.MaxClicks,
+		Clicks:     r.Clicks,
+		CreateTime: r.CreateTime,
+		UpdateTime: r.UpdateTime,
+	}
+}
+
+func (r *Record) validate() error {
+	if len(r.Shorten) == 0 {
+		return ErrEmptyShorten
+	}
+	if len(r.Url) == 0 {
+		return ErrEmptyUrl
+	}
+	if r.MaxClicks < 0 || r.Clicks < 0 {
+		return ErrNegativeCount
+	}
+
+	return nil
+}
+
+// newCsvReader reads the header first, the other shorteners' files
+// only need the shorten and url columns.
+func newCsvReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"shorten", "url"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, name)
+		}
+	}
+
+	return &csvReader{
+		reader:  reader,
+		columns: columns,
+	}, nil
+}
+
+func (r *csvReader) Read() (*Record, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	record := &Record{
+		Shorten:  field("shorten"),
+		Url:      field("url"),
+		Owner:    field("owner"),
+		Rules:    field("rules"),
+		Password: field("password"),
+	}
+	if v := field("disabled"); len(v) > 0 {
+		if record.Disabled, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("disabled: %w", err)
+		}
+	}
+	if v := field("max_clicks"); len(v) > 0 {
+		if record.MaxClicks, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("max_clicks: %w", err)
+		}
+	}
+	if v := field("clicks"); len(v) > 0 {
+		if record.Clicks, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("clicks: %w", err)
+		}
+	}
+	if v := field("create_time"); len(v) > 0 {
+		if record.CreateTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("create_time: %w", err)
+		}
+	}
+	if v := field("update_time"); len(v) > 0 {
+		if record.UpdateTime, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("update_time: %w", err)
+		}
+	}
+
+	return record, nil
+}
+
+func (w *csvWriter) Write(record *Record) error {
+	if !w.header {
+		if err := w.writer.Write(csvColumns); err != nil {
+			return err
+		}
+		w.header = true
+	}
+
+	return w.writer.Write([]string{
+		record.Shorten,
+		record.Url,
+		record.Owner,
+		strconv.FormatBool(record.Disabled),
+		record.Rules,
+		record.Password,
+		strconv.FormatInt(record.MaxClicks, 10),
+		strconv.FormatInt(record.Clicks, 10),
+		record.CreateTime.UTC().Format(time.RFC3339),
+		record.UpdateTime.UTC().Format(time.RFC3339),
+	})
+}
+
+// Flush writes the header even without records, so that empty exports can be imported.
+func (w *csvWriter) Flush() error {
+	if !w.header {
+		if err := w.writer.Write(csvColumns); err != nil {
+			return err
+		}
+		w.header = true
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (r *ndjsonReader) Read() (*Record, error) {
+	var record Record
+	if err := r.decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (w *ndjsonWriter) Write(record *Record) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,291 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"shorturl/common/targeting"
+	"shorturl/common/urlcheck"
+	"shorturl/rpc/transform/model"
+)
+
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictFail      = "fail"
+)
+
+var (
+	ErrConflict        = errors.New("shorten key already exists")
+	ErrUnknownConflict = errors.New("unknown conflict policy")
+)
+
+type (
+	// Stats counts the records of an import or an export.
+	Stats struct {
+		Read        int
+		Created     int
+		Overwritten int
+		Skipped     int
+	}
+
+	// An Importer loads records into a ShorturlModel in batches.
+	Importer struct {
+		model    model.ShorturlModel
+		checker  *urlcheck.Checker
+		conflict string
+		dryRun   bool
+		batch    int
+		progress func(Stats)
+	}
+
+	// ImportOption customizes an Importer.
+	ImportOption func(im *Importer)
+
+	// pendingWrite is a link the import writes, it may replace an existing
+	// link and the earlier records of the same key in the file.
+	pendingWrite struct {
+		item    model.Shorturl
+		exists  bool
+		repeats int
+	}
+)
+
+// WithDryRun checks and counts the records without writing them.
+func WithDryRun(dryRun bool) ImportOption {
+	return func(im *Importer) {
+		im.dryRun = dryRun
+	}
+}
+
+// WithProgress calls fn after every batch.
+func WithProgress(fn func(Stats)) ImportOption {
+	return func(im *Importer) {
+		im.progress = fn
+	}
+}
+
+// NewImporter returns an Importer that checks the urls with checker
+// and handles existing keys with the conflict policy.
+func NewImporter(m model.ShorturlModel, checker *urlcheck.Checker, conflict string, batch int,
+	opts ...ImportOption) (*Importer, error) {
+	switch conflict {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownConflict, conflict)
+	}
+
+	im := &Importer{
+		model:    m,
+		checker:  checker,
+		conflict: conflict,
+		batch:    batch,
+		progress: func(Stats) {},
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+
+	return im, nil
+}
+
+// Import loads all records of r. The whole file is checked before anything
+// is written: every record must pass the checks of the transform service,
+// and with the fail policy no key may exist or repeat in the file. The links
+// are then written in batches with their clicks and times.
+func (im *Importer) Import(r Reader) (Stats, error) {
+	var stats Stats
+	var records []*Record
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("record %d: %w", stats.Read+1, err)
+		}
+
+		stats.Read++
+		if err := im.check(record); err != nil {
+			return stats, fmt.Errorf("record %d: %w", stats.Read, err)
+		}
+		records = append(records, record)
+	}
+
+	writes, skipped, err := im.plan(records)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Skipped = skipped
+	for len(writes) > 0 {
+		n := im.batch
+		if n > len(writes) {
+			n = len(writes)
+		}
+		if err := im.write(writes[:n], &stats); err != nil {
+			return stats, err
+		}
+		writes = writes[n:]
+	}
+
+	return stats, nil
+}
+
+// check runs the checks of Shorten and SetLinkRules on the record,
+// the url and the target urls are normalized the same way.
+func (im *Importer) check(record *Record) error {
+	if err := record.validate(); err != nil {
+		return err
+	}
+
+	url, err := im.checker.Normalize(record.Url)
+	if err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	record.Url = url
+
+	rules, err := targeting.Parse(record.Rules)
+	if err != nil {
+		return fmt.Errorf("rules: %w", err)
+	}
+	if rules != nil {
+		if err := rules.Validate(); err != nil {
+			return fmt.Errorf("rules: %w", err)
+		}
+		if err := rules.NormalizeUrls(im.checker.Normalize); err != nil {
+			return fmt.Errorf("rules: %w", err)
+		}
+	}
+	record.Rules = rules.String()
+
+	return nil
+}
+
+// plan decides what to do with every record, a key repeated in the file
+// conflicts with its earlier record. Nothing is written yet.
+func (im *Importer) plan(records []*Record) ([]*pendingWrite, int, error) {
+	existing, err := im.existing(records)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var writes []*pendingWrite
+	var skipped int
+	pending := make(map[string]*pendingWrite)
+	for _, record := range records {
+		write, repeated := pending[record.Shorten]
+		if !repeated && !existing[record.Shorten] {
+			write = &pendingWrite{item: record.shorturl()}
+			pending[record.Shorten] = write
+			writes = append(writes, write)
+			continue
+		}
+
+		switch im.conflict {
+		case ConflictSkip:
+			skipped++
+		case ConflictOverwrite:
+			if repeated {
+				write.repeats++
+			} else {
+				write = &pendingWrite{exists: true}
+				pending[record.Shorten] = write
+				writes = append(writes, write)
+			}
+			write.item = record.shorturl()
+		default:
+			return nil, 0, fmt.Errorf("%w: %s", ErrConflict, record.Shorten)
+		}
+	}
+
+	return writes, skipped, nil
+}
+
+// existing looks up the keys of records in the store in batches.
+func (im *Importer) existing(records []*Record) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	seen := make(map[string]bool)
+	var keys []string
+	lookup := func() error {
+		items, err := im.model.FindMany(keys)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			existing[item.Shorten] = true
+		}
+		keys = keys[:0]
+		return nil
+	}
+
+	for _, record := range records {
+		if seen[record.Shorten] {
+			continue
+		}
+		seen[record.Shorten] = true
+		keys = append(keys, record.Shorten)
+		if len(keys) >= im.batch {
+			if err := lookup(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(keys) > 0 {
+		if err := lookup(); err != nil {
+			return nil, err
+		}
+	}
+
+	return existing, nil
+}
+
+func (im *Importer) write(writes []*pendingWrite, stats *Stats) error {
+	if !im.dryRun {
+		items := make([]model.Shorturl, 0, len(writes))
+		for _, write := range writes {
+			items = append(items, write.item)
+		}
+		if err := im.model.Restore(items); err != nil {
+			return err
+		}
+	}
+
+	for _, write := range writes {
+		if write.exists {
+			stats.Overwritten++
+		} else {
+			stats.Created++
+		}
+		stats.Overwritten += write.repeats
+	}
+	im.progress(*stats)
+	return nil
+}
+
+// Export writes all links of m to w in key order, progress is called after every batch.
+func Export(m model.ShorturlModel, w Writer, batch int, progress func(Stats)) (Stats, error) {
+	var stats Stats
+	var last string
+	for {
+		items, err := m.FindAfter(last, int64(batch))
+		if err != nil {
+			return stats, err
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		for _, item := range items {
+			if err := w.Write(newRecord(item)); err != nil {
+				return stats, err
+			}
+		}
+		stats.Read += len(items)
+		last = items[len(items)-1].Shorten
+		progress(stats)
+	}
+
+	return stats, w.Flush()
+}
@@ -0,0 +1,284 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shorturl/common/targeting"
+	"shorturl/common/urlcheck"
+	"shorturl/rpc/transform/model"
+)
+
+var testChecker = urlcheck.MustNewChecker(urlcheck.Conf{ShortDomain: "https://s.test", MaxLength: 2048})
+
+func newModel(t *testing.T) *model.BoltShorturlModel {
+	m, err := model.NewBoltShorturlModel(filepath.Join(t.TempDir(), "shorturl.bolt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		m.Close()
+	})
+
+	return m
+}
+
+func TestExportImport(t *testing.T) {
+	for _, format := range []string{FormatCsv, FormatNdjson} {
+		t.Run(format, func(t *testing.T) {
+			src := newModel(t)
+			if _, err := src.InsertMany([]model.Shorturl{
+				{Shorten: "k1", Url: "https://go.dev/1", Owner: "u1", Rules: `{"targets":[]}`, Clicks: 7},
+				{Shorten: "k2", Url: "https://go.dev/2", Disabled: true, Password: "hash", MaxClicks: 3, Clicks: 2},
+				{Shorten: "k3", Url: "https://go.dev/3?q=a,b"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			w, err := NewWriter(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats, err := Export(src, w, 2, func(Stats) {})
+			if err != nil || stats.Read != 3 {
+				t.Fatalf("Export() = %+v, %v", stats, err)
+			}
+
+			dst := newModel(t)
+			r, err := NewReader(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			im, err := NewImporter(dst, testChecker, ConflictFail, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats, err = im.Import(r); err != nil || stats.Created != 3 {
+				t.Fatalf("Import() = %+v, %v", stats, err)
+			}
+
+			for _, key := range []string{"k1", "k2", "k3"} {
+				want, _ := src.FindOne(key)
+				got, err := dst.FindOne(key)
+				if err != nil {
+					t.Fatalf("FindOne(%s) error = %v", key, err)
+				}
+				if got.Url != want.Url || got.Owner != want.Owner || got.Disabled != want.Disabled ||
+					got.Rules != want.Rules || got.Password != want.Password || got.MaxClicks != want.MaxClicks ||
+					got.Clicks != want.Clicks || !got.CreateTime.Equal(want.CreateTime) || !got.UpdateTime.Equal(want.UpdateTime) {
+					t.Errorf("FindOne(%s) = %+v, want %+v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportConflicts(t *testing.T) {
+	const input = "shorten,url\nk1,https://go.dev/new\nk2,https://go.dev/2\nk2,https://go.dev/2b\n"
+
+	tests := []struct {
+		name     string
+		conflict string
+		dryRun   bool
+		stats    Stats
+		err      error
+		k1       string
+		k2       string
+	}{
+		{
+			name:     "skip",
+			conflict: ConflictSkip,
+			stats:    Stats{Read: 3, Created: 1, Skipped: 2},
+			k1:       "https://go.dev/old",
+			k2:       "https://go.dev/2",
+		},
+		{
+			name:     "overwrite",
+			conflict: ConflictOverwrite,
+			stats:    Stats{Read: 3, Created: 1, Overwritten: 2},
+			k1:       "https://go.dev/new",
+			k2:       "https://go.dev/2b",
+		},
+		{
+			name:     "fail",
+			conflict: ConflictFail,
+			stats:    Stats{Read: 3},
+			err:      ErrConflict,
+			k1:       "https://go.dev/old",
+		},
+		{
+			name:     "dry run",
+			conflict: ConflictOverwrite,
+			dryRun:   true,
+			stats:    Stats{Read: 3, Created: 1, Overwritten: 2},
+			k1:       "https://go.dev/old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModel(t)
+			if _, err := m.Insert(model.Shorturl{Shorten: "k1", Url: "https://go.dev/old"}); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(FormatCsv, strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var batches int
+			im, err := NewImporter(m, testChecker, tt.conflict, 10, WithDryRun(tt.dryRun), WithProgress(func(Stats) {
+				batches++
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stats, err := im.Import(r)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Import() error = %v, want %v", err, tt.err)
+			}
+			if stats != tt.stats {
+				t.Errorf("Import() = %+v, want %+v", stats, tt.stats)
+			}
+			if tt.err == nil && batches != 1 {
+				t.Errorf("progress called %d times, want 1", batches)
+			}
+
+			for key, want := range map[string]string{"k1": tt.k1, "k2": tt.k2} {
+				item, err := m.FindOne(key)
+				switch {
+				case len(want) == 0 && err != model.ErrNotFound:
+					t.Errorf("FindOne(%s) = %+v, %v, want not found", key, item, err)
+				case len(want) > 0 && (err != nil || item.Url != want):
+					t.Errorf("FindOne(%s) = %+v, %v, want %s", key, item, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportFailIsAtomic(t *testing.T) {
+	m := newModel(t)
+	if _, err := m.Insert(model.Shorturl{Shorten: "k3", Url: "https://go.dev/old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the conflict is in the last batch, the earlier batches must not be written either
+	const input = "shorten,url\nk1,https://go.dev/1\nk2,https://go.dev/2\nk3,https://go.dev/3\n"
+	r, _ := NewReader(FormatCsv, strings.NewReader(input))
+	im, _ := NewImporter(m, testChecker, ConflictFail, 1)
+	if _, err := im.Import(r); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Import() error = %v, want %v", err, ErrConflict)
+	}
+	for _, key := range []string{"k1", "k2"} {
+		if item, err := m.FindOne(key); err != model.ErrNotFound {
+			t.Errorf("FindOne(%s) = %+v, %v, want not found", key, item, err)
+		}
+	}
+
+	// a repeated key fails too
+	r, _ = NewReader(FormatCsv, strings.NewReader("shorten,url\nk1,https://go.dev/1\nk1,https://go.dev/1b\n"))
+	if _, err := im.Import(r); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Import() error = %v, want %v", err, ErrConflict)
+	}
+	if item, err := m.FindOne("k1"); err != model.ErrNotFound {
+		t.Errorf("FindOne(k1) = %+v, %v, want not found", item, err)
+	}
+}
+
+func TestImportOverwriteKeepsClicks(t *testing.T) {
+	m := newModel(t)
+	if _, err := m.Insert(model.Shorturl{Shorten: "k1", Url: "https://go.dev/old", Clicks: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	const input = "shorten,url,clicks,create_time\nk1,https://go.dev/new,9,2020-01-02T03:04:05Z\n"
+	r, _ := NewReader(FormatCsv, strings.NewReader(input))
+	im, _ := NewImporter(m, testChecker, ConflictOverwrite, 10)
+	if stats, err := im.Import(r); err != nil || stats.Overwritten != 1 {
+		t.Fatalf("Import() = %+v, %v", stats, err)
+	}
+
+	item, err := m.FindOne("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if item.Url != "https://go.dev/new" || item.Clicks != 9 || !item.CreateTime.Equal(created) || item.UpdateTime.IsZero() {
+		t.Errorf("FindOne() = %+v, want the imported clicks and create time", item)
+	}
+}
+
+func TestImportChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		err    error
+	}{
+		{name: "empty url", record: `{"shorten":"k1"}`, err: ErrEmptyUrl},
+		{name: "scheme", record: `{"shorten":"k1","url":"javascript:alert(1)"}`, err: urlcheck.ErrScheme},
+		{name: "self reference", record: `{"shorten":"k1","url":"https://s.test/abc"}`, err: urlcheck.ErrSelfReference},
+		{name: "negative clicks", record: `{"shorten":"k1","url":"https://go.dev/","clicks":-1}`, err: ErrNegativeCount},
+		{name: "unknown target", record: `{"shorten":"k1","url":"https://go.dev/","rules":"{\"rules\":[{\"target\":\"b\"}]}"}`,
+			err: targeting.ErrUnknownTarget},
+		{name: "target url", record: `{"shorten":"k1","url":"https://go.dev/","rules":"{\"targets\":[{\"name\":\"a\",\"url\":\"ftp://go.dev/\"}]}"}`,
+			err: urlcheck.ErrScheme},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModel(t)
+			// the valid record comes first, it must not be written either
+			r, _ := NewReader(FormatNdjson, strings.NewReader(`{"shorten":"k0","url":"https://go.dev/"}`+"\n"+tt.record))
+			im, _ := NewImporter(m, testChecker, ConflictFail, 1)
+			if _, err := im.Import(r); !errors.Is(err, tt.err) {
+				t.Errorf("Import() error = %v, want %v", err, tt.err)
+			}
+			if item, err := m.FindOne("k0"); err != model.ErrNotFound {
+				t.Errorf("FindOne(k0) = %+v, %v, want not found", item, err)
+			}
+		})
+	}
+}
+
+func TestImportNormalizes(t *testing.T) {
+	m := newModel(t)
+	const input = `{"shorten":"k1","url":" HTTPS://Go.dev ","rules":"{\"targets\":[{\"name\":\"a\",\"url\":\"https://Go.dev/a\"}]}"}`
+	r, _ := NewReader(FormatNdjson, strings.NewReader(input))
+	im, _ := NewImporter(m, testChecker, ConflictFail, 10)
+	if _, err := im.Import(r); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := m.FindOne("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantUrl, _ := testChecker.Normalize("HTTPS://Go.dev")
+	rules, _ := targeting.Parse(item.Rules)
+	wantTarget, _ := testChecker.Normalize("https://Go.dev/a")
+	if item.Url != wantUrl || rules == nil || rules.Targets[0].Url != wantTarget {
+		t.Errorf("FindOne() = %+v, want the normalized urls %s and %s", item, wantUrl, wantTarget)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	if _, err := NewReader(FormatCsv, strings.NewReader("key,url\n")); !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("NewReader() error = %v, want %v", err, ErrMissingColumn)
+	}
+	if _, err := NewReader("xml", strings.NewReader("")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewReader() error = %v, want %v", err, ErrUnknownFormat)
+	}
+
+	r, _ := NewReader(FormatNdjson, strings.NewReader(`{"shorten":"k1"}`))
+	im, _ := NewImporter(newModel(t), testChecker, ConflictFail, 10)
+	if _, err := im.Import(r); !errors.Is(err, ErrEmptyUrl) {
+		t.Errorf("Import() error = %v, want %v", err, ErrEmptyUrl)
+	}
+}
@@ -1,11 +1,46 @@
 package config
 
 import (
+	"shorturl/common/localcache"
+
+	"github.com/tal-tech/go-zero/core/stores/redis"
 	"github.com/tal-tech/go-zero/rest"
 	"github.com/tal-tech/go-zero/zrpc"
 )
 
-type Config struct {
-	rest.RestConf
-	Transform zrpc.RpcClientConf
-}
+type (
+	Config struct {
+		rest.RestConf
+		Transform zrpc.RpcClientConf
+		// ShortDomain is the prefix of the short urls encoded in qr codes, ending with the redirect route
+		ShortDomain string
+		RateLimit   RateLimitConf
+		LocalCache  localcache.Conf
+		// Auth checks the tokens of the link management routes, AccessSecret is ${ACCESS_SECRET} of the environment
+		Auth struct {
+			AccessSecret string
+			AccessExpire int64
+		}
+	}
+
+	RateLimitConf struct {
+		Store string          `json:",default=memory,options=memory|redis"`
+		Redis redis.RedisConf `json:",optional"`
+		// TrustProxy takes the client ip from the last X-Forwarded-For address, which is added by the proxy,
+		// only enable it behind a proxy that appends to X-Forwarded-For
+		TrustProxy bool `json:",optional"`
+		// ApiKeys are the X-Api-Key values that get their own quota instead of the ip's
+		ApiKeys []string `json:",optional"`
+		Shorten LimitConf
+		Expand  LimitConf
+	}
+
+	// LimitConf is the quota of a route group, a batch request takes one request per item,
+	// so a quota below the batch size rejects the full batches.
+	LimitConf struct {
+		// Period is the length of the limit window in seconds
+		Period   int `json:",default=60"`
+		IpQuota  int `json:",default=30"`
+		KeyQuota int `json:",default=600"`
+	}
+)
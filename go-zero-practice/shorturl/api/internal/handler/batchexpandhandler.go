@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"shorturl/api/internal/logic"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func BatchExpandHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.BatchExpandReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+		// httpx.Parse of this go-zero version doesn't fill the header fields
+		req.UserAgent = r.UserAgent()
+		req.AcceptLanguage = r.Header.Get("Accept-Language")
+
+		l := logic.NewBatchExpandLogic(r.Context(), ctx)
+		resp, err := l.BatchExpand(req)
+		if err != nil {
+			httpx.Error(w, err)
+		} else {
+			httpx.OkJson(w, resp)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"shorturl/api/internal/logic"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func CreateLinkHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateLinkReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := logic.NewCreateLinkLogic(r.Context(), ctx)
+		resp, err := l.CreateLink(req)
+		if err != nil {
+			httpx.Error(w, err)
+		} else {
+			httpx.OkJson(w, resp)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"shorturl/api/internal/logic"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+func DeleteLinkHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DeleteLinkReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := logic.NewDeleteLinkLogic(r.Context(), ctx)
+		err := l.DeleteLink(req)
+		if err != nil {
+			httpx.Error(w, err)
+		} else {
+			httpx.Ok(w)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	errorResponse struct {
+		Code    string           `json:"code"`
+		Message string           `json:"message"`
+		Fields  []fieldViolation `json:"fields,omitempty"`
+	}
+
+	fieldViolation struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+	}
+)
+
+var httpStatuses = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+// ErrorHandler translates the errors returned by the transform rpc into http responses.
+func ErrorHandler(err error) (int, interface{}) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusBadRequest, err
+	}
+
+	code, ok := httpStatuses[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+
+	resp := errorResponse{
+		Code:    st.Code().String(),
+		Message: st.Message(),
+	}
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				resp.Fields = append(resp.Fields, fieldViolation{
+					Field:       v.GetField(),
+					Description: v.GetDescription(),
+				})
+			}
+		}
+	}
+
+	return code, resp
+}
@@ -17,6 +17,11 @@ func ExpandHandler(ctx *svc.ServiceContext) http.HandlerFunc {
 			httpx.Error(w, err)
 			return
 		}
+		// httpx.Parse of this go-zero version doesn't fill the header fields
+		req.UserAgent = r.UserAgent()
+		req.AcceptLanguage = r.Header.Get("Accept-Language")
+		// the password is only taken from the header, query strings end up in access logs and browser history
+		req.Password = r.Header.Get(passwordHeader)
 
 		l := logic.NewExpandLogic(r.Context(), ctx)
 		resp, err := l.Expand(req)
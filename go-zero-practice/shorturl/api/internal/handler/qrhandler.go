@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+
+	"shorturl/api/internal/logic"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+)
+
+var qrContentTypes = map[string]string{
+	"png": "image/png",
+	"svg": "image/svg+xml",
+}
+
+func QrHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.QrReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		l := logic.NewQrLogic(r.Context(), ctx)
+		content, err := l.Qr(req)
+		if err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		sum := md5.Sum(content)
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		// the link may be disabled, deleted or protected later, so the code isn't kept in shared
+		// caches and clients revalidate it with the etag, which checks the link again
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", qrContentTypes[req.Format])
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	}
+}
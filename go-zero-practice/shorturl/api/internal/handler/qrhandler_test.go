@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shorturl/api/internal/config"
+	"shorturl/api/internal/svc"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+	"github.com/tal-tech/go-zero/rest/router"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type checkTransformer struct {
+	transformer.Transformer
+}
+
+func (checkTransformer) Expand(ctx context.Context, in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+	return &transformer.ExpandResp{}, nil
+}
+
+// toggleTransformer finds the link until it's disabled.
+type toggleTransformer struct {
+	transformer.Transformer
+	disabled bool
+}
+
+func (m *toggleTransformer) Expand(ctx context.Context, in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+	if m.disabled {
+		return nil, status.Error(codes.NotFound, "shorten key not found")
+	}
+
+	return &transformer.ExpandResp{}, nil
+}
+
+func TestQrHandlerCache(t *testing.T) {
+	httpx.SetErrorHandler(ErrorHandler)
+	links := &toggleTransformer{}
+	rt := router.NewRouter()
+	if err := rt.Handle(http.MethodGet, "/qr/:shorten", QrHandler(&svc.ServiceContext{
+		Config:      config.Config{ShortDomain: "https://s.test/"},
+		Transformer: links,
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/qr/abc123", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || len(etag) == 0 {
+		t.Fatalf("status = %d, ETag = %q", w.Code, etag)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "private, no-cache" {
+		t.Errorf("Cache-Control = %q, want private, no-cache", got)
+	}
+
+	revalidate := func() int {
+		r := httptest.NewRequest(http.MethodGet, "/qr/abc123", nil)
+		r.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := revalidate(); code != http.StatusNotModified {
+		t.Errorf("revalidation status = %d, want %d", code, http.StatusNotModified)
+	}
+
+	// the cached code isn't confirmed once the link is disabled
+	links.disabled = true
+	if code := revalidate(); code != http.StatusNotFound {
+		t.Errorf("revalidation of a disabled link status = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestQrHandlerParams(t *testing.T) {
+	rt := router.NewRouter()
+	if err := rt.Handle(http.MethodGet, "/qr/:shorten", QrHandler(&svc.ServiceContext{
+		Config:      config.Config{ShortDomain: "https://s.test/"},
+		Transformer: checkTransformer{},
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query       string
+		code        int
+		contentType string
+	}{
+		{query: "", code: http.StatusOK, contentType: "image/png"},
+		{query: "?size=64", code: http.StatusOK, contentType: "image/png"},
+		{query: "?size=2048&format=svg", code: http.StatusOK, contentType: "image/svg+xml"},
+		{query: "?size=63", code: http.StatusBadRequest},
+		{query: "?size=2049", code: http.StatusBadRequest},
+		{query: "?level=X", code: http.StatusBadRequest},
+		{query: "?margin=17", code: http.StatusBadRequest},
+		{query: "?format=gif", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/qr/abc123"+tt.query, nil))
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); tt.code == http.StatusOK && got != tt.contentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.contentType)
+			}
+		})
+	}
+}
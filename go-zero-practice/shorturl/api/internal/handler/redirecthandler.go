@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+
+	"shorturl/api/internal/logic"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/rest/httpx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// passwordHeader carries the password of a protected link on the json routes.
+const passwordHeader = "X-Link-Password"
+
+var unlockTemplate = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Protected link</title>
+</head>
+<body>
+<form method="post">
+<p>{{.}}</p>
+<input type="password" name="password" autofocus required>
+<button type="submit">Unlock</button>
+</form>
+</body>
+</html>
+`))
+
+func RedirectHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.RedirectReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+		// httpx.Parse of this go-zero version doesn't fill the header fields
+		req.UserAgent = r.UserAgent()
+		req.AcceptLanguage = r.Header.Get("Accept-Language")
+
+		l := logic.NewRedirectLogic(r.Context(), ctx)
+		url, err := l.Redirect(req)
+		writeRedirect(w, r, url, err, "This link is protected by a password.")
+	}
+}
+
+// writeRedirect redirects to url, or shows the unlock form with message if the link is protected by a password.
+func writeRedirect(w http.ResponseWriter, r *http.Request, url string, err error, message string) {
+	// the clicks and the variants are counted on every visit
+	w.Header().Set("Cache-Control", "no-store")
+
+	switch {
+	case err == nil:
+		http.Redirect(w, r, url, http.StatusFound)
+	case status.Code(err) == codes.Unauthenticated:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		if err := unlockTemplate.Execute(w, message); err != nil {
+			logx.WithContext(r.Context()).Error(err)
+		}
+	default:
+		httpx.Error(w, err)
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"shorturl/api/internal/svc"
+	"shorturl/common/localcache"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+	"github.com/tal-tech/go-zero/rest/router"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	httpx.SetErrorHandler(ErrorHandler)
+}
+
+// passwordTransformer resolves every key to a link protected by the password "right".
+type passwordTransformer struct {
+	transformer.Transformer
+}
+
+func (passwordTransformer) Expand(ctx context.Context, in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+	switch in.Password {
+	case "":
+		return nil, status.Error(codes.Unauthenticated, "password required")
+	case "right":
+		return &transformer.ExpandResp{Url: "https://go.dev/"}, nil
+	default:
+		return nil, status.Error(codes.Unauthenticated, "wrong password")
+	}
+}
+
+func TestPasswordIsNotTakenFromQuery(t *testing.T) {
+	svcCtx := &svc.ServiceContext{
+		ExpandCache: localcache.MustNew("expand", localcache.Conf{Limit: 10, Expire: 60, NotFoundExpire: 10}, func(error) bool { return false }),
+		Transformer: passwordTransformer{},
+	}
+	rt := router.NewRouter()
+	for _, route := range []struct {
+		method, path string
+		handler      http.HandlerFunc
+	}{
+		{http.MethodGet, "/expand", ExpandHandler(svcCtx)},
+		{http.MethodGet, "/r/:shorten", RedirectHandler(svcCtx)},
+		{http.MethodPost, "/r/:shorten", UnlockHandler(svcCtx)},
+	} {
+		if err := rt.Handle(route.method, route.path, route.handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	form := func(password string) *strings.Reader {
+		return strings.NewReader(url.Values{"password": {password}}.Encode())
+	}
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   *strings.Reader
+		header string
+		code   int
+	}{
+		{name: "expand with query", method: http.MethodGet, target: "/expand?shorten=abc&password=right", code: http.StatusUnauthorized},
+		{name: "expand with header", method: http.MethodGet, target: "/expand?shorten=abc", header: "right", code: http.StatusOK},
+		{name: "redirect with query", method: http.MethodGet, target: "/r/abc?password=right", code: http.StatusUnauthorized},
+		{name: "unlock with body", method: http.MethodPost, target: "/r/abc", body: form("right"), code: http.StatusFound},
+		{name: "unlock with wrong password", method: http.MethodPost, target: "/r/abc", body: form("wrong"), code: http.StatusUnauthorized},
+		{name: "unlock with query", method: http.MethodPost, target: "/r/abc?password=right", body: form("wrong"), code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r *http.Request
+			if tt.body != nil {
+				r = httptest.NewRequest(tt.method, tt.target, tt.body)
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest(tt.method, tt.target, nil)
+			}
+			if len(tt.header) > 0 {
+				r.Header.Set(passwordHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+		})
+	}
+}
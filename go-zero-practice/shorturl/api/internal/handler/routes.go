@@ -10,18 +10,90 @@ import (
 )
 
 func RegisterHandlers(engine *rest.Server, serverCtx *svc.ServiceContext) {
+	engine.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ShortenLimit},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/shorten",
+					Handler: ShortenHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/batch/shorten",
+					Handler: BatchShortenHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	engine.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ExpandLimit},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/expand",
+					Handler: ExpandHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/batch/expand",
+					Handler: BatchExpandHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/qr/:shorten",
+					Handler: QrHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/r/:shorten",
+					Handler: RedirectHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/r/:shorten",
+					Handler: UnlockHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
 	engine.AddRoutes(
 		[]rest.Route{
 			{
-				Method:  http.MethodGet,
-				Path:    "/shorten",
-				Handler: ShortenHandler(serverCtx),
+				Method:  http.MethodPost,
+				Path:    "/links",
+				Handler: CreateLinkHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodGet,
-				Path:    "/expand",
-				Handler: ExpandHandler(serverCtx),
+				Path:    "/links",
+				Handler: ListLinksHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/links/:shorten",
+				Handler: UpdateLinkHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/links/:shorten/status",
+				Handler: SetLinkStatusHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/links/:shorten/rules",
+				Handler: SetLinkRulesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/links/:shorten",
+				Handler: DeleteLinkHandler(serverCtx),
 			},
 		},
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
 	)
 }
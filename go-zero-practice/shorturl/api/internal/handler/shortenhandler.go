@@ -17,6 +17,8 @@ func ShortenHandler(ctx *svc.ServiceContext) http.HandlerFunc {
 			httpx.Error(w, err)
 			return
 		}
+		// the password is only taken from the header, query strings end up in access logs and browser history
+		req.Password = r.Header.Get(passwordHeader)
 
 		l := logic.NewShortenLogic(r.Context(), ctx)
 		resp, err := l.Shorten(req)
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"shorturl/api/internal/logic"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errPasswordInQuery = status.Error(codes.InvalidArgument, "password must be sent in the request body")
+
+func UnlockHandler(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// httpx.Parse merges the query into the form, a password in the query would end up in access logs
+		if _, ok := r.URL.Query()["password"]; ok {
+			httpx.Error(w, errPasswordInQuery)
+			return
+		}
+
+		var req types.UnlockReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+		// httpx.Parse of this go-zero version doesn't fill the header fields
+		req.UserAgent = r.UserAgent()
+		req.AcceptLanguage = r.Header.Get("Accept-Language")
+
+		l := logic.NewRedirectLogic(r.Context(), ctx)
+		url, err := l.Unlock(req)
+		writeRedirect(w, r, url, err, "Wrong password, please try again.")
+	}
+}
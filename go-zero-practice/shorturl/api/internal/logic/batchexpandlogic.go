@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type BatchExpandLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewBatchExpandLogic(ctx context.Context, svcCtx *svc.ServiceContext) BatchExpandLogic {
+	return BatchExpandLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *BatchExpandLogic) BatchExpand(req types.BatchExpandReq) (*types.BatchExpandResp, error) {
+	resp, err := l.svcCtx.Transformer.BatchExpand(l.ctx, &transformer.BatchExpandReq{
+		Shortens:       req.Shortens,
+		UserAgent:      req.UserAgent,
+		AcceptLanguage: req.AcceptLanguage,
+	})
+	if err != nil {
+		return &types.BatchExpandResp{}, err
+	}
+
+	results := make([]types.ExpandResult, 0, len(resp.Results))
+	for _, item := range resp.Results {
+		results = append(results, types.ExpandResult{
+			Shorten: item.Shorten,
+			Url:     item.Url,
+			Variant: item.Variant,
+			Error:   item.Error,
+		})
+	}
+
+	return &types.BatchExpandResp{
+		Results: results,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type BatchShortenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewBatchShortenLogic(ctx context.Context, svcCtx *svc.ServiceContext) BatchShortenLogic {
+	return BatchShortenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *BatchShortenLogic) BatchShorten(req types.BatchShortenReq) (*types.BatchShortenResp, error) {
+	resp, err := l.svcCtx.Transformer.BatchShorten(l.ctx, &transformer.BatchShortenReq{
+		Urls: req.Urls,
+	})
+	if err != nil {
+		return &types.BatchShortenResp{}, err
+	}
+
+	results := make([]types.ShortenResult, 0, len(resp.Results))
+	for _, item := range resp.Results {
+		results = append(results, types.ShortenResult{
+			Url:     item.Url,
+			Shorten: item.Shorten,
+			Error:   item.Error,
+		})
+	}
+
+	return &types.BatchShortenResp{
+		Results: results,
+	}, nil
+}
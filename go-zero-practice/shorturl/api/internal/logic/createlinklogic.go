@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type CreateLinkLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) CreateLinkLogic {
+	return CreateLinkLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateLinkLogic) CreateLink(req types.CreateLinkReq) (*types.Link, error) {
+	owner := ownerFromContext(l.ctx)
+	resp, err := l.svcCtx.Transformer.Shorten(l.ctx, &transformer.ShortenReq{
+		Url:       req.Url,
+		Owner:     owner,
+		Password:  req.Password,
+		MaxClicks: req.MaxClicks,
+	})
+	if err != nil {
+		return &types.Link{}, err
+	}
+
+	return &types.Link{
+		Shorten:   resp.Shorten,
+		Url:       resp.Url,
+		Protected: len(req.Password) > 0,
+		MaxClicks: req.MaxClicks,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type DeleteLinkLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) DeleteLinkLogic {
+	return DeleteLinkLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteLinkLogic) DeleteLink(req types.DeleteLinkReq) error {
+	_, err := l.svcCtx.Transformer.DeleteLink(l.ctx, &transformer.DeleteLinkReq{
+		Owner:   ownerFromContext(l.ctx),
+		Shorten: req.Shorten,
+	})
+
+	return err
+}
@@ -25,16 +25,44 @@ func NewExpandLogic(ctx context.Context, svcCtx *svc.ServiceContext) ExpandLogic
 }
 
 func (l *ExpandLogic) Expand(req types.ExpandReq) (*types.ExpandResp, error) {
-	// todo: add your logic here and delete this line
-
-	resp, err := l.svcCtx.Transformer.Expand(l.ctx, &transformer.ExpandReq{
-		Shorten: req.Shorten,
+	resp, err := expand(l.ctx, l.svcCtx, &transformer.ExpandReq{
+		Shorten:        req.Shorten,
+		Password:       req.Password,
+		UserAgent:      req.UserAgent,
+		AcceptLanguage: req.AcceptLanguage,
 	})
 	if err != nil {
 		return &types.ExpandResp{}, err
 	}
 
 	return &types.ExpandResp{
-		Url: resp.Url,
+		Url:     resp.Url,
+		Variant: resp.Variant,
 	}, nil
 }
+
+// expand resolves the short key through the local cache before calling the transform rpc.
+// The links with targeting rules depend on the client, only their existence is cached.
+// The requests with a password skip the cache, so a shared fetch never hands one
+// client's password check to another.
+func expand(ctx context.Context, svcCtx *svc.ServiceContext, in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+	if len(in.Password) > 0 {
+		return svcCtx.Transformer.Expand(ctx, in)
+	}
+
+	var fetched bool
+	val, err := svcCtx.ExpandCache.Take(in.Shorten, func() (interface{}, error) {
+		fetched = true
+		return svcCtx.Transformer.Expand(ctx, in)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := val.(*transformer.ExpandResp)
+	if resp.Cacheable || fetched {
+		return resp, nil
+	}
+
+	return svcCtx.Transformer.Expand(ctx, in)
+}
@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/common/localcache"
+	"shorturl/rpc/transform/transformer"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExpandPasswordsAreNotShared(t *testing.T) {
+	// both calls must reach the rpc, a shared fetch would leave the barrier waiting
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	svcCtx := &svc.ServiceContext{
+		ExpandCache: localcache.MustNew("expand", localcache.Conf{Limit: 10, Expire: 60, NotFoundExpire: 10}, func(error) bool { return false }),
+		Transformer: &fakeTransformer{expand: func(in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+			barrier.Done()
+			done := make(chan struct{})
+			go func() {
+				barrier.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Error("the calls with different passwords share one rpc")
+			}
+
+			if in.Password != "right" {
+				return nil, status.Error(codes.Unauthenticated, "wrong password")
+			}
+			return &transformer.ExpandResp{Url: "https://go.dev/"}, nil
+		}},
+	}
+
+	var wg sync.WaitGroup
+	results := make(map[string]error)
+	var lock sync.Mutex
+	for _, password := range []string{"wrong", "right"} {
+		wg.Add(1)
+		go func(password string) {
+			defer wg.Done()
+			l := NewExpandLogic(context.Background(), svcCtx)
+			_, err := l.Expand(types.ExpandReq{Shorten: "abc123", Password: password})
+			lock.Lock()
+			results[password] = err
+			lock.Unlock()
+		}(password)
+	}
+	wg.Wait()
+
+	if status.Code(results["wrong"]) != codes.Unauthenticated || results["right"] != nil {
+		t.Errorf("Expand() errors = %v", results)
+	}
+}
+
+func TestExpandSharesFetchesWithoutPassword(t *testing.T) {
+	var calls int
+	svcCtx := &svc.ServiceContext{
+		ExpandCache: localcache.MustNew("expand", localcache.Conf{Limit: 10, Expire: 60, NotFoundExpire: 10}, func(error) bool { return false }),
+		Transformer: &fakeTransformer{expand: func(in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+			calls++
+			return &transformer.ExpandResp{Url: "https://go.dev/", Cacheable: true}, nil
+		}},
+	}
+
+	for i := 0; i < 2; i++ {
+		l := NewExpandLogic(context.Background(), svcCtx)
+		if resp, err := l.Expand(types.ExpandReq{Shorten: "abc123"}); err != nil || resp.Url != "https://go.dev/" {
+			t.Fatalf("Expand() = %+v, %v", resp, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("rpc called %d times, want the cached url", calls)
+	}
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type ListLinksLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListLinksLogic(ctx context.Context, svcCtx *svc.ServiceContext) ListLinksLogic {
+	return ListLinksLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListLinksLogic) ListLinks(req types.ListLinksReq) (*types.ListLinksResp, error) {
+	resp, err := l.svcCtx.Transformer.ListLinks(l.ctx, &transformer.ListLinksReq{
+		Owner:    ownerFromContext(l.ctx),
+		Keyword:  req.Keyword,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		return &types.ListLinksResp{}, err
+	}
+
+	links := make([]types.Link, 0, len(resp.Links))
+	for _, link := range resp.Links {
+		links = append(links, toLink(link))
+	}
+
+	return &types.ListLinksResp{
+		Links: links,
+		Total: resp.Total,
+	}, nil
+}
@@ -0,0 +1,17 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/rpc/transform/transformer"
+)
+
+// fakeTransformer answers Expand with expand, the other calls are not used by the tests.
+type fakeTransformer struct {
+	transformer.Transformer
+	expand func(in *transformer.ExpandReq) (*transformer.ExpandResp, error)
+}
+
+func (f *fakeTransformer) Expand(ctx context.Context, in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+	return f.expand(in)
+}
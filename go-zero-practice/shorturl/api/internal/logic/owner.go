@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+)
+
+// ownerClaim is the jwt claim that carries the id of the current user.
+const ownerClaim = "userId"
+
+// ownerFromContext returns the id of the user that the jwt token was issued to.
+func ownerFromContext(ctx context.Context) string {
+	switch v := ctx.Value(ownerClaim).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+func toLink(link *transformer.Link) types.Link {
+	if link == nil {
+		return types.Link{}
+	}
+
+	resp := types.Link{
+		Shorten:    link.Shorten,
+		Url:        link.Url,
+		Disabled:   link.Disabled,
+		Protected:  link.Protected,
+		MaxClicks:  link.MaxClicks,
+		Clicks:     link.Clicks,
+		CreateTime: link.CreateTime,
+		UpdateTime: link.UpdateTime,
+	}
+	if len(link.Rules) > 0 {
+		var rules types.LinkRules
+		if err := json.Unmarshal([]byte(link.Rules), &rules); err == nil {
+			resp.Rules = &rules
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,129 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+var (
+	recoveryLevels = map[string]qrcode.RecoveryLevel{
+		"L": qrcode.Low,
+		"M": qrcode.Medium,
+		"Q": qrcode.High,
+		"H": qrcode.Highest,
+	}
+
+	errQrTooSmall = errors.New("size is too small for the qr code")
+)
+
+type QrLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewQrLogic(ctx context.Context, svcCtx *svc.ServiceContext) QrLogic {
+	return QrLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Qr renders the qr code of the short url in the requested format.
+func (l *QrLogic) Qr(req types.QrReq) ([]byte, error) {
+	// make sure we never print a qr code for a key that doesn't resolve,
+	// only check the key so that the qr code doesn't use up the clicks of the link
+	_, err := l.svcCtx.Transformer.Expand(l.ctx, &transformer.ExpandReq{
+		Shorten: req.Shorten,
+		Check:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shortUrl := strings.TrimRight(l.svcCtx.Config.ShortDomain, "/") + "/" + req.Shorten
+	code, err := qrcode.New(shortUrl, recoveryLevels[req.Level])
+	if err != nil {
+		return nil, err
+	}
+	// the quiet zone is drawn by ourselves with the requested margin
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	modules := len(bitmap) + 2*req.Margin
+	scale := req.Size / modules
+	if scale < 1 {
+		return nil, errQrTooSmall
+	}
+	// center the code if size is not a multiple of the module count
+	offset := (req.Size-modules*scale)/2 + req.Margin*scale
+
+	if req.Format == "svg" {
+		return renderSvg(bitmap, req.Size, scale, offset), nil
+	}
+
+	return renderPng(bitmap, req.Size, scale, offset)
+}
+
+func renderPng(bitmap [][]bool, size, scale, offset int) ([]byte, error) {
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(offset+x*scale+dx, offset+y*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderSvg(bitmap [][]bool, size, scale, offset int) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, size, size)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, size, size)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+
+			// merge the dark modules of a row into one rect
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv%dh-%dz", offset+start*scale, offset+y*scale,
+				(x-start)*scale, scale, (x-start)*scale)
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
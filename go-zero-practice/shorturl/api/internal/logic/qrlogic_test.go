@@ -0,0 +1,97 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"regexp"
+	"testing"
+
+	"shorturl/api/internal/config"
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newQrLogic(t *testing.T) QrLogic {
+	return NewQrLogic(context.Background(), &svc.ServiceContext{
+		Config: config.Config{ShortDomain: "https://s.test/"},
+		Transformer: &fakeTransformer{expand: func(in *transformer.ExpandReq) (*transformer.ExpandResp, error) {
+			if !in.Check {
+				t.Errorf("Expand() for a qr code must only check the key")
+			}
+			if in.Shorten == "missing" {
+				return nil, status.Error(codes.NotFound, "shorten key not found")
+			}
+			return &transformer.ExpandResp{}, nil
+		}},
+	})
+}
+
+func TestQrPng(t *testing.T) {
+	l := newQrLogic(t)
+
+	for _, level := range []string{"L", "M", "Q", "H"} {
+		t.Run(level, func(t *testing.T) {
+			content, err := l.Qr(types.QrReq{Shorten: "abc123", Size: 256, Level: level, Margin: 4, Format: "png"})
+			if err != nil {
+				t.Fatalf("Qr() error = %v", err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(content))
+			if err != nil {
+				t.Fatalf("png.Decode() error = %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+				t.Errorf("image size = %v, want 256x256", b)
+			}
+
+			bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := qrcode.NewQRCodeReader().Decode(bmp, nil)
+			if err != nil {
+				t.Fatalf("decode qr code error = %v", err)
+			}
+			if result.GetText() != "https://s.test/abc123" {
+				t.Errorf("decoded url = %s, want https://s.test/abc123", result.GetText())
+			}
+			if ec := result.GetResultMetadata()[gozxing.ResultMetadataType_ERROR_CORRECTION_LEVEL]; ec != level {
+				t.Errorf("error correction level = %v, want %s", ec, level)
+			}
+		})
+	}
+}
+
+func TestQrSvg(t *testing.T) {
+	l := newQrLogic(t)
+	content, err := l.Qr(types.QrReq{Shorten: "abc123", Size: 300, Level: "M", Margin: 0, Format: "svg"})
+	if err != nil {
+		t.Fatalf("Qr() error = %v", err)
+	}
+	if !regexp.MustCompile(`^<svg [^>]*width="300" height="300" viewBox="0 0 300 300"`).Match(content) {
+		t.Errorf("Qr() svg = %.120s", content)
+	}
+}
+
+func TestQrSize(t *testing.T) {
+	l := newQrLogic(t)
+
+	// the smallest size the api accepts still fits a code of the lowest level
+	if _, err := l.Qr(types.QrReq{Shorten: "abc123", Size: 64, Level: "L", Margin: 0, Format: "png"}); err != nil {
+		t.Errorf("Qr() at the smallest size error = %v", err)
+	}
+	// the widest margin leaves no room for the modules of a longer key
+	if _, err := l.Qr(types.QrReq{Shorten: "abc123abc123abc123", Size: 64, Level: "H", Margin: 16, Format: "png"}); err != errQrTooSmall {
+		t.Errorf("Qr() too small error = %v, want %v", err, errQrTooSmall)
+	}
+	if _, err := l.Qr(types.QrReq{Shorten: "missing", Size: 256, Level: "M", Format: "png"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Qr() missing key error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type RedirectLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRedirectLogic(ctx context.Context, svcCtx *svc.ServiceContext) RedirectLogic {
+	return RedirectLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Redirect returns the url that the client is redirected to.
+func (l *RedirectLogic) Redirect(req types.RedirectReq) (string, error) {
+	return l.redirect(&transformer.ExpandReq{
+		Shorten:        req.Shorten,
+		UserAgent:      req.UserAgent,
+		AcceptLanguage: req.AcceptLanguage,
+	})
+}
+
+// Unlock returns the url that the client is redirected to with the password of a protected link.
+func (l *RedirectLogic) Unlock(req types.UnlockReq) (string, error) {
+	return l.redirect(&transformer.ExpandReq{
+		Shorten:        req.Shorten,
+		Password:       req.Password,
+		UserAgent:      req.UserAgent,
+		AcceptLanguage: req.AcceptLanguage,
+	})
+}
+
+func (l *RedirectLogic) redirect(in *transformer.ExpandReq) (string, error) {
+	resp, err := expand(l.ctx, l.svcCtx, in)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Url, nil
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type SetLinkRulesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSetLinkRulesLogic(ctx context.Context, svcCtx *svc.ServiceContext) SetLinkRulesLogic {
+	return SetLinkRulesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SetLinkRulesLogic) SetLinkRules(req types.SetLinkRulesReq) (*types.Link, error) {
+	// no targets and no rules remove the targeting of the link
+	var rules string
+	if len(req.Targets) > 0 || len(req.Rules) > 0 {
+		content, err := json.Marshal(types.LinkRules{
+			Targets: req.Targets,
+			Rules:   req.Rules,
+		})
+		if err != nil {
+			return &types.Link{}, err
+		}
+		rules = string(content)
+	}
+
+	resp, err := l.svcCtx.Transformer.SetLinkRules(l.ctx, &transformer.SetLinkRulesReq{
+		Owner:   ownerFromContext(l.ctx),
+		Shorten: req.Shorten,
+		Rules:   rules,
+	})
+	if err != nil {
+		return &types.Link{}, err
+	}
+
+	link := toLink(resp.Link)
+	return &link, nil
+}
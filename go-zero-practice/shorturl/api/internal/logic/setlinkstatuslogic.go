@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type SetLinkStatusLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSetLinkStatusLogic(ctx context.Context, svcCtx *svc.ServiceContext) SetLinkStatusLogic {
+	return SetLinkStatusLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SetLinkStatusLogic) SetLinkStatus(req types.SetLinkStatusReq) (*types.Link, error) {
+	resp, err := l.svcCtx.Transformer.SetLinkDisabled(l.ctx, &transformer.SetLinkDisabledReq{
+		Owner:    ownerFromContext(l.ctx),
+		Shorten:  req.Shorten,
+		Disabled: req.Disabled,
+	})
+	if err != nil {
+		return &types.Link{}, err
+	}
+
+	link := toLink(resp.Link)
+	return &link, nil
+}
@@ -26,7 +26,9 @@ func NewShortenLogic(ctx context.Context, svcCtx *svc.ServiceContext) ShortenLog
 
 func (l *ShortenLogic) Shorten(req types.ShortenReq) (*types.ShortenResp, error) {
 	resp, err := l.svcCtx.Transformer.Shorten(l.ctx, &transformer.ShortenReq{
-		Url: req.Url,
+		Url:       req.Url,
+		Password:  req.Password,
+		MaxClicks: req.MaxClicks,
 	})
 	if err != nil {
 		return &types.ShortenResp{}, err
@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/api/internal/svc"
+	"shorturl/api/internal/types"
+	"shorturl/rpc/transform/transformer"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type UpdateLinkLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpdateLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) UpdateLinkLogic {
+	return UpdateLinkLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateLinkLogic) UpdateLink(req types.UpdateLinkReq) (*types.Link, error) {
+	resp, err := l.svcCtx.Transformer.UpdateLink(l.ctx, &transformer.UpdateLinkReq{
+		Owner:   ownerFromContext(l.ctx),
+		Shorten: req.Shorten,
+		Url:     req.Url,
+	})
+	if err != nil {
+		return &types.Link{}, err
+	}
+
+	link := toLink(resp.Link)
+	return &link, nil
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"shorturl/api/internal/config"
+	"shorturl/api/internal/ratelimit"
+
+	"github.com/tal-tech/go-zero/core/lang"
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/rest/httpx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	apiKeyHeader       = "X-Api-Key"
+	forwardedForHeader = "X-Forwarded-For"
+	// maxBatchBody is the largest body read to count the items of a batch, it fits the largest batch of the longest urls
+	maxBatchBody = 8 << 20
+)
+
+var (
+	errTooManyRequests = status.Error(codes.ResourceExhausted, "too many requests")
+	errBodyTooLarge    = status.Error(codes.InvalidArgument, "request body is too large")
+)
+
+// A RateLimitMiddleware limits the requests of every client ip, or of every
+// api key if the request carries a known one.
+type RateLimitMiddleware struct {
+	ipLimiter  ratelimit.Limiter
+	keyLimiter ratelimit.Limiter
+	apiKeys    map[string]lang.PlaceholderType
+	trustProxy bool
+}
+
+func NewRateLimitMiddleware(c config.RateLimitConf, ipLimiter, keyLimiter ratelimit.Limiter) *RateLimitMiddleware {
+	apiKeys := make(map[string]lang.PlaceholderType)
+	for _, key := range c.ApiKeys {
+		apiKeys[key] = lang.Placeholder
+	}
+
+	return &RateLimitMiddleware{
+		ipLimiter:  ipLimiter,
+		keyLimiter: keyLimiter,
+		apiKeys:    apiKeys,
+		trustProxy: c.TrustProxy,
+	}
+}
+
+func (m *RateLimitMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limiter, key := m.ipLimiter, "ip:"+m.clientIp(r)
+		if apiKey := r.Header.Get(apiKeyHeader); len(apiKey) > 0 {
+			if _, ok := m.apiKeys[apiKey]; ok {
+				limiter, key = m.keyLimiter, "key:"+apiKey
+			}
+		}
+
+		cost, err := requestCost(r)
+		if err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		allowed, retryAfter, err := limiter.Take(key, cost)
+		if err != nil {
+			// don't take the service down with the limit store
+			logx.WithContext(r.Context()).Errorf("rate limit of %s failed: %v", key, err)
+			next(w, r)
+			return
+		}
+
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			httpx.Error(w, errTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// clientIp returns the address the request comes from. Behind a trusted proxy it's the
+// last address of X-Forwarded-For, which is added by the proxy, the addresses before it are sent by the client.
+func (m *RateLimitMiddleware) clientIp(r *http.Request) string {
+	if m.trustProxy {
+		forwarded := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+		if ip := net.ParseIP(strings.TrimSpace(forwarded[len(forwarded)-1])); ip != nil {
+			return ip.String()
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
+
+// requestCost returns how many requests r takes from the quota, a batch takes one per item.
+// The body is read ahead and put back for the handler.
+func requestCost(r *http.Request) (int, error) {
+	if r.Body == nil || r.Body == http.NoBody || r.Method != http.MethodPost {
+		return 1, nil
+	}
+
+	// rest.RestConf.MaxBytes only checks Content-Length, so chunked bodies are bounded here
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBatchBody+1))
+	if err != nil {
+		return 0, err
+	}
+	if len(body) > maxBatchBody {
+		return 0, errBodyTooLarge
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var batch struct {
+		Urls     []json.RawMessage `json:"urls"`
+		Shortens []json.RawMessage `json:"shortens"`
+	}
+	// a malformed body is rejected by the handler
+	if json.Unmarshal(body, &batch) != nil {
+		return 1, nil
+	}
+	if cost := len(batch.Urls) + len(batch.Shortens); cost > 1 {
+		return cost, nil
+	}
+
+	return 1, nil
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shorturl/api/internal/config"
+	"shorturl/api/internal/ratelimit"
+
+	"github.com/tal-tech/go-zero/rest/httpx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	// the api maps the grpc codes the same way, see handler.ErrorHandler
+	httpx.SetErrorHandler(func(err error) (int, interface{}) {
+		if status.Code(err) == codes.ResourceExhausted {
+			return http.StatusTooManyRequests, err.Error()
+		}
+		return http.StatusInternalServerError, err.Error()
+	})
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	m := NewRateLimitMiddleware(config.RateLimitConf{
+		ApiKeys: []string{"known"},
+	}, ratelimit.NewMemoryLimiter(time.Minute, 1), ratelimit.NewMemoryLimiter(time.Minute, 2))
+	h := m.Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		addr   string
+		apiKey string
+		code   int
+	}{
+		{name: "ip", addr: "10.0.0.1:1234", code: http.StatusOK},
+		{name: "ip over quota", addr: "10.0.0.1:5678", code: http.StatusTooManyRequests},
+		{name: "unknown api key uses ip quota", addr: "10.0.0.1:1234", apiKey: "unknown", code: http.StatusTooManyRequests},
+		{name: "known api key", addr: "10.0.0.1:1234", apiKey: "known", code: http.StatusOK},
+		{name: "known api key again", addr: "10.0.0.2:1234", apiKey: "known", code: http.StatusOK},
+		{name: "api key over quota", addr: "10.0.0.3:1234", apiKey: "known", code: http.StatusTooManyRequests},
+		{name: "other ip", addr: "10.0.0.2:1234", code: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/shorten?url=https://go.dev", nil)
+			r.RemoteAddr = tt.addr
+			if len(tt.apiKey) > 0 {
+				r.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+				t.Errorf("Retry-After = %q, want 60", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareBatch(t *testing.T) {
+	m := NewRateLimitMiddleware(config.RateLimitConf{}, ratelimit.NewMemoryLimiter(time.Minute, 3), nil)
+	var body string
+	h := m.Handle(func(w http.ResponseWriter, r *http.Request) {
+		// the handler still reads the whole body
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "batch", body: `{"urls":["https://go.dev/1","https://go.dev/2"]}`, code: http.StatusOK},
+		{name: "batch over quota", body: `{"shortens":["a","b"]}`, code: http.StatusTooManyRequests},
+		{name: "single within quota", body: `{"urls":["https://go.dev/3"]}`, code: http.StatusOK},
+		{name: "malformed counts once", body: `{"urls":`, code: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/batch/shorten", strings.NewReader(tt.body))
+			r.RemoteAddr = "10.0.0.1:1234"
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.code == http.StatusOK && body != tt.body {
+				t.Errorf("handler body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareClientIp(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		forwarded  []string
+		want       string
+	}{
+		{name: "remote addr", forwarded: []string{"10.0.0.9"}, want: "10.0.0.1"},
+		{name: "proxy hop", trustProxy: true, forwarded: []string{"10.0.0.9"}, want: "10.0.0.9"},
+		{name: "spoofed entries", trustProxy: true, forwarded: []string{"1.1.1.1, 2.2.2.2", "10.0.0.9"}, want: "10.0.0.9"},
+		{name: "last hop of one header", trustProxy: true, forwarded: []string{"1.1.1.1, 10.0.0.9"}, want: "10.0.0.9"},
+		{name: "invalid hop", trustProxy: true, forwarded: []string{"10.0.0.9, unknown"}, want: "10.0.0.1"},
+		{name: "no header", trustProxy: true, want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRateLimitMiddleware(config.RateLimitConf{TrustProxy: tt.trustProxy}, nil, nil)
+			r := httptest.NewRequest(http.MethodGet, "/expand", nil)
+			r.RemoteAddr = "10.0.0.1:1234"
+			for _, value := range tt.forwarded {
+				r.Header.Add(forwardedForHeader, value)
+			}
+			if got := m.clientIp(r); got != tt.want {
+				t.Errorf("clientIp() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// A MemoryLimiter is a Limiter that keeps the counters in process,
+	// it works for single instance deployments and tests.
+	MemoryLimiter struct {
+		period    time.Duration
+		quota     int
+		lock      sync.Mutex
+		windows   map[string]*window
+		nextSweep time.Time
+		now       func() time.Time
+	}
+
+	window struct {
+		count int
+		reset time.Time
+	}
+)
+
+// NewMemoryLimiter returns a MemoryLimiter that allows quota requests per period.
+func NewMemoryLimiter(period time.Duration, quota int) *MemoryLimiter {
+	return &MemoryLimiter{
+		period:  period,
+		quota:   quota,
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+}
+
+// Take implements Limiter.
+func (l *MemoryLimiter) Take(key string, n int) (bool, time.Duration, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.reset) {
+		w = &window{reset: now.Add(l.period)}
+		l.windows[key] = w
+	}
+
+	if w.count+n > l.quota {
+		return false, w.reset.Sub(now), nil
+	}
+
+	w.count += n
+	return true, 0, nil
+}
+
+// sweep drops the expired windows once per period, so idle clients don't pile up.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+
+	for key, w := range l.windows {
+		if !now.Before(w.reset) {
+			delete(l.windows, key)
+		}
+	}
+	l.nextSweep = now.Add(l.period)
+}
@@ -0,0 +1,24 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	l := NewMemoryLimiter(time.Minute, 3)
+	l.now = func() time.Time {
+		return now
+	}
+
+	testLimiter(t, l, func(d time.Duration) {
+		now = now.Add(d)
+	})
+
+	now = now.Add(time.Minute)
+	l.Take("a", 1)
+	if _, ok := l.windows["b"]; ok {
+		t.Error("expired window of b is not swept")
+	}
+}
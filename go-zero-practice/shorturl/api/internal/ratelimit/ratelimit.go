@@ -0,0 +1,11 @@
+package ratelimit
+
+import "time"
+
+// A Limiter counts the requests of every key in fixed periods.
+type Limiter interface {
+	// Take consumes n requests from the quota of key. If the quota is
+	// exceeded, it returns false and how long to wait until the quota resets,
+	// nothing is consumed then.
+	Take(key string, n int) (bool, time.Duration, error)
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// testLimiter runs the same requests against every Limiter, l allows 3 requests per minute
+// and advance moves its clock forward.
+func testLimiter(t *testing.T, l Limiter, advance func(time.Duration)) {
+	tests := []struct {
+		name       string
+		key        string
+		n          int
+		elapsed    time.Duration
+		allowed    bool
+		retryAfter time.Duration
+	}{
+		{name: "first", key: "a", n: 1, allowed: true},
+		{name: "batch", key: "a", n: 2, elapsed: 10 * time.Second, allowed: true},
+		{name: "over quota", key: "a", n: 1, elapsed: 10 * time.Second, retryAfter: 40 * time.Second},
+		{name: "other key", key: "b", n: 2, allowed: true},
+		{name: "batch over quota", key: "b", n: 2, retryAfter: time.Minute},
+		{name: "batch within quota", key: "b", n: 1, allowed: true},
+		{name: "batch over the whole quota", key: "c", n: 4, retryAfter: time.Minute},
+		{name: "next period", key: "a", n: 3, elapsed: 40 * time.Second, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advance(tt.elapsed)
+			allowed, retryAfter, err := l.Take(tt.key, tt.n)
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+			if allowed != tt.allowed || retryAfter != tt.retryAfter {
+				t.Errorf("Take() = %v, %v, want %v, %v", allowed, retryAfter, tt.allowed, tt.retryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tal-tech/go-zero/core/stores/redis"
+)
+
+// takeScript adds n to the counter if it stays within the quota, the counter expires at the end of the period.
+// It returns 1 and 0 if allowed, otherwise 0 and the ttl of the counter.
+const takeScript = `local quota = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+if current + n > quota then
+    return {0, redis.call("TTL", KEYS[1])}
+end
+if redis.call("INCRBY", KEYS[1], n) == n then
+    redis.call("EXPIRE", KEYS[1], period)
+end
+return {1, 0}`
+
+// A RedisLimiter is a Limiter that shares the counters of all instances in redis.
+type RedisLimiter struct {
+	store     *redis.Redis
+	keyPrefix string
+	period    time.Duration
+	quota     int
+}
+
+// NewRedisLimiter returns a RedisLimiter that allows quota requests per period.
+func NewRedisLimiter(store *redis.Redis, keyPrefix string, period time.Duration, quota int) *RedisLimiter {
+	return &RedisLimiter{
+		store:     store,
+		keyPrefix: keyPrefix,
+		period:    period,
+		quota:     quota,
+	}
+}
+
+// Take implements Limiter.
+func (l *RedisLimiter) Take(key string, n int) (bool, time.Duration, error) {
+	resp, err := l.store.Eval(takeScript, []string{l.keyPrefix + key}, []string{
+		strconv.Itoa(l.quota),
+		strconv.Itoa(int(l.period / time.Second)),
+		strconv.Itoa(n),
+	})
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := resp.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit response: %v", resp)
+	}
+	allowed, _ := values[0].(int64)
+	ttl, _ := values[1].(int64)
+	if allowed == 1 {
+		return true, 0, nil
+	}
+
+	// fall back to a full period if the counter has no ttl, like when n alone exceeds the quota
+	if ttl <= 0 {
+		return false, l.period, nil
+	}
+
+	return false, time.Duration(ttl) * time.Second, nil
+}
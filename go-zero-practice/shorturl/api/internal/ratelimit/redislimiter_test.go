@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/tal-tech/go-zero/core/stores/redis"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	l := NewRedisLimiter(redis.NewRedis(mr.Addr(), redis.NodeType), "limit:", time.Minute, 3)
+	testLimiter(t, l, mr.FastForward)
+
+	if got := mr.TTL("limit:a"); got != time.Minute {
+		t.Errorf("ttl of the counter = %v, want %v", got, time.Minute)
+	}
+}
+
+func TestRedisLimiterError(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewRedisLimiter(redis.NewRedis(mr.Addr(), redis.NodeType), "limit:", time.Minute, 3)
+	mr.Close()
+
+	if _, _, err := l.Take("a", 1); err == nil {
+		t.Error("Take() with redis down expected error")
+	}
+}
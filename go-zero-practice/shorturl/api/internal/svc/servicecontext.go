@@ -1,20 +1,56 @@
 package svc
 
 import (
+	"time"
+
 	"shorturl/api/internal/config"
+	"shorturl/api/internal/middleware"
+	"shorturl/api/internal/ratelimit"
+	"shorturl/common/localcache"
 	"shorturl/rpc/transform/transformer"
 
+	"github.com/tal-tech/go-zero/rest"
 	"github.com/tal-tech/go-zero/zrpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ServiceContext struct {
-	Config      config.Config
-	Transformer transformer.Transformer
+	Config       config.Config
+	Transformer  transformer.Transformer
+	ExpandCache  *localcache.Cache
+	ShortenLimit rest.Middleware
+	ExpandLimit  rest.Middleware
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	// an empty secret would accept the tokens anyone can sign
+	if len(c.Auth.AccessSecret) == 0 {
+		panic("Auth.AccessSecret is empty, set ACCESS_SECRET")
+	}
+
 	return &ServiceContext{
-		Config:      c,
-		Transformer: transformer.NewTransformer(zrpc.MustNewClient(c.Transform)),
+		Config:       c,
+		Transformer:  transformer.NewTransformer(zrpc.MustNewClient(c.Transform)),
+		ExpandCache:  localcache.MustNew("expand", c.LocalCache, isNotFound),
+		ShortenLimit: newRateLimit(c.RateLimit, "shorten", c.RateLimit.Shorten),
+		ExpandLimit:  newRateLimit(c.RateLimit, "expand", c.RateLimit.Expand),
+	}
+}
+
+func isNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
+
+func newRateLimit(c config.RateLimitConf, name string, lc config.LimitConf) rest.Middleware {
+	period := time.Duration(lc.Period) * time.Second
+	newLimiter := func(kind string, quota int) ratelimit.Limiter {
+		if c.Store == "redis" {
+			return ratelimit.NewRedisLimiter(c.Redis.NewRedis(), "shorturl:limit:"+name+":"+kind+":", period, quota)
+		}
+
+		return ratelimit.NewMemoryLimiter(period, quota)
 	}
+
+	return middleware.NewRateLimitMiddleware(c, newLimiter("ip", lc.IpQuota), newLimiter("key", lc.KeyQuota)).Handle
 }
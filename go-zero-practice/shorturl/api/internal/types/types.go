@@ -2,17 +2,141 @@
 package types
 
 type ExpandReq struct {
-	Shorten string `form:"shorten"`
+	Shorten        string `form:"shorten"`
+	Password       string `header:"X-Link-Password,optional"`
+	UserAgent      string `header:"User-Agent,optional"`
+	AcceptLanguage string `header:"Accept-Language,optional"`
 }
 
 type ExpandResp struct {
-	Url string `json:"url"`
+	Url     string `json:"url"`
+	Variant string `json:"variant,omitempty"`
 }
 
 type ShortenReq struct {
-	Url string `form:"url"`
+	Url       string `form:"url"`
+	Password  string `header:"X-Link-Password,optional"`
+	MaxClicks int64  `form:"maxClicks,optional"`
 }
 
 type ShortenResp struct {
 	Shorten string `json:"shorten"`
 }
+
+type BatchShortenReq struct {
+	Urls []string `json:"urls"`
+}
+
+type ShortenResult struct {
+	Url     string `json:"url"`
+	Shorten string `json:"shorten,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BatchShortenResp struct {
+	Results []ShortenResult `json:"results"`
+}
+
+type BatchExpandReq struct {
+	Shortens       []string `json:"shortens"`
+	UserAgent      string   `header:"User-Agent,optional"`
+	AcceptLanguage string   `header:"Accept-Language,optional"`
+}
+
+type ExpandResult struct {
+	Shorten string `json:"shorten"`
+	Url     string `json:"url,omitempty"`
+	Variant string `json:"variant,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BatchExpandResp struct {
+	Results []ExpandResult `json:"results"`
+}
+
+type RedirectReq struct {
+	Shorten        string `path:"shorten"`
+	UserAgent      string `header:"User-Agent,optional"`
+	AcceptLanguage string `header:"Accept-Language,optional"`
+}
+
+type UnlockReq struct {
+	Shorten        string `path:"shorten"`
+	Password       string `form:"password"`
+	UserAgent      string `header:"User-Agent,optional"`
+	AcceptLanguage string `header:"Accept-Language,optional"`
+}
+
+type QrReq struct {
+	Shorten string `path:"shorten"`
+	Size    int    `form:"size,default=256,range=[64:2048]"`
+	Level   string `form:"level,default=M,options=L|M|Q|H"`
+	Margin  int    `form:"margin,default=4,range=[0:16]"`
+	Format  string `form:"format,default=png,options=png|svg"`
+}
+
+type Link struct {
+	Shorten    string     `json:"shorten"`
+	Url        string     `json:"url"`
+	Disabled   bool       `json:"disabled"`
+	Rules      *LinkRules `json:"rules,omitempty"`
+	Protected  bool       `json:"protected"`
+	MaxClicks  int64      `json:"maxClicks"`
+	Clicks     int64      `json:"clicks"`
+	CreateTime int64      `json:"createTime"`
+	UpdateTime int64      `json:"updateTime"`
+}
+
+type LinkTarget struct {
+	Name   string `json:"name"`
+	Url    string `json:"url,optional"`
+	Weight int    `json:"weight,optional"`
+}
+
+type LinkRule struct {
+	Languages []string `json:"languages,optional"`
+	Devices   []string `json:"devices,optional"`
+	Target    string   `json:"target"`
+}
+
+type LinkRules struct {
+	Targets []LinkTarget `json:"targets"`
+	Rules   []LinkRule   `json:"rules,optional"`
+}
+
+type CreateLinkReq struct {
+	Url       string `json:"url"`
+	Password  string `json:"password,optional"`
+	MaxClicks int64  `json:"maxClicks,optional"`
+}
+
+type ListLinksReq struct {
+	Page     int64  `form:"page,default=1"`
+	PageSize int64  `form:"pageSize,default=20"`
+	Keyword  string `form:"keyword,optional"`
+}
+
+type ListLinksResp struct {
+	Links []Link `json:"links"`
+	Total int64  `json:"total"`
+}
+
+type UpdateLinkReq struct {
+	Shorten string `path:"shorten"`
+	Url     string `json:"url"`
+}
+
+type SetLinkStatusReq struct {
+	Shorten  string `path:"shorten"`
+	Disabled bool   `json:"disabled"`
+}
+
+type SetLinkRulesReq struct {
+	Shorten string       `path:"shorten"`
+	Targets []LinkTarget `json:"targets,optional"`
+	Rules   []LinkRule   `json:"rules,optional"`
+}
+
+type DeleteLinkReq struct {
+	Shorten string `path:"shorten"`
+}
@@ -0,0 +1,128 @@
+package localcache
+
+import (
+	"strings"
+	"time"
+
+	red "github.com/go-redis/redis"
+	"github.com/tal-tech/go-zero/core/collection"
+	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/core/stores/redis"
+	"github.com/tal-tech/go-zero/core/threading"
+)
+
+const keySeparator = "\n"
+
+type (
+	// A Conf is the config of a Cache.
+	Conf struct {
+		Limit int `json:",default=10000"`
+		// Expire and NotFoundExpire are in seconds
+		Expire         int `json:",default=60"`
+		NotFoundExpire int `json:",default=10"`
+		// Redis is used to broadcast the invalidations to all instances,
+		// without it the entries are only dropped locally.
+		Redis   redis.RedisConf `json:",optional"`
+		Channel string          `json:",default=shorturl:invalidate"`
+	}
+
+	// A Cache is an in-process lru cache with expiry, it shares the fetches
+	// of the same key and remembers the keys that are not found.
+	Cache struct {
+		items      *collection.Cache
+		misses     *collection.Cache
+		isNotFound func(error) bool
+		client     *red.Client
+		channel    string
+	}
+)
+
+// New returns a Cache, isNotFound tells which fetch errors are cached as missing keys.
+func New(name string, c Conf, isNotFound func(error) bool) (*Cache, error) {
+	items, err := collection.NewCache(time.Duration(c.Expire)*time.Second,
+		collection.WithName(name), collection.WithLimit(c.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	misses, err := collection.NewCache(time.Duration(c.NotFoundExpire)*time.Second,
+		collection.WithName(name+"-notfound"), collection.WithLimit(c.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &Cache{
+		items:      items,
+		misses:     misses,
+		isNotFound: isNotFound,
+		channel:    c.Channel,
+	}
+	if len(c.Redis.Host) > 0 {
+		cache.client = red.NewClient(&red.Options{
+			Addr:     c.Redis.Host,
+			Password: c.Redis.Pass,
+		})
+		threading.GoSafe(cache.subscribe)
+	}
+
+	return cache, nil
+}
+
+// MustNew returns a Cache, exits on any error.
+func MustNew(name string, c Conf, isNotFound func(error) bool) *Cache {
+	cache, err := New(name, c, isNotFound)
+	if err != nil {
+		logx.Must(err)
+	}
+
+	return cache
+}
+
+// Take returns the cached value of key, or calls fetch to load it.
+// Concurrent calls on the same key share one fetch.
+func (c *Cache) Take(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if err, ok := c.misses.Get(key); ok {
+		return nil, err.(error)
+	}
+
+	return c.items.Take(key, func() (interface{}, error) {
+		val, err := fetch()
+		if err != nil && c.isNotFound(err) {
+			c.misses.Set(key, err)
+		}
+
+		return val, err
+	})
+}
+
+// Del drops the keys from the local cache only.
+func (c *Cache) Del(keys ...string) {
+	for _, key := range keys {
+		c.items.Del(key)
+		c.misses.Del(key)
+	}
+}
+
+// Invalidate drops the keys from the local cache and tells the other instances to drop them too.
+func (c *Cache) Invalidate(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	c.Del(keys...)
+	if c.client == nil {
+		return
+	}
+
+	if err := c.client.Publish(c.channel, strings.Join(keys, keySeparator)).Err(); err != nil {
+		logx.Errorf("publish invalidation of %v failed: %v", keys, err)
+	}
+}
+
+func (c *Cache) subscribe() {
+	// the channel of PubSub reconnects by itself
+	pubsub := c.client.Subscribe(c.channel)
+	for msg := range pubsub.Channel() {
+		c.Del(strings.Split(msg.Payload, keySeparator)...)
+	}
+}
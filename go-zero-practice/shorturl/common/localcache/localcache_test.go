@@ -0,0 +1,106 @@
+package localcache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errNotFound = errors.New("not found")
+
+func newTestCache(t *testing.T) *Cache {
+	cache, err := New("test", Conf{
+		Limit:          10,
+		Expire:         60,
+		NotFoundExpire: 60,
+	}, func(err error) bool {
+		return err == errNotFound
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cache
+}
+
+func TestCacheTakeShared(t *testing.T) {
+	cache := newTestCache(t)
+	var fetches int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := cache.Take("k", func() (interface{}, error) {
+				atomic.AddInt32(&fetches, 1)
+				time.Sleep(50 * time.Millisecond)
+				return "v", nil
+			})
+			if err != nil || val != "v" {
+				t.Errorf("Take() = %v, %v", val, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+}
+
+func TestCacheNotFound(t *testing.T) {
+	cache := newTestCache(t)
+	var fetches int
+	fetch := func() (interface{}, error) {
+		fetches++
+		return nil, errNotFound
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Take("missing", fetch); err != errNotFound {
+			t.Fatalf("Take() error = %v, want %v", err, errNotFound)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+
+	// other errors are not cached
+	errOther := errors.New("other")
+	for i := 0; i < 2; i++ {
+		cache.Take("broken", func() (interface{}, error) {
+			fetches++
+			return nil, errOther
+		})
+	}
+	if fetches != 3 {
+		t.Errorf("fetches = %d, want 3", fetches)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	cache := newTestCache(t)
+	cache.Take("k", func() (interface{}, error) {
+		return "old", nil
+	})
+	cache.Take("missing", func() (interface{}, error) {
+		return nil, errNotFound
+	})
+
+	cache.Invalidate("k", "missing")
+
+	val, err := cache.Take("k", func() (interface{}, error) {
+		return "new", nil
+	})
+	if err != nil || val != "new" {
+		t.Errorf("Take() after invalidate = %v, %v", val, err)
+	}
+	val, err = cache.Take("missing", func() (interface{}, error) {
+		return "created", nil
+	})
+	if err != nil || val != "created" {
+		t.Errorf("Take() of missing key after invalidate = %v, %v", val, err)
+	}
+}
@@ -0,0 +1,324 @@
+package targeting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultVariant is the name of the link's own url.
+	DefaultVariant = "default"
+
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+
+	maxTargets = 20
+	maxRules   = 50
+)
+
+var (
+	ErrTooManyTargets = errors.New("too many targets")
+	ErrTooManyRules   = errors.New("too many rules")
+	ErrTargetName     = errors.New("target name is empty or duplicated")
+	ErrTargetUrl      = errors.New("target url is missing")
+	ErrWeight         = errors.New("target weight must not be negative")
+	ErrUnknownTarget  = errors.New("rule refers to an unknown target")
+	ErrUnknownDevice  = errors.New("unknown device")
+
+	devices = map[string]bool{
+		DeviceMobile:  true,
+		DeviceTablet:  true,
+		DeviceDesktop: true,
+		DeviceBot:     true,
+	}
+	botMarks    = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit"}
+	mobileMarks = []string{"mobi", "iphone", "ipod", "android", "windows phone"}
+
+	// randIntn is replaced in tests.
+	randIntn = rand.Intn
+)
+
+type (
+	// Rules decide which target a client is redirected to.
+	// The rules are tried first, the weighted split of the targets is the fallback.
+	Rules struct {
+		Targets []Target `json:"targets"`
+		Rules   []Rule   `json:"rules,omitempty"`
+	}
+
+	// A Target is a variant of the link. The target named default keeps the link's
+	// own url and only takes part in the split with its weight.
+	Target struct {
+		Name   string `json:"name"`
+		Url    string `json:"url,omitempty"`
+		Weight int    `json:"weight,omitempty"`
+	}
+
+	// A Rule sends the clients matching any of the languages and any of the devices
+	// to Target, empty conditions match all clients.
+	Rule struct {
+		Languages []string `json:"languages,omitempty"`
+		Devices   []string `json:"devices,omitempty"`
+		Target    string   `json:"target"`
+	}
+
+	// A TargetUrlError tells which target url failed the checks of the links.
+	TargetUrlError struct {
+		Index int
+		Err   error
+	}
+
+	// A Client describes the request being redirected.
+	Client struct {
+		UserAgent      string
+		AcceptLanguage string
+	}
+)
+
+// Parse parses the json rules, empty s means no rules and returns nil.
+func Parse(s string) (*Rules, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil, nil
+	}
+
+	var rules Rules
+	if err := json.Unmarshal([]byte(s), &rules); err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// String returns the json form of r.
+func (r *Rules) String() string {
+	if r == nil {
+		return ""
+	}
+
+	content, _ := json.Marshal(r)
+	return string(content)
+}
+
+// Validate checks that the targets are complete and the rules refer to them.
+func (r *Rules) Validate() error {
+	if len(r.Targets) > maxTargets {
+		return ErrTooManyTargets
+	}
+	if len(r.Rules) > maxRules {
+		return ErrTooManyRules
+	}
+
+	names := map[string]bool{DefaultVariant: true}
+	for _, target := range r.Targets {
+		if target.Weight < 0 {
+			return fmt.Errorf("%w: %s", ErrWeight, target.Name)
+		}
+		if target.Name == DefaultVariant {
+			continue
+		}
+		if len(target.Name) == 0 || names[target.Name] {
+			return fmt.Errorf("%w: %q", ErrTargetName, target.Name)
+		}
+		if len(target.Url) == 0 {
+			return fmt.Errorf("%w: %s", ErrTargetUrl, target.Name)
+		}
+		names[target.Name] = true
+	}
+
+	for _, rule := range r.Rules {
+		if !names[rule.Target] {
+			return fmt.Errorf("%w: %s", ErrUnknownTarget, rule.Target)
+		}
+		for _, device := range rule.Devices {
+			if !devices[device] {
+				return fmt.Errorf("%w: %s", ErrUnknownDevice, device)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NormalizeUrls replaces the target urls with their normalized form, the targets
+// must pass the same checks as the links. It returns a *TargetUrlError on failure.
+func (r *Rules) NormalizeUrls(normalize func(string) (string, error)) error {
+	for i, target := range r.Targets {
+		if target.Name == DefaultVariant {
+			continue
+		}
+
+		url, err := normalize(target.Url)
+		if err != nil {
+			return &TargetUrlError{Index: i, Err: err}
+		}
+		r.Targets[i].Url = url
+	}
+
+	return nil
+}
+
+func (e *TargetUrlError) Error() string {
+	return fmt.Sprintf("targets[%d].url: %v", e.Index, e.Err)
+}
+
+func (e *TargetUrlError) Unwrap() error {
+	return e.Err
+}
+
+// Pick returns the variant and the url that client is redirected to,
+// defaultUrl is the url of the default variant.
+func (r *Rules) Pick(client Client, defaultUrl string) (string, string) {
+	if r == nil {
+		return DefaultVariant, defaultUrl
+	}
+
+	if name, ok := r.match(client); ok {
+		return r.target(name, defaultUrl)
+	}
+
+	var total int
+	for _, target := range r.Targets {
+		total += target.Weight
+	}
+	if total == 0 {
+		return DefaultVariant, defaultUrl
+	}
+
+	n := randIntn(total)
+	for _, target := range r.Targets {
+		if n < target.Weight {
+			return r.target(target.Name, defaultUrl)
+		}
+		n -= target.Weight
+	}
+
+	return DefaultVariant, defaultUrl
+}
+
+// match finds the rule for the client. The languages are tried in the client's
+// preference, the rules without languages come after all of them.
+func (r *Rules) match(client Client) (string, bool) {
+	device := Device(client.UserAgent)
+	for _, lang := range Languages(client.AcceptLanguage) {
+		for _, rule := range r.Rules {
+			if matchLanguage(rule.Languages, lang) && matchDevice(rule.Devices, device) {
+				return rule.Target, true
+			}
+		}
+	}
+
+	for _, rule := range r.Rules {
+		if len(rule.Languages) == 0 && matchDevice(rule.Devices, device) {
+			return rule.Target, true
+		}
+	}
+
+	return "", false
+}
+
+func (r *Rules) target(name, defaultUrl string) (string, string) {
+	for _, target := range r.Targets {
+		if target.Name == name && name != DefaultVariant {
+			return target.Name, target.Url
+		}
+	}
+
+	return DefaultVariant, defaultUrl
+}
+
+// Device returns the device class of the user agent.
+func Device(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, mark := range botMarks {
+		if strings.Contains(ua, mark) {
+			return DeviceBot
+		}
+	}
+
+	if strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile") {
+		return DeviceTablet
+	}
+
+	for _, mark := range mobileMarks {
+		if strings.Contains(ua, mark) {
+			return DeviceMobile
+		}
+	}
+
+	return DeviceDesktop
+}
+
+// Languages returns the language tags of the Accept-Language header in the order of preference.
+func Languages(acceptLanguage string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if len(tag) == 0 || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			langs = append(langs, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	tags := make([]string, 0, len(langs))
+	for _, lang := range langs {
+		tags = append(tags, lang.tag)
+	}
+
+	return tags
+}
+
+// matchLanguage tells if lang is any of the languages or one of their subtags, like en-us of en.
+func matchLanguage(languages []string, lang string) bool {
+	for _, l := range languages {
+		l = strings.ToLower(l)
+		if lang == l || strings.HasPrefix(lang, l+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchDevice(devices []string, device string) bool {
+	if len(devices) == 0 {
+		return true
+	}
+
+	for _, d := range devices {
+		if d == device {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package targeting
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	iphone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 14_4 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	ipad    = "Mozilla/5.0 (iPad; CPU OS 14_4 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	pixel   = "Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 Chrome/89.0 Mobile Safari/537.36"
+	galaxy  = "Mozilla/5.0 (Linux; Android 10; SM-T510) AppleWebKit/537.36 Chrome/89.0 Safari/537.36"
+	mac     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 11_2_3) AppleWebKit/605.1.15 Version/14.0.3 Safari/605.1.15"
+	googler = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+)
+
+func TestDevice(t *testing.T) {
+	tests := map[string]string{
+		iphone:  DeviceMobile,
+		ipad:    DeviceTablet,
+		pixel:   DeviceMobile,
+		galaxy:  DeviceTablet,
+		mac:     DeviceDesktop,
+		googler: DeviceBot,
+		"":      DeviceDesktop,
+	}
+
+	for ua, want := range tests {
+		if got := Device(ua); got != want {
+			t.Errorf("Device(%q) = %s, want %s", ua, got, want)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{header: "", want: []string{}},
+		{header: "en-US", want: []string{"en-us"}},
+		{header: "fr;q=0.8, de-CH, en;q=0.9, *;q=0.5", want: []string{"de-ch", "en", "fr"}},
+		{header: "zh-CN, ja;q=0", want: []string{"zh-cn"}},
+	}
+
+	for _, tt := range tests {
+		if got := Languages(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Languages(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		err   error
+	}{
+		{
+			name: "valid",
+			rules: Rules{
+				Targets: []Target{{Name: "a", Url: "https://a.com/", Weight: 1}, {Name: DefaultVariant, Weight: 1}},
+				Rules:   []Rule{{Devices: []string{DeviceMobile}, Target: "a"}, {Languages: []string{"en"}, Target: DefaultVariant}},
+			},
+		},
+		{name: "duplicated target", rules: Rules{Targets: []Target{{Name: "a", Url: "u"}, {Name: "a", Url: "u"}}}, err: ErrTargetName},
+		{name: "missing url", rules: Rules{Targets: []Target{{Name: "a"}}}, err: ErrTargetUrl},
+		{name: "negative weight", rules: Rules{Targets: []Target{{Name: "a", Url: "u", Weight: -1}}}, err: ErrWeight},
+		{name: "unknown target", rules: Rules{Rules: []Rule{{Target: "b"}}}, err: ErrUnknownTarget},
+		{name: "unknown device", rules: Rules{Rules: []Rule{{Devices: []string{"watch"}, Target: DefaultVariant}}}, err: ErrUnknownDevice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeUrls(t *testing.T) {
+	errBad := errors.New("bad url")
+	normalize := func(url string) (string, error) {
+		if url == "bad" {
+			return "", errBad
+		}
+		return url + "/", nil
+	}
+
+	rules := Rules{Targets: []Target{{Name: DefaultVariant}, {Name: "a", Url: "https://a.com"}}}
+	if err := rules.NormalizeUrls(normalize); err != nil || rules.Targets[1].Url != "https://a.com/" || rules.Targets[0].Url != "" {
+		t.Errorf("NormalizeUrls() = %v, targets %+v", err, rules.Targets)
+	}
+
+	rules.Targets = append(rules.Targets, Target{Name: "b", Url: "bad"})
+	err := rules.NormalizeUrls(normalize)
+	var targetErr *TargetUrlError
+	if !errors.As(err, &targetErr) || targetErr.Index != 2 || !errors.Is(err, errBad) {
+		t.Errorf("NormalizeUrls() error = %v, want the url error of target 2", err)
+	}
+}
+
+func TestPick(t *testing.T) {
+	rules := &Rules{
+		Targets: []Target{
+			{Name: "a", Url: "https://a.com/", Weight: 1},
+			{Name: "b", Url: "https://b.com/", Weight: 3},
+			{Name: "fr", Url: "https://fr.com/"},
+			{Name: "app", Url: "https://app.com/"},
+		},
+		Rules: []Rule{
+			{Languages: []string{"fr"}, Target: "fr"},
+			{Languages: []string{"en"}, Devices: []string{DeviceBot}, Target: DefaultVariant},
+			{Devices: []string{DeviceMobile, DeviceTablet}, Target: "app"},
+		},
+	}
+	defer func(intn func(int) int) {
+		randIntn = intn
+	}(randIntn)
+
+	tests := []struct {
+		name    string
+		client  Client
+		n       int
+		variant string
+		url     string
+	}{
+		{name: "language", client: Client{UserAgent: iphone, AcceptLanguage: "fr-FR"}, variant: "fr", url: "https://fr.com/"},
+		{name: "preferred language first", client: Client{AcceptLanguage: "en;q=0.5, fr;q=0.8", UserAgent: googler}, variant: "fr", url: "https://fr.com/"},
+		{name: "language and device", client: Client{AcceptLanguage: "en-GB", UserAgent: googler}, variant: DefaultVariant, url: "https://default.com/"},
+		{name: "device", client: Client{AcceptLanguage: "de", UserAgent: ipad}, variant: "app", url: "https://app.com/"},
+		{name: "split first", client: Client{UserAgent: mac}, n: 0, variant: "a", url: "https://a.com/"},
+		{name: "split second", client: Client{UserAgent: mac}, n: 3, variant: "b", url: "https://b.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			randIntn = func(n int) int {
+				if n != 4 {
+					t.Errorf("total weight = %d, want 4", n)
+				}
+				return tt.n
+			}
+
+			variant, url := rules.Pick(tt.client, "https://default.com/")
+			if variant != tt.variant || url != tt.url {
+				t.Errorf("Pick() = %s, %s, want %s, %s", variant, url, tt.variant, tt.url)
+			}
+		})
+	}
+
+	var none *Rules
+	if variant, url := none.Pick(Client{}, "https://default.com/"); variant != DefaultVariant || url != "https://default.com/" {
+		t.Errorf("Pick() without rules = %s, %s", variant, url)
+	}
+}
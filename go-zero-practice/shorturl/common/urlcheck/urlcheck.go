@@ -0,0 +1,172 @@
+package urlcheck
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrEmpty         = errors.New("url is empty")
+	ErrTooLong       = errors.New("url is too long")
+	ErrMalformed     = errors.New("url is malformed")
+	ErrScheme        = errors.New("only http and https urls are allowed")
+	ErrMissingHost   = errors.New("url has no host")
+	ErrUserInfo      = errors.New("url must not contain user info")
+	ErrBlocked       = errors.New("host is blocked")
+	ErrSelfReference = errors.New("url points to the short domain itself")
+)
+
+// Conf defines the rules a url must pass before being shortened.
+type Conf struct {
+	// ShortDomain is the domain the short links are served on, links to it are rejected.
+	ShortDomain string
+	MaxLength   int `json:",default=2048"`
+	// BlockedHosts rejects the hosts and all of their subdomains.
+	BlockedHosts []string `json:",optional"`
+	// DeniedPatterns rejects the hosts matching any of the regular expressions.
+	DeniedPatterns []string `json:",optional"`
+}
+
+// A Checker normalizes urls and rejects the ones that are not allowed to be shortened.
+type Checker struct {
+	maxLength    int
+	selfHost     string
+	blockedHosts []string
+	denied       []*regexp.Regexp
+}
+
+// NewChecker returns a Checker with the given config.
+func NewChecker(c Conf) (*Checker, error) {
+	checker := &Checker{
+		maxLength: c.MaxLength,
+		selfHost:  domainHost(c.ShortDomain),
+	}
+	for _, host := range c.BlockedHosts {
+		host = normalizeHost(host)
+		if len(host) > 0 {
+			checker.blockedHosts = append(checker.blockedHosts, host)
+		}
+	}
+	for _, pattern := range c.DeniedPatterns {
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied pattern %q: %v", pattern, err)
+		}
+		checker.denied = append(checker.denied, reg)
+	}
+
+	return checker, nil
+}
+
+// MustNewChecker returns a Checker with the given config, panics on error.
+func MustNewChecker(c Conf) *Checker {
+	checker, err := NewChecker(c)
+	if err != nil {
+		panic(err)
+	}
+
+	return checker
+}
+
+// Normalize checks the raw url and returns its normalized form.
+func (c *Checker) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "", ErrEmpty
+	}
+	if c.maxLength > 0 && len(raw) > c.maxLength {
+		return "", ErrTooLong
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", ErrScheme
+	}
+	if len(u.Opaque) > 0 {
+		return "", ErrMalformed
+	}
+	if u.User != nil {
+		return "", ErrUserInfo
+	}
+
+	host := normalizeHost(u.Hostname())
+	if len(host) == 0 {
+		return "", ErrMissingHost
+	}
+	if err := c.checkHost(host); err != nil {
+		return "", err
+	}
+
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case len(port) > 0:
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	if len(u.Path) == 0 {
+		u.Path = "/"
+	}
+
+	normalized := u.String()
+	if c.maxLength > 0 && len(normalized) > c.maxLength {
+		return "", ErrTooLong
+	}
+
+	return normalized, nil
+}
+
+func (c *Checker) checkHost(host string) error {
+	if len(c.selfHost) > 0 && host == c.selfHost {
+		return ErrSelfReference
+	}
+
+	for _, blocked := range c.blockedHosts {
+		if host == blocked || strings.HasSuffix(host, "."+blocked) {
+			return fmt.Errorf("%w: %s", ErrBlocked, host)
+		}
+	}
+
+	for _, reg := range c.denied {
+		if reg.MatchString(host) {
+			return fmt.Errorf("%w: %s", ErrBlocked, host)
+		}
+	}
+
+	return nil
+}
+
+func domainHost(domain string) string {
+	domain = strings.TrimSpace(domain)
+	if len(domain) == 0 {
+		return ""
+	}
+	if !strings.Contains(domain, "://") {
+		domain = "http://" + domain
+	}
+
+	u, err := url.Parse(domain)
+	if err != nil {
+		return ""
+	}
+
+	return normalizeHost(u.Hostname())
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,55 @@
+package urlcheck
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChecker_Normalize(t *testing.T) {
+	checker := MustNewChecker(Conf{
+		ShortDomain:    "https://s.example.com",
+		MaxLength:      64,
+		BlockedHosts:   []string{"Evil.com"},
+		DeniedPatterns: []string{`^(\d{1,3}\.){3}\d{1,3}$`},
+	})
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+		err  error
+	}{
+		{name: "lower case scheme and host", raw: " HTTPS://Go.Dev/Doc ", want: "https://go.dev/Doc"},
+		{name: "default port", raw: "http://go.dev:80", want: "http://go.dev/"},
+		{name: "custom port", raw: "http://go.dev:8080/a?b=c#d", want: "http://go.dev:8080/a?b=c#d"},
+		{name: "empty", raw: "  ", err: ErrEmpty},
+		{name: "javascript", raw: "javascript:alert(1)", err: ErrScheme},
+		{name: "opaque", raw: "http:go.dev", err: ErrMalformed},
+		{name: "ftp", raw: "ftp://go.dev/file", err: ErrScheme},
+		{name: "no scheme", raw: "go.dev/doc", err: ErrScheme},
+		{name: "no host", raw: "http:///path", err: ErrMissingHost},
+		{name: "user info", raw: "https://go.dev@evil.com", err: ErrUserInfo},
+		{name: "too long", raw: "https://go.dev/" + string(make([]byte, 64)), err: ErrTooLong},
+		{name: "blocked host", raw: "https://evil.com/x", err: ErrBlocked},
+		{name: "blocked subdomain", raw: "https://www.evil.com./x", err: ErrBlocked},
+		{name: "denied pattern", raw: "http://10.0.0.1/admin", err: ErrBlocked},
+		{name: "self reference", raw: "https://S.example.com/abcdef", err: ErrSelfReference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checker.Normalize(tt.raw)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewChecker_InvalidPattern(t *testing.T) {
+	if _, err := NewChecker(Conf{DeniedPatterns: []string{"("}}); err == nil {
+		t.Error("NewChecker() expected error for invalid pattern")
+	}
+}
@@ -3,7 +3,15 @@ module shorturl
 go 1.14
 
 require (
+	github.com/alicebob/miniredis/v2 v2.14.1
+	github.com/go-redis/redis v6.15.7+incompatible
 	github.com/golang/protobuf v1.4.2
+	github.com/makiuchi-d/gozxing v0.1.1
+	github.com/mattn/go-sqlite3 v1.14.6
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/tal-tech/go-zero v1.1.6
+	go.etcd.io/bbolt v1.3.5
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	google.golang.org/genproto v0.0.0-20200729003335-053ba62fc06f
 	google.golang.org/grpc v1.29.1
 )
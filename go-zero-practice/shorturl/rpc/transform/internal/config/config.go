@@ -2,10 +2,16 @@ package config
 
 import "github.com/tal-tech/go-zero/zrpc"
 import "github.com/tal-tech/go-zero/core/stores/cache"
+import "shorturl/common/localcache"
+import "shorturl/common/urlcheck"
 
 type Config struct {
 	zrpc.RpcServerConf
+	Driver     string          `json:",default=mysql,options=mysql|sqlite|postgres|bolt"` // 手动代码
 	DataSource string          // 手动代码
 	Table      string          // 手动代码
-	Cache      cache.CacheConf // 手动代码
+	Cache      cache.CacheConf `json:",optional"` // 手动代码
+	Url        urlcheck.Conf   // 手动代码
+	MaxBatch   int             `json:",default=1000"` // 手动代码
+	LocalCache localcache.Conf // 手动代码
 }
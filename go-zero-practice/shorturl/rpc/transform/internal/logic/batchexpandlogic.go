@@ -0,0 +1,74 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"shorturl/common/targeting"
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type BatchExpandLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewBatchExpandLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BatchExpandLogic {
+	return &BatchExpandLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+func (l *BatchExpandLogic) BatchExpand(in *transform.BatchExpandReq) (*transform.BatchExpandResp, error) {
+	if len(in.Shortens) > l.svcCtx.Config.MaxBatch {
+		return nil, invalidArgument("shortens", fmt.Errorf("at most %d keys in one batch", l.svcCtx.Config.MaxBatch))
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, key := range in.Shortens {
+		if len(key) > 0 && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	found := make(map[string]*model.Shorturl)
+	if len(keys) > 0 {
+		items, err := l.svcCtx.Model.FindMany(keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			found[item.Shorten] = item
+		}
+	}
+
+	client := targeting.Client{
+		UserAgent:      in.UserAgent,
+		AcceptLanguage: in.AcceptLanguage,
+	}
+	results := make([]*transform.ExpandResult, len(in.Shortens))
+	for i, key := range in.Shortens {
+		results[i] = &transform.ExpandResult{Shorten: key}
+		item, ok := found[key]
+		switch {
+		case !ok || item.Disabled:
+			results[i].Error = errShortenNotFound.Error()
+		case len(item.Password) > 0 || item.MaxClicks > 0:
+			// the password and the clicks are only handled by Expand
+			results[i].Error = errProtectedLink.Error()
+		default:
+			_, results[i].Variant, results[i].Url = pickTarget(l.Logger, item, client)
+		}
+	}
+
+	return &transform.BatchExpandResp{Results: results}, nil
+}
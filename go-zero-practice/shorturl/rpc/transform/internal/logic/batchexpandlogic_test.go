@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+)
+
+const testRules = `{"targets":[{"name":"de","url":"https://go.dev/de"}],"rules":[{"languages":["de"],"target":"de"}]}`
+
+func TestBatchExpand(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	if _, err := svcCtx.Model.InsertMany([]model.Shorturl{
+		{Shorten: "plain", Url: "https://go.dev/"},
+		{Shorten: "targeted", Url: "https://go.dev/en", Rules: testRules},
+		{Shorten: "disabled", Url: "https://go.dev/", Disabled: true},
+		{Shorten: "limited", Url: "https://go.dev/", MaxClicks: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           []*transform.ExpandResult
+	}{
+		{name: "default", acceptLanguage: "en-US", want: []*transform.ExpandResult{
+			{Shorten: "plain", Url: "https://go.dev/", Variant: "default"},
+			{Shorten: "targeted", Url: "https://go.dev/en", Variant: "default"},
+			{Shorten: "disabled", Error: errShortenNotFound.Error()},
+			{Shorten: "limited", Error: errProtectedLink.Error()},
+			{Shorten: "missing", Error: errShortenNotFound.Error()},
+		}},
+		{name: "rule", acceptLanguage: "de-DE,de;q=0.9", want: []*transform.ExpandResult{
+			{Shorten: "plain", Url: "https://go.dev/", Variant: "default"},
+			{Shorten: "targeted", Url: "https://go.dev/de", Variant: "de"},
+			{Shorten: "disabled", Error: errShortenNotFound.Error()},
+			{Shorten: "limited", Error: errProtectedLink.Error()},
+			{Shorten: "missing", Error: errShortenNotFound.Error()},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, result := range tt.want {
+				keys = append(keys, result.Shorten)
+			}
+			resp, err := NewBatchExpandLogic(context.Background(), svcCtx).BatchExpand(&transform.BatchExpandReq{
+				Shortens:       keys,
+				AcceptLanguage: tt.acceptLanguage,
+			})
+			if err != nil {
+				t.Fatalf("BatchExpand() error = %v", err)
+			}
+			if len(resp.Results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(resp.Results), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := resp.Results[i]
+				if got.Shorten != want.Shorten || got.Url != want.Url || got.Variant != want.Variant || got.Error != want.Error {
+					t.Errorf("result %d = %+v, want %+v", i, got, want)
+				}
+			}
+
+			// batch expand resolves the targeted link like expand
+			single, err := NewExpandLogic(context.Background(), svcCtx).Expand(&transform.ExpandReq{
+				Shorten:        "targeted",
+				AcceptLanguage: tt.acceptLanguage,
+			})
+			if err != nil || single.Url != resp.Results[1].Url || single.Variant != resp.Results[1].Variant {
+				t.Errorf("Expand() = %+v, %v, batch result %+v", single, err, resp.Results[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type BatchShortenLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewBatchShortenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BatchShortenLogic {
+	return &BatchShortenLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+func (l *BatchShortenLogic) BatchShorten(in *transform.BatchShortenReq) (*transform.BatchShortenResp, error) {
+	if len(in.Urls) > l.svcCtx.Config.MaxBatch {
+		return nil, invalidArgument("urls", fmt.Errorf("at most %d urls in one batch", l.svcCtx.Config.MaxBatch))
+	}
+
+	results := make([]*transform.ShortenResult, len(in.Urls))
+	// shorten key -> normalized url, the same url may appear several times in one batch
+	urls := make(map[string]string)
+	var keys []string
+	for i, raw := range in.Urls {
+		results[i] = &transform.ShortenResult{Url: raw}
+		url, err := l.svcCtx.UrlChecker.Normalize(raw)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		key := shortenKey(in.Owner, url)
+		results[i].Url = url
+		results[i].Shorten = key
+		if _, ok := urls[key]; !ok {
+			urls[key] = url
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return &transform.BatchShortenResp{Results: results}, nil
+	}
+
+	existing, err := l.svcCtx.Model.FindMany(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make(map[string]error)
+	for _, item := range existing {
+		if item.Url != urls[item.Shorten] {
+			failures[item.Shorten] = errKeyConflict
+		}
+		delete(urls, item.Shorten)
+	}
+
+	if len(urls) > 0 {
+		data := make([]model.Shorturl, 0, len(urls))
+		for _, key := range keys {
+			if url, ok := urls[key]; ok {
+				data = append(data, model.Shorturl{
+					Shorten: key,
+					Url:     url,
+					Owner:   in.Owner,
+				})
+			}
+		}
+		if _, err := l.svcCtx.Model.InsertMany(data); err != nil {
+			// one bad item or a concurrent insert must not fail the whole batch,
+			// fall back to inserting the items one by one
+			l.Errorf("batch insert %d urls failed, inserting one by one: %v", len(data), err)
+			for _, item := range data {
+				if err := l.insertOne(item); err != nil {
+					failures[item.Shorten] = err
+				}
+			}
+		}
+	}
+
+	for _, result := range results {
+		if err, ok := failures[result.Shorten]; ok {
+			result.Shorten = ""
+			result.Error = err.Error()
+		}
+	}
+
+	return &transform.BatchShortenResp{Results: results}, nil
+}
+
+// insertOne inserts item, a key inserted with the same url in the meantime counts as inserted.
+func (l *BatchShortenLogic) insertOne(item model.Shorturl) error {
+	_, err := l.svcCtx.Model.Insert(item)
+	if err == nil {
+		return nil
+	}
+
+	existing, ferr := l.svcCtx.Model.FindOne(item.Shorten)
+	switch {
+	case ferr != nil:
+		l.Errorf("insert %s failed: %v", item.Shorten, err)
+		return err
+	case existing.Url != item.Url:
+		return errKeyConflict
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+)
+
+// failingBatchModel fails every InsertMany, so the items are inserted one by one.
+type failingBatchModel struct {
+	model.ShorturlModel
+}
+
+func (m failingBatchModel) InsertMany([]model.Shorturl) (sql.Result, error) {
+	return nil, errors.New("batch insert failed")
+}
+
+func TestBatchShorten(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	l := NewBatchShortenLogic(context.Background(), svcCtx)
+
+	conflict := shortenKey("", "https://go.dev/conflict")
+	if _, err := svcCtx.Model.Insert(model.Shorturl{Shorten: conflict, Url: "https://go.dev/other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := l.BatchShorten(&transform.BatchShortenReq{Urls: []string{
+		"https://go.dev/a",
+		"ftp://go.dev/",
+		"https://go.dev/conflict",
+		"https://go.dev/a",
+	}})
+	if err != nil {
+		t.Fatalf("BatchShorten() error = %v", err)
+	}
+
+	key := shortenKey("", "https://go.dev/a")
+	want := []*transform.ShortenResult{
+		{Url: "https://go.dev/a", Shorten: key},
+		{Url: "ftp://go.dev/", Error: "only http and https urls are allowed"},
+		{Url: "https://go.dev/conflict", Error: errKeyConflict.Error()},
+		{Url: "https://go.dev/a", Shorten: key},
+	}
+	assertShortenResults(t, resp.Results, want)
+	if item, err := svcCtx.Model.FindOne(key); err != nil || item.Url != "https://go.dev/a" {
+		t.Errorf("FindOne(%s) = %+v, %v", key, item, err)
+	}
+}
+
+func TestBatchShortenTooMany(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	urls := make([]string, svcCtx.Config.MaxBatch+1)
+	if _, err := NewBatchShortenLogic(context.Background(), svcCtx).BatchShorten(&transform.BatchShortenReq{Urls: urls}); err == nil {
+		t.Error("BatchShorten() over the batch limit expected error")
+	}
+}
+
+func TestBatchShortenFallback(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	svcCtx.Model = failingBatchModel{ShorturlModel: svcCtx.Model}
+
+	// the key is taken by another url after FindMany, only its item fails
+	conflict := shortenKey("u1", "https://go.dev/b")
+	svcCtx.Model = &racingModel{ShorturlModel: svcCtx.Model, insert: model.Shorturl{Shorten: conflict, Url: "https://go.dev/other"}}
+
+	resp, err := NewBatchShortenLogic(context.Background(), svcCtx).BatchShorten(&transform.BatchShortenReq{
+		Owner: "u1",
+		Urls:  []string{"https://go.dev/a", "https://go.dev/b", "https://go.dev/c"},
+	})
+	if err != nil {
+		t.Fatalf("BatchShorten() error = %v", err)
+	}
+
+	want := []*transform.ShortenResult{
+		{Url: "https://go.dev/a", Shorten: shortenKey("u1", "https://go.dev/a")},
+		{Url: "https://go.dev/b", Error: errKeyConflict.Error()},
+		{Url: "https://go.dev/c", Shorten: shortenKey("u1", "https://go.dev/c")},
+	}
+	assertShortenResults(t, resp.Results, want)
+	for _, result := range []*transform.ShortenResult{want[0], want[2]} {
+		if item, err := svcCtx.Model.FindOne(result.Shorten); err != nil || item.Url != result.Url || item.Owner != "u1" {
+			t.Errorf("FindOne(%s) = %+v, %v", result.Shorten, item, err)
+		}
+	}
+}
+
+// racingModel inserts a link right after the first FindMany, like a concurrent request would.
+type racingModel struct {
+	model.ShorturlModel
+	insert   model.Shorturl
+	inserted bool
+}
+
+func (m *racingModel) FindMany(shortens []string) ([]*model.Shorturl, error) {
+	items, err := m.ShorturlModel.FindMany(shortens)
+	if err != nil || m.inserted {
+		return items, err
+	}
+	m.inserted = true
+	_, err = m.ShorturlModel.Insert(m.insert)
+	return items, err
+}
+
+func assertShortenResults(t *testing.T, got, want []*transform.ShortenResult) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Url != want[i].Url || got[i].Shorten != want[i].Shorten || got[i].Error != want[i].Error {
+			t.Errorf("result %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type DeleteLinkLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewDeleteLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteLinkLogic {
+	return &DeleteLinkLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+func (l *DeleteLinkLogic) DeleteLink(in *transform.DeleteLinkReq) (*transform.DeleteLinkResp, error) {
+	if _, err := findOwnedLink(l.svcCtx, in.Owner, in.Shorten); err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.Model.Delete(in.Shorten); err != nil {
+		return nil, err
+	}
+
+	return &transform.DeleteLinkResp{}, nil
+}
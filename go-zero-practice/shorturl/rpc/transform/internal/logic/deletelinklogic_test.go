@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeleteLink(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	insertLinks(t, svcCtx, model.Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1"})
+	l := NewDeleteLinkLogic(context.Background(), svcCtx)
+
+	if _, err := l.DeleteLink(&transform.DeleteLinkReq{Owner: "u2", Shorten: "k1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteLink() of another owner error = %v, want NotFound", err)
+	}
+	if _, err := svcCtx.Model.FindOne("k1"); err != nil {
+		t.Fatalf("FindOne() after DeleteLink of another owner error = %v", err)
+	}
+
+	if _, err := l.DeleteLink(&transform.DeleteLinkReq{Owner: "u1", Shorten: "k1"}); err != nil {
+		t.Fatalf("DeleteLink() error = %v", err)
+	}
+	if _, err := svcCtx.Model.FindOne("k1"); err != model.ErrNotFound {
+		t.Errorf("FindOne() after DeleteLink error = %v, want %v", err, model.ErrNotFound)
+	}
+	if _, err := l.DeleteLink(&transform.DeleteLinkReq{Owner: "u1", Shorten: "k1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteLink() again error = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errShortenNotFound   = errors.New("shorten key not found")
+	errKeyConflict       = errors.New("shorten key is already used by another url")
+	errProtectedLink     = errors.New("protected link must be expanded on its own")
+	errPasswordNeeded    = errors.New("password required")
+	errWrongPassword     = errors.New("wrong password")
+	errNegativeMaxClicks = errors.New("max clicks must not be negative")
+)
+
+// invalidArgument returns an InvalidArgument status error that carries the offending field.
+func invalidArgument(field string, err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	detailed, derr := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{
+				Field:       field,
+				Description: err.Error(),
+			},
+		},
+	})
+	if derr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
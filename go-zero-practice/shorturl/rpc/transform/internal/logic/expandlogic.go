@@ -3,10 +3,15 @@ package logic
 import (
 	"context"
 
+	"shorturl/common/targeting"
 	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
 	"shorturl/rpc/transform/transform"
 
 	"github.com/tal-tech/go-zero/core/logx"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ExpandLogic struct {
@@ -25,11 +30,58 @@ func NewExpandLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExpandLogi
 
 func (l *ExpandLogic) Expand(in *transform.ExpandReq) (*transform.ExpandResp, error) {
 	res, err := l.svcCtx.Model.FindOne(in.Shorten)
+	if err == model.ErrNotFound {
+		return nil, status.Error(codes.NotFound, errShortenNotFound.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
+	if res.Disabled || res.MaxClicks > 0 && res.Clicks >= res.MaxClicks {
+		return nil, status.Error(codes.NotFound, errShortenNotFound.Error())
+	}
+	if in.Check {
+		return &transform.ExpandResp{}, nil
+	}
+	if len(res.Password) > 0 {
+		if len(in.Password) == 0 {
+			return nil, status.Error(codes.Unauthenticated, errPasswordNeeded.Error())
+		}
+		if bcrypt.CompareHashAndPassword([]byte(res.Password), []byte(in.Password)) != nil {
+			return nil, status.Error(codes.Unauthenticated, errWrongPassword.Error())
+		}
+	}
+
+	rules, variant, url := pickTarget(l.Logger, res, targeting.Client{
+		UserAgent:      in.UserAgent,
+		AcceptLanguage: in.AcceptLanguage,
+	})
+
+	if res.MaxClicks > 0 {
+		// another client may have taken the last click since FindOne
+		counted, err := l.svcCtx.Model.IncrClicks(res.Shorten)
+		if err != nil {
+			return nil, err
+		}
+		if !counted {
+			return nil, status.Error(codes.NotFound, errShortenNotFound.Error())
+		}
+	}
 
 	return &transform.ExpandResp{
-		Url: res.Url,
+		Url:       url,
+		Variant:   variant,
+		Cacheable: rules == nil && len(res.Password) == 0 && res.MaxClicks == 0,
 	}, nil
 }
+
+// pickTarget returns the rules of item and the variant and the url that client is redirected to.
+func pickTarget(logger logx.Logger, item *model.Shorturl, client targeting.Client) (*targeting.Rules, string, string) {
+	rules, err := targeting.Parse(item.Rules)
+	if err != nil {
+		// the rules are validated when set, fall back to the link's own url
+		logger.Errorf("parse rules of %s failed: %v", item.Shorten, err)
+	}
+
+	variant, url := rules.Pick(client, item.Url)
+	return rules, variant, url
+}
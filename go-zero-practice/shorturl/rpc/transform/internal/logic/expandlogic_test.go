@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExpand(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	ctx := context.Background()
+	protected, err := NewShortenLogic(ctx, svcCtx).Shorten(&transform.ShortenReq{Url: "https://go.dev/secret", Password: "s3cret", MaxClicks: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svcCtx.Model.InsertMany([]model.Shorturl{
+		{Shorten: "plain", Url: "https://go.dev/"},
+		{Shorten: "targeted", Url: "https://go.dev/en", Rules: testRules},
+		{Shorten: "disabled", Url: "https://go.dev/", Disabled: true},
+		{Shorten: "badrules", Url: "https://go.dev/", Rules: "{"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		in        *transform.ExpandReq
+		code      codes.Code
+		url       string
+		variant   string
+		cacheable bool
+	}{
+		{name: "plain", in: &transform.ExpandReq{Shorten: "plain"}, url: "https://go.dev/", variant: "default", cacheable: true},
+		{name: "missing", in: &transform.ExpandReq{Shorten: "missing"}, code: codes.NotFound},
+		{name: "disabled", in: &transform.ExpandReq{Shorten: "disabled"}, code: codes.NotFound},
+		{name: "targeted", in: &transform.ExpandReq{Shorten: "targeted", AcceptLanguage: "de"}, url: "https://go.dev/de", variant: "de"},
+		{name: "invalid rules fall back", in: &transform.ExpandReq{Shorten: "badrules"}, url: "https://go.dev/", variant: "default", cacheable: true},
+		{name: "check skips the password", in: &transform.ExpandReq{Shorten: protected.Shorten, Check: true}},
+		{name: "password needed", in: &transform.ExpandReq{Shorten: protected.Shorten}, code: codes.Unauthenticated},
+		{name: "wrong password", in: &transform.ExpandReq{Shorten: protected.Shorten, Password: "wrong"}, code: codes.Unauthenticated},
+		{name: "first click", in: &transform.ExpandReq{Shorten: protected.Shorten, Password: "s3cret"}, url: "https://go.dev/secret", variant: "default"},
+		{name: "last click", in: &transform.ExpandReq{Shorten: protected.Shorten, Password: "s3cret"}, url: "https://go.dev/secret", variant: "default"},
+		{name: "clicks used up", in: &transform.ExpandReq{Shorten: protected.Shorten, Password: "s3cret"}, code: codes.NotFound},
+		{name: "check after the clicks are used up", in: &transform.ExpandReq{Shorten: protected.Shorten, Check: true}, code: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewExpandLogic(ctx, svcCtx).Expand(tt.in)
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expand() error = %v, want %v", err, tt.code)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Url != tt.url || resp.Variant != tt.variant || resp.Cacheable != tt.cacheable {
+				t.Errorf("Expand() = %+v, want %s, %s, %v", resp, tt.url, tt.variant, tt.cacheable)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// findOwnedLink returns the link of shorten if it belongs to owner,
+// the links of other owners are reported as not found to not leak their existence.
+func findOwnedLink(svcCtx *svc.ServiceContext, owner, shorten string) (*model.Shorturl, error) {
+	if len(owner) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "owner is required")
+	}
+
+	item, err := svcCtx.Model.FindOne(shorten)
+	switch {
+	case err == model.ErrNotFound:
+		return nil, status.Error(codes.NotFound, errShortenNotFound.Error())
+	case err != nil:
+		return nil, err
+	case item.Owner != owner:
+		return nil, status.Error(codes.NotFound, errShortenNotFound.Error())
+	default:
+		return item, nil
+	}
+}
+
+// updateOwnedLink writes change to the link of shorten if it belongs to owner and returns the
+// link as stored, only the changed fields are written so concurrent changes and clicks are kept.
+func updateOwnedLink(svcCtx *svc.ServiceContext, owner, shorten string, change model.ShorturlChange) (*model.Shorturl, error) {
+	if _, err := findOwnedLink(svcCtx, owner, shorten); err != nil {
+		return nil, err
+	}
+
+	if err := svcCtx.Model.UpdateOwned(shorten, owner, change); err != nil {
+		return nil, err
+	}
+
+	return findOwnedLink(svcCtx, owner, shorten)
+}
+
+func toLink(item *model.Shorturl) *transform.Link {
+	return &transform.Link{
+		Shorten:    item.Shorten,
+		Url:        item.Url,
+		Owner:      item.Owner,
+		Disabled:   item.Disabled,
+		Rules:      item.Rules,
+		Protected:  len(item.Password) > 0,
+		MaxClicks:  item.MaxClicks,
+		Clicks:     item.Clicks,
+		CreateTime: item.CreateTime.Unix(),
+		UpdateTime: item.UpdateTime.Unix(),
+	}
+}
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"testing"
+
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// insertLinks stores the links of the link management tests.
+func insertLinks(t *testing.T, svcCtx *svc.ServiceContext, items ...model.Shorturl) {
+	t.Helper()
+	if _, err := svcCtx.Model.InsertMany(items); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindOwnedLink(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	insertLinks(t, svcCtx, model.Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1"})
+
+	tests := []struct {
+		name    string
+		owner   string
+		shorten string
+		code    codes.Code
+	}{
+		{"owner", "u1", "k1", codes.OK},
+		{"no owner", "", "k1", codes.Unauthenticated},
+		// the link of another owner looks like a missing one
+		{"another owner", "u2", "k1", codes.NotFound},
+		{"missing", "u1", "k2", codes.NotFound},
+	}
+	for _, tt := range tests {
+		item, err := findOwnedLink(svcCtx, tt.owner, tt.shorten)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: findOwnedLink() error = %v, want %v", tt.name, err, tt.code)
+			continue
+		}
+		if err == nil && item.Shorten != tt.shorten {
+			t.Errorf("%s: findOwnedLink() = %+v", tt.name, item)
+		}
+	}
+	if _, err := findOwnedLink(svcCtx, "u2", "k1"); status.Convert(err).Message() != errShortenNotFound.Error() {
+		t.Errorf("findOwnedLink() of another owner error = %v, want %v", err, errShortenNotFound)
+	}
+}
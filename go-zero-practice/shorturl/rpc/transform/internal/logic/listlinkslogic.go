@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type ListLinksLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewListLinksLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLinksLogic {
+	return &ListLinksLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+func (l *ListLinksLogic) ListLinks(in *transform.ListLinksReq) (*transform.ListLinksResp, error) {
+	if len(in.Owner) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "owner is required")
+	}
+
+	page, pageSize := in.Page, in.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	total, err := l.svcCtx.Model.CountByOwner(in.Owner, in.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &transform.ListLinksResp{Total: total}
+	if total == 0 {
+		return resp, nil
+	}
+
+	items, err := l.svcCtx.Model.FindByOwner(in.Owner, in.Keyword, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		resp.Links = append(resp.Links, toLink(item))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListLinks(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	// the links are created in the same second, so they are listed in key order
+	insertLinks(t, svcCtx,
+		model.Shorturl{Shorten: "k1", Url: "https://go.dev/blog", Owner: "u1"},
+		model.Shorturl{Shorten: "k2", Url: "https://go.dev/doc", Owner: "u1", Password: "hash"},
+		model.Shorturl{Shorten: "k3", Url: "https://go.dev/blog/2", Owner: "u1"},
+		model.Shorturl{Shorten: "k4", Url: "https://go.dev/blog", Owner: "u2"},
+	)
+	l := NewListLinksLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		name  string
+		in    *transform.ListLinksReq
+		total int64
+		keys  []string
+	}{
+		{"all", &transform.ListLinksReq{Owner: "u1"}, 3, []string{"k1", "k2", "k3"}},
+		{"keyword", &transform.ListLinksReq{Owner: "u1", Keyword: "blog"}, 2, []string{"k1", "k3"}},
+		{"first page", &transform.ListLinksReq{Owner: "u1", Page: 1, PageSize: 2}, 3, []string{"k1", "k2"}},
+		{"second page", &transform.ListLinksReq{Owner: "u1", Page: 2, PageSize: 2}, 3, []string{"k3"}},
+		{"past the last page", &transform.ListLinksReq{Owner: "u1", Page: 3, PageSize: 2}, 3, nil},
+		{"no links", &transform.ListLinksReq{Owner: "u3"}, 0, nil},
+	}
+	for _, tt := range tests {
+		resp, err := l.ListLinks(tt.in)
+		if err != nil {
+			t.Fatalf("%s: ListLinks() error = %v", tt.name, err)
+		}
+		var keys []string
+		for _, link := range resp.Links {
+			if link.Owner != tt.in.Owner {
+				t.Errorf("%s: ListLinks() returned the link %s of %s", tt.name, link.Shorten, link.Owner)
+			}
+			keys = append(keys, link.Shorten)
+		}
+		if resp.Total != tt.total || !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%s: ListLinks() = %d %v, want %d %v", tt.name, resp.Total, keys, tt.total, tt.keys)
+		}
+	}
+
+	if resp, err := l.ListLinks(&transform.ListLinksReq{Owner: "u1", Keyword: "doc"}); err != nil || len(resp.Links) != 1 || !resp.Links[0].Protected {
+		t.Errorf("ListLinks() of a protected link = %+v, %v", resp, err)
+	}
+	if _, err := l.ListLinks(&transform.ListLinksReq{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListLinks() without owner error = %v, want Unauthenticated", err)
+	}
+}
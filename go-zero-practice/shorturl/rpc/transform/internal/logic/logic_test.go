@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"path/filepath"
+	"testing"
+
+	"shorturl/common/urlcheck"
+	"shorturl/rpc/transform/internal/config"
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+func init() {
+	logx.Disable()
+}
+
+// newTestServiceContext returns a ServiceContext on a fresh sqlite store.
+func newTestServiceContext(t *testing.T) *svc.ServiceContext {
+	m, err := model.Open(model.DriverSqlite, filepath.Join(t.TempDir(), "shorturl.db"), nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	c := config.Config{
+		MaxBatch: 10,
+		Url:      urlcheck.Conf{ShortDomain: "https://s.test", MaxLength: 2048},
+	}
+	return &svc.ServiceContext{
+		Config:     c,
+		Model:      m,
+		UrlChecker: urlcheck.MustNewChecker(c.Url),
+	}
+}
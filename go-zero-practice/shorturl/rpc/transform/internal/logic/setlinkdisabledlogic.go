@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type SetLinkDisabledLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewSetLinkDisabledLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SetLinkDisabledLogic {
+	return &SetLinkDisabledLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+func (l *SetLinkDisabledLogic) SetLinkDisabled(in *transform.SetLinkDisabledReq) (*transform.LinkResp, error) {
+	item, err := findOwnedLink(l.svcCtx, in.Owner, in.Shorten)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Disabled != in.Disabled {
+		item, err = updateOwnedLink(l.svcCtx, in.Owner, in.Shorten, model.ShorturlChange{Disabled: &in.Disabled})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &transform.LinkResp{
+		Link: toLink(item),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetLinkDisabled(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	insertLinks(t, svcCtx, model.Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1", Rules: testRules})
+	l := NewSetLinkDisabledLogic(context.Background(), svcCtx)
+
+	for _, disabled := range []bool{true, true, false} {
+		resp, err := l.SetLinkDisabled(&transform.SetLinkDisabledReq{Owner: "u1", Shorten: "k1", Disabled: disabled})
+		if err != nil {
+			t.Fatalf("SetLinkDisabled(%v) error = %v", disabled, err)
+		}
+		if resp.Link.Disabled != disabled || resp.Link.Url != "https://go.dev/" || resp.Link.Rules != testRules {
+			t.Errorf("SetLinkDisabled(%v) = %+v", disabled, resp.Link)
+		}
+		if item, err := svcCtx.Model.FindOne("k1"); err != nil || item.Disabled != disabled {
+			t.Errorf("FindOne() after SetLinkDisabled(%v) = %+v, %v", disabled, item, err)
+		}
+	}
+
+	if _, err := l.SetLinkDisabled(&transform.SetLinkDisabledReq{Owner: "u2", Shorten: "k1", Disabled: true}); status.Code(err) != codes.NotFound {
+		t.Errorf("SetLinkDisabled() of another owner error = %v, want NotFound", err)
+	}
+	if item, err := svcCtx.Model.FindOne("k1"); err != nil || item.Disabled {
+		t.Errorf("FindOne() after SetLinkDisabled of another owner = %+v, %v", item, err)
+	}
+}
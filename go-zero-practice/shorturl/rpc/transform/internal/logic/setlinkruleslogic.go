@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"shorturl/common/targeting"
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type SetLinkRulesLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewSetLinkRulesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SetLinkRulesLogic {
+	return &SetLinkRulesLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// SetLinkRules replaces the targeting rules of the link, empty rules remove them.
+func (l *SetLinkRulesLogic) SetLinkRules(in *transform.SetLinkRulesReq) (*transform.LinkResp, error) {
+	rules, err := targeting.Parse(in.Rules)
+	if err != nil {
+		return nil, invalidArgument("rules", err)
+	}
+	if rules != nil {
+		if err := rules.Validate(); err != nil {
+			return nil, invalidArgument("rules", err)
+		}
+
+		var targetErr *targeting.TargetUrlError
+		if err := rules.NormalizeUrls(l.svcCtx.UrlChecker.Normalize); errors.As(err, &targetErr) {
+			return nil, invalidArgument(fmt.Sprintf("rules.targets[%d].url", targetErr.Index), targetErr.Err)
+		}
+	}
+
+	encoded := rules.String()
+	item, err := updateOwnedLink(l.svcCtx, in.Owner, in.Shorten, model.ShorturlChange{Rules: &encoded})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transform.LinkResp{
+		Link: toLink(item),
+	}, nil
+}
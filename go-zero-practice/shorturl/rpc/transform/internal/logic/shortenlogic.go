@@ -10,10 +10,19 @@ import (
 
 	"github.com/tal-tech/go-zero/core/hash"
 	"github.com/tal-tech/go-zero/core/logx"
+	"github.com/tal-tech/go-zero/core/stringx"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	_ "github.com/tal-tech/go-zero/core/stores/sqlx"
 )
 
+const (
+	randomKeyLength   = 8
+	randomKeyAttempts = 3
+)
+
 type ShortenLogic struct {
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
@@ -29,17 +38,109 @@ func NewShortenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ShortenLo
 }
 
 func (l *ShortenLogic) Shorten(in *transform.ShortenReq) (*transform.ShortenResp, error) {
+	url, err := l.svcCtx.UrlChecker.Normalize(in.Url)
+	if err != nil {
+		return nil, invalidArgument("url", err)
+	}
+
+	if in.MaxClicks < 0 {
+		return nil, invalidArgument("max_clicks", errNegativeMaxClicks)
+	}
+	if len(in.Password) > 0 || in.MaxClicks > 0 {
+		return l.shortenProtected(in, url)
+	}
 
-	key := hash.Md5Hex([]byte(in.Url))[:6]
-	_, err := l.svcCtx.Model.Insert(model.Shorturl{
-		Shorten: key,
-		Url:     in.Url,
-	})
+	key := shortenKey(in.Owner, url)
+	existing, err := l.findKey(key)
 	if err != nil {
 		return nil, err
 	}
+	if existing == nil {
+		_, err = l.svcCtx.Model.Insert(model.Shorturl{
+			Shorten: key,
+			Url:     url,
+			Owner:   in.Owner,
+		})
+		if err != nil {
+			// the same url might be shortened concurrently, the key is used then
+			existing, ferr := l.findKey(key)
+			if ferr != nil || existing == nil {
+				return nil, err
+			}
+			return l.existingKey(existing, url)
+		}
+
+		return &transform.ShortenResp{
+			Shorten: key,
+			Url:     url,
+		}, nil
+	}
+
+	return l.existingKey(existing, url)
+}
+
+// findKey returns the link of key or nil, unlike FindOne it doesn't cache
+// a placeholder for the missing key, which would hide the inserted link.
+func (l *ShortenLogic) findKey(key string) (*model.Shorturl, error) {
+	items, err := l.svcCtx.Model.FindMany([]string{key})
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	return items[0], nil
+}
+
+// existingKey returns the key of the link that already shortens url,
+// the key of a link changed to another url can't be used again.
+func (l *ShortenLogic) existingKey(item *model.Shorturl, url string) (*transform.ShortenResp, error) {
+	if item.Url != url {
+		return nil, status.Error(codes.AlreadyExists, errKeyConflict.Error())
+	}
 
 	return &transform.ShortenResp{
-		Shorten: key,
+		Shorten: item.Shorten,
+		Url:     item.Url,
 	}, nil
 }
+
+// shortenProtected always creates a new link with a random key,
+// so the protection isn't shared with other links of the same url.
+func (l *ShortenLogic) shortenProtected(in *transform.ShortenReq, url string) (*transform.ShortenResp, error) {
+	var password string
+	if len(in.Password) > 0 {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, invalidArgument("password", err)
+		}
+		password = string(hashed)
+	}
+
+	var err error
+	for i := 0; i < randomKeyAttempts; i++ {
+		key := stringx.Randn(randomKeyLength)
+		_, err = l.svcCtx.Model.Insert(model.Shorturl{
+			Shorten:   key,
+			Url:       url,
+			Owner:     in.Owner,
+			Password:  password,
+			MaxClicks: in.MaxClicks,
+		})
+		if err == nil {
+			return &transform.ShortenResp{
+				Shorten: key,
+				Url:     url,
+			}, nil
+		}
+	}
+
+	return nil, err
+}
+
+// shortenKey generates the key of url, the same url gets different keys for different owners.
+func shortenKey(owner, url string) string {
+	if len(owner) == 0 {
+		return hash.Md5Hex([]byte(url))[:6]
+	}
+
+	return hash.Md5Hex([]byte(owner + "\n" + url))[:6]
+}
@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShortenReturnsStoredUrl(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	l := NewShortenLogic(context.Background(), svcCtx)
+
+	for _, in := range []*transform.ShortenReq{
+		{Url: " HTTPS://Go.Dev/a "},
+		{Url: " HTTPS://Go.Dev/a ", MaxClicks: 1},
+	} {
+		resp, err := l.Shorten(in)
+		if err != nil {
+			t.Fatalf("Shorten(%+v) error = %v", in, err)
+		}
+		item, err := svcCtx.Model.FindOne(resp.Shorten)
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if resp.Url != item.Url || resp.Url == in.Url {
+			t.Errorf("Shorten(%+v) url = %q, stored %q", in, resp.Url, item.Url)
+		}
+	}
+}
+
+func TestShortenTwice(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	l := NewShortenLogic(context.Background(), svcCtx)
+
+	first, err := l.Shorten(&transform.ShortenReq{Owner: "u1", Url: "https://go.dev/a"})
+	if err != nil {
+		t.Fatalf("Shorten() error = %v", err)
+	}
+	second, err := l.Shorten(&transform.ShortenReq{Owner: "u1", Url: " HTTPS://go.dev/a"})
+	if err != nil || second.Shorten != first.Shorten || second.Url != first.Url {
+		t.Errorf("Shorten() again = %+v, %v, want %+v", second, err, first)
+	}
+
+	// the link is changed to another url, its key can't shorten the old url
+	item, err := svcCtx.Model.FindOne(first.Shorten)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Url = "https://go.dev/b"
+	if err := svcCtx.Model.Update(*item); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Shorten(&transform.ShortenReq{Owner: "u1", Url: "https://go.dev/a"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Shorten() of a changed link error = %v, want AlreadyExists", err)
+	}
+}
+
+func TestShortenConcurrently(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	key := shortenKey("u1", "https://go.dev/a")
+	svcCtx.Model = &racingModel{ShorturlModel: svcCtx.Model, insert: model.Shorturl{Shorten: key, Url: "https://go.dev/a", Owner: "u1"}}
+
+	resp, err := NewShortenLogic(context.Background(), svcCtx).Shorten(&transform.ShortenReq{Owner: "u1", Url: "https://go.dev/a"})
+	if err != nil || resp.Shorten != key {
+		t.Errorf("Shorten() = %+v, %v, want %s", resp, err, key)
+	}
+}
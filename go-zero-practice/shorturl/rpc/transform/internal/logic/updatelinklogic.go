@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+
+	"shorturl/rpc/transform/internal/svc"
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"github.com/tal-tech/go-zero/core/logx"
+)
+
+type UpdateLinkLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewUpdateLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateLinkLogic {
+	return &UpdateLinkLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+func (l *UpdateLinkLogic) UpdateLink(in *transform.UpdateLinkReq) (*transform.LinkResp, error) {
+	url, err := l.svcCtx.UrlChecker.Normalize(in.Url)
+	if err != nil {
+		return nil, invalidArgument("url", err)
+	}
+
+	item, err := updateOwnedLink(l.svcCtx, in.Owner, in.Shorten, model.ShorturlChange{Url: &url})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transform.LinkResp{
+		Link: toLink(item),
+	}, nil
+}
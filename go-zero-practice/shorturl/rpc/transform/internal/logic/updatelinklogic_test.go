@@ -0,0 +1,75 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"shorturl/rpc/transform/model"
+	"shorturl/rpc/transform/transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateLink(t *testing.T) {
+	svcCtx := newTestServiceContext(t)
+	insertLinks(t, svcCtx, model.Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1", MaxClicks: 5})
+	l := NewUpdateLinkLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		name string
+		in   *transform.UpdateLinkReq
+		code codes.Code
+	}{
+		{"invalid url", &transform.UpdateLinkReq{Owner: "u1", Shorten: "k1", Url: "ftp://go.dev/"}, codes.InvalidArgument},
+		{"another owner", &transform.UpdateLinkReq{Owner: "u2", Shorten: "k1", Url: "https://evil.test/"}, codes.NotFound},
+		{"missing", &transform.UpdateLinkReq{Owner: "u1", Shorten: "k2", Url: "https://go.dev/doc"}, codes.NotFound},
+	}
+	for _, tt := range tests {
+		if _, err := l.UpdateLink(tt.in); status.Code(err) != tt.code {
+			t.Errorf("%s: UpdateLink() error = %v, want %v", tt.name, err, tt.code)
+		}
+	}
+
+	// the link is read before a concurrent click and disable, the update must not revert them
+	store := svcCtx.Model
+	svcCtx.Model = &staleModel{ShorturlModel: store, items: make(map[string]*model.Shorturl)}
+	if _, err := svcCtx.Model.FindOne("k1"); err != nil {
+		t.Fatal(err)
+	}
+	disabled := true
+	if err := store.UpdateOwned("k1", "u1", model.ShorturlChange{Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.IncrClicks("k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.UpdateLink(&transform.UpdateLinkReq{Owner: "u1", Shorten: "k1", Url: " HTTPS://Go.Dev/doc "}); err != nil {
+		t.Fatalf("UpdateLink() error = %v", err)
+	}
+	if item, err := store.FindOne("k1"); err != nil || item.Url != "https://go.dev/doc" || !item.Disabled || item.Clicks != 1 || item.MaxClicks != 5 {
+		t.Errorf("FindOne() after UpdateLink = %+v, %v", item, err)
+	}
+}
+
+// staleModel returns the links as they were first read, like a cache that missed the later changes.
+type staleModel struct {
+	model.ShorturlModel
+	items map[string]*model.Shorturl
+}
+
+func (m *staleModel) FindOne(shorten string) (*model.Shorturl, error) {
+	if item, ok := m.items[shorten]; ok {
+		copied := *item
+		return &copied, nil
+	}
+
+	item, err := m.ShorturlModel.FindOne(shorten)
+	if err != nil {
+		return nil, err
+	}
+	copied := *item
+	m.items[shorten] = &copied
+	return item, nil
+}
@@ -30,3 +30,38 @@ func (s *TransformerServer) Shorten(ctx context.Context, in *transform.ShortenRe
 	l := logic.NewShortenLogic(ctx, s.svcCtx)
 	return l.Shorten(in)
 }
+
+func (s *TransformerServer) BatchShorten(ctx context.Context, in *transform.BatchShortenReq) (*transform.BatchShortenResp, error) {
+	l := logic.NewBatchShortenLogic(ctx, s.svcCtx)
+	return l.BatchShorten(in)
+}
+
+func (s *TransformerServer) BatchExpand(ctx context.Context, in *transform.BatchExpandReq) (*transform.BatchExpandResp, error) {
+	l := logic.NewBatchExpandLogic(ctx, s.svcCtx)
+	return l.BatchExpand(in)
+}
+
+func (s *TransformerServer) ListLinks(ctx context.Context, in *transform.ListLinksReq) (*transform.ListLinksResp, error) {
+	l := logic.NewListLinksLogic(ctx, s.svcCtx)
+	return l.ListLinks(in)
+}
+
+func (s *TransformerServer) UpdateLink(ctx context.Context, in *transform.UpdateLinkReq) (*transform.LinkResp, error) {
+	l := logic.NewUpdateLinkLogic(ctx, s.svcCtx)
+	return l.UpdateLink(in)
+}
+
+func (s *TransformerServer) SetLinkDisabled(ctx context.Context, in *transform.SetLinkDisabledReq) (*transform.LinkResp, error) {
+	l := logic.NewSetLinkDisabledLogic(ctx, s.svcCtx)
+	return l.SetLinkDisabled(in)
+}
+
+func (s *TransformerServer) SetLinkRules(ctx context.Context, in *transform.SetLinkRulesReq) (*transform.LinkResp, error) {
+	l := logic.NewSetLinkRulesLogic(ctx, s.svcCtx)
+	return l.SetLinkRules(in)
+}
+
+func (s *TransformerServer) DeleteLink(ctx context.Context, in *transform.DeleteLinkReq) (*transform.DeleteLinkResp, error) {
+	l := logic.NewDeleteLinkLogic(ctx, s.svcCtx)
+	return l.DeleteLink(in)
+}
@@ -1,18 +1,19 @@
 package svc
 
 import "shorturl/rpc/transform/internal/config"
+import "shorturl/common/urlcheck"
 import "shorturl/rpc/transform/model"
 
-import "github.com/tal-tech/go-zero/core/stores/sqlx"
-
 type ServiceContext struct {
-	Config config.Config
-	Model  model.ShorturlModel
+	Config     config.Config
+	Model      model.ShorturlModel
+	UrlChecker *urlcheck.Checker
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	return &ServiceContext{
-		Config: c,
-		Model:  model.NewShorturlModel(sqlx.NewMysql(c.DataSource), c.Cache), // 手动代码
+		Config:     c,
+		Model:      model.NewLocalCachedShorturlModel(model.MustOpen(c.Driver, c.DataSource, c.Cache), c.LocalCache), // 手动代码
+		UrlChecker: urlcheck.MustNewChecker(c.Url),                                                                   // 手动代码
 	}
 }
@@ -0,0 +1,347 @@
+package model
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltShorturlBucket = []byte("shorturl")
+	// boltOwnerBucket indexes the shorten keys by owner, keys are owner\x00shorten.
+	boltOwnerBucket = []byte("shorturl_owner")
+)
+
+type (
+	// BoltShorturlModel is a ShorturlModel that stores the links in an embedded bolt database.
+	BoltShorturlModel struct {
+		db *bolt.DB
+	}
+
+	boltResult int64
+)
+
+// NewBoltShorturlModel returns a BoltShorturlModel that stores the links in the file of path.
+func NewBoltShorturlModel(path string) (*BoltShorturlModel, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltShorturlBucket, boltOwnerBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltShorturlModel{db: db}, nil
+}
+
+// Close closes the underlying bolt database.
+func (m *BoltShorturlModel) Close() error {
+	return m.db.Close()
+}
+
+func (m *BoltShorturlModel) Insert(data Shorturl) (sql.Result, error) {
+	return m.InsertMany([]Shorturl{data})
+}
+
+func (m *BoltShorturlModel) InsertMany(data []Shorturl) (sql.Result, error) {
+	now := boltNow()
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		// the keys of the batch are checked too, the last item must not silently overwrite an earlier one
+		seen := make(map[string]bool, len(data))
+		for _, item := range data {
+			if seen[item.Shorten] || tx.Bucket(boltShorturlBucket).Get([]byte(item.Shorten)) != nil {
+				return ErrDuplicateKey
+			}
+			seen[item.Shorten] = true
+
+			item.CreateTime = now
+			item.UpdateTime = now
+			if err := boltPut(tx, &item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return boltResult(len(data)), nil
+}
+
+func (m *BoltShorturlModel) FindOne(shorten string) (*Shorturl, error) {
+	var resp *Shorturl
+	err := m.db.View(func(tx *bolt.Tx) error {
+		item, err := boltGet(tx, shorten)
+		resp = item
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, ErrNotFound
+	}
+
+	return resp, nil
+}
+
+func (m *BoltShorturlModel) FindMany(shortens []string) ([]*Shorturl, error) {
+	var resp []*Shorturl
+	err := m.db.View(func(tx *bolt.Tx) error {
+		for _, shorten := range shortens {
+			item, err := boltGet(tx, shorten)
+			if err != nil {
+				return err
+			}
+			if item != nil {
+				resp = append(resp, item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (m *BoltShorturlModel) FindAfter(shorten string, limit int64) ([]*Shorturl, error) {
+	var resp []*Shorturl
+	err := m.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltShorturlBucket).Cursor()
+		k, v := cursor.Seek([]byte(shorten))
+		if k != nil && string(k) == shorten {
+			k, v = cursor.Next()
+		}
+		for ; k != nil && int64(len(resp)) < limit; k, v = cursor.Next() {
+			var item Shorturl
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+			resp = append(resp, &item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (m *BoltShorturlModel) FindByOwner(owner, keyword string, offset, limit int64) ([]*Shorturl, error) {
+	items, err := m.findByOwner(owner, keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreateTime.Equal(items[j].CreateTime) {
+			return items[i].Shorten < items[j].Shorten
+		}
+		return items[i].CreateTime.After(items[j].CreateTime)
+	})
+
+	if offset >= int64(len(items)) {
+		return nil, nil
+	}
+	items = items[offset:]
+	if limit < int64(len(items)) {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+func (m *BoltShorturlModel) CountByOwner(owner, keyword string) (int64, error) {
+	items, err := m.findByOwner(owner, keyword)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(items)), nil
+}
+
+func (m *BoltShorturlModel) Update(data Shorturl) error {
+	return m.db.Update(func(tx *bolt.Tx) error {
+		item, err := boltGet(tx, data.Shorten)
+		if err != nil || item == nil {
+			return err
+		}
+
+		if item.Owner != data.Owner {
+			if err := tx.Bucket(boltOwnerBucket).Delete(boltOwnerKey(item.Owner, item.Shorten)); err != nil {
+				return err
+			}
+		}
+
+		data.Clicks = item.Clicks
+		data.CreateTime = item.CreateTime
+		data.UpdateTime = boltNow()
+		return boltPut(tx, &data)
+	})
+}
+
+func (m *BoltShorturlModel) UpdateOwned(shorten, owner string, change ShorturlChange) error {
+	return m.db.Update(func(tx *bolt.Tx) error {
+		item, err := boltGet(tx, shorten)
+		if err != nil || item == nil || item.Owner != owner {
+			return err
+		}
+
+		if change.Url != nil {
+			item.Url = *change.Url
+		}
+		if change.Disabled != nil {
+			item.Disabled = *change.Disabled
+		}
+		if change.Rules != nil {
+			item.Rules = *change.Rules
+		}
+		item.UpdateTime = boltNow()
+		return boltPut(tx, item)
+	})
+}
+
+func (m *BoltShorturlModel) Restore(data []Shorturl) error {
+	now := boltNow()
+	return m.db.Update(func(tx *bolt.Tx) error {
+		for _, item := range data {
+			old, err := boltGet(tx, item.Shorten)
+			if err != nil {
+				return err
+			}
+			if old != nil && old.Owner != item.Owner {
+				if err := tx.Bucket(boltOwnerBucket).Delete(boltOwnerKey(old.Owner, old.Shorten)); err != nil {
+					return err
+				}
+			}
+
+			restoreTimes(&item, now)
+			item.CreateTime = item.CreateTime.UTC().Truncate(time.Second)
+			item.UpdateTime = item.UpdateTime.UTC().Truncate(time.Second)
+			if err := boltPut(tx, &item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *BoltShorturlModel) IncrClicks(shorten string) (bool, error) {
+	var counted bool
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		item, err := boltGet(tx, shorten)
+		if err != nil || item == nil {
+			return err
+		}
+
+		if item.MaxClicks > 0 && item.Clicks >= item.MaxClicks {
+			return nil
+		}
+
+		item.Clicks++
+		counted = true
+		return boltPut(tx, item)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return counted, nil
+}
+
+func (m *BoltShorturlModel) Delete(shorten string) error {
+	return m.db.Update(func(tx *bolt.Tx) error {
+		item, err := boltGet(tx, shorten)
+		if err != nil || item == nil {
+			return err
+		}
+
+		if err := tx.Bucket(boltOwnerBucket).Delete(boltOwnerKey(item.Owner, item.Shorten)); err != nil {
+			return err
+		}
+
+		return tx.Bucket(boltShorturlBucket).Delete([]byte(shorten))
+	})
+}
+
+func (m *BoltShorturlModel) findByOwner(owner, keyword string) ([]*Shorturl, error) {
+	var resp []*Shorturl
+	err := m.db.View(func(tx *bolt.Tx) error {
+		prefix := boltOwnerKey(owner, "")
+		cursor := tx.Bucket(boltOwnerBucket).Cursor()
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			item, err := boltGet(tx, string(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+			if item != nil && strings.Contains(item.Url, keyword) {
+				resp = append(resp, item)
+			}
+		}
+		return nil
+	})
+
+	return resp, err
+}
+
+func (r boltResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (r boltResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+func boltGet(tx *bolt.Tx, shorten string) (*Shorturl, error) {
+	data := tx.Bucket(boltShorturlBucket).Get([]byte(shorten))
+	if data == nil {
+		return nil, nil
+	}
+
+	var item Shorturl
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func boltPut(tx *bolt.Tx, item *Shorturl) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(boltShorturlBucket).Put([]byte(item.Shorten), data); err != nil {
+		return err
+	}
+
+	return tx.Bucket(boltOwnerBucket).Put(boltOwnerKey(item.Owner, item.Shorten), nil)
+}
+
+func boltOwnerKey(owner, shorten string) []byte {
+	return []byte(owner + "\x00" + shorten)
+}
+
+// boltNow returns the current time in the precision of sql timestamps.
+func boltNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
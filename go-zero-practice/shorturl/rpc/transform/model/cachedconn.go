@@ -0,0 +1,64 @@
+package model
+
+import (
+	"database/sql"
+
+	"github.com/tal-tech/go-zero/core/stores/sqlc"
+	"github.com/tal-tech/go-zero/core/stores/sqlx"
+)
+
+type (
+	// cachedConn is the part of sqlc.CachedConn that the sql model uses.
+	cachedConn interface {
+		DelCache(keys ...string) error
+		GetCache(key string, v interface{}) error
+		SetCache(key string, v interface{}) error
+		Exec(exec sqlc.ExecFn, keys ...string) (sql.Result, error)
+		ExecNoCache(q string, args ...interface{}) (sql.Result, error)
+		QueryRow(v interface{}, key string, query sqlc.QueryFn) error
+		QueryRowNoCache(v interface{}, q string, args ...interface{}) error
+		QueryRowsNoCache(v interface{}, q string, args ...interface{}) error
+		Transact(fn func(sqlx.Session) error) error
+	}
+
+	// noCacheConn runs everything on the database directly, used if no cache is configured.
+	noCacheConn struct {
+		db sqlx.SqlConn
+	}
+)
+
+func (c noCacheConn) DelCache(keys ...string) error {
+	return nil
+}
+
+func (c noCacheConn) GetCache(key string, v interface{}) error {
+	return sqlc.ErrNotFound
+}
+
+func (c noCacheConn) SetCache(key string, v interface{}) error {
+	return nil
+}
+
+func (c noCacheConn) Exec(exec sqlc.ExecFn, keys ...string) (sql.Result, error) {
+	return exec(c.db)
+}
+
+func (c noCacheConn) ExecNoCache(q string, args ...interface{}) (sql.Result, error) {
+	return c.db.Exec(q, args...)
+}
+
+func (c noCacheConn) QueryRow(v interface{}, key string, query sqlc.QueryFn) error {
+	return query(c.db, v)
+}
+
+func (c noCacheConn) QueryRowNoCache(v interface{}, q string, args ...interface{}) error {
+	return c.db.QueryRow(v, q, args...)
+}
+
+func (c noCacheConn) QueryRowsNoCache(v interface{}, q string, args ...interface{}) error {
+	return c.db.QueryRows(v, q, args...)
+}
+
+func (c noCacheConn) Transact(fn func(sqlx.Session) error) error {
+	return c.db.Transact(fn)
+}
@@ -0,0 +1,362 @@
+package model
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The conformance suite runs against every ShorturlModel implementation,
+// mysql and postgres are only tested if their data sources are given in the environment.
+
+func TestSqliteShorturlModel(t *testing.T) {
+	runConformance(t, func(t *testing.T) ShorturlModel {
+		return mustOpen(t, DriverSqlite, filepath.Join(t.TempDir(), "shorturl.db"))
+	})
+}
+
+func TestBoltShorturlModel(t *testing.T) {
+	runConformance(t, func(t *testing.T) ShorturlModel {
+		return mustOpen(t, DriverBolt, filepath.Join(t.TempDir(), "shorturl.bolt"))
+	})
+}
+
+func TestMysqlShorturlModel(t *testing.T) {
+	runConformance(t, openFromEnv(DriverMysql, "SHORTURL_MYSQL_DATASOURCE"))
+}
+
+func TestPostgresShorturlModel(t *testing.T) {
+	runConformance(t, openFromEnv(DriverPostgres, "SHORTURL_POSTGRES_DATASOURCE"))
+}
+
+func TestRebindPostgres(t *testing.T) {
+	got := rebindPostgres("select `url` from `shorturl` where `owner` = ? and `url` like ? limit ?")
+	want := `select "url" from "shorturl" where "owner" = $1 and "url" like $2 limit $3`
+	if got != want {
+		t.Errorf("rebindPostgres() = %s, want %s", got, want)
+	}
+}
+
+func openFromEnv(driver, env string) func(t *testing.T) ShorturlModel {
+	return func(t *testing.T) ShorturlModel {
+		dataSource := os.Getenv(env)
+		if len(dataSource) == 0 {
+			t.Skipf("%s is not set", env)
+		}
+
+		m := mustOpen(t, driver, dataSource)
+		// the shared databases are cleaned up by the test itself
+		t.Cleanup(func() {
+			for _, key := range []string{"k1", "k2", "k3", "k4", "k5"} {
+				m.Delete(key)
+			}
+		})
+		return m
+	}
+}
+
+func mustOpen(t *testing.T, driver, dataSource string) ShorturlModel {
+	m, err := Open(driver, dataSource, nil)
+	if err != nil {
+		t.Fatalf("Open(%s) error = %v", driver, err)
+	}
+	if closer, ok := m.(io.Closer); ok {
+		t.Cleanup(func() {
+			closer.Close()
+		})
+	}
+
+	return m
+}
+
+func runConformance(t *testing.T, newModel func(t *testing.T) ShorturlModel) {
+	t.Run("insert and find", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.Insert(Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1"}); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+		if _, err := m.Insert(Shorturl{Shorten: "k1", Url: "https://go.dev/doc"}); err == nil {
+			t.Error("Insert() duplicate key expected error")
+		}
+
+		item, err := m.FindOne("k1")
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if item.Url != "https://go.dev/" || item.Owner != "u1" || item.Disabled {
+			t.Errorf("FindOne() = %+v", item)
+		}
+		if item.CreateTime.IsZero() || item.UpdateTime.IsZero() {
+			t.Errorf("FindOne() timestamps not set: %+v", item)
+		}
+
+		if _, err := m.FindOne("missing"); err != ErrNotFound {
+			t.Errorf("FindOne() missing error = %v, want %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("insert many and find many", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.InsertMany([]Shorturl{
+			{Shorten: "k1", Url: "https://go.dev/1"},
+			{Shorten: "k2", Url: "https://go.dev/2"},
+		}); err != nil {
+			t.Fatalf("InsertMany() error = %v", err)
+		}
+
+		items, err := m.FindMany([]string{"k1", "missing", "k2"})
+		if err != nil {
+			t.Fatalf("FindMany() error = %v", err)
+		}
+		urls := make(map[string]string)
+		for _, item := range items {
+			urls[item.Shorten] = item.Url
+		}
+		if len(urls) != 2 || urls["k1"] != "https://go.dev/1" || urls["k2"] != "https://go.dev/2" {
+			t.Errorf("FindMany() = %v", urls)
+		}
+
+		if _, err := m.InsertMany([]Shorturl{
+			{Shorten: "k3", Url: "https://go.dev/3"},
+			{Shorten: "k1", Url: "https://go.dev/1"},
+		}); err == nil {
+			t.Error("InsertMany() duplicate key expected error")
+		}
+		if _, err := m.FindOne("k3"); err != ErrNotFound {
+			t.Errorf("InsertMany() failed batch left k3 behind, error = %v", err)
+		}
+	})
+
+	t.Run("insert many duplicates in batch", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.InsertMany([]Shorturl{
+			{Shorten: "k4", Url: "https://go.dev/4"},
+			{Shorten: "k5", Url: "https://go.dev/5"},
+			{Shorten: "k4", Url: "https://go.dev/other"},
+		}); err == nil {
+			t.Error("InsertMany() duplicate key in batch expected error")
+		}
+		for _, key := range []string{"k4", "k5"} {
+			if item, err := m.FindOne(key); err != ErrNotFound {
+				t.Errorf("InsertMany() failed batch left %s behind: %+v, %v", key, item, err)
+			}
+		}
+	})
+
+	t.Run("find after", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.InsertMany([]Shorturl{
+			{Shorten: "k3", Url: "https://go.dev/3"},
+			{Shorten: "k1", Url: "https://go.dev/1"},
+			{Shorten: "k2", Url: "https://go.dev/2"},
+		}); err != nil {
+			t.Fatalf("InsertMany() error = %v", err)
+		}
+
+		var keys []string
+		var last string
+		for {
+			items, err := m.FindAfter(last, 2)
+			if err != nil {
+				t.Fatalf("FindAfter() error = %v", err)
+			}
+			if len(items) == 0 {
+				break
+			}
+			for _, item := range items {
+				keys = append(keys, item.Shorten)
+			}
+			last = items[len(items)-1].Shorten
+		}
+		if strings.Join(keys, ",") != "k1,k2,k3" {
+			t.Errorf("FindAfter() pages = %v", keys)
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.Insert(Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1"}); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		if err := m.Update(Shorturl{Shorten: "k1", Url: "https://go.dev/blog", Owner: "u2", Disabled: true, Rules: `{"targets":[]}`}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		item, err := m.FindOne("k1")
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if item.Url != "https://go.dev/blog" || item.Owner != "u2" || !item.Disabled || item.Rules != `{"targets":[]}` {
+			t.Errorf("FindOne() after update = %+v", item)
+		}
+		if count, _ := m.CountByOwner("u1", ""); count != 0 {
+			t.Errorf("CountByOwner() previous owner = %d, want 0", count)
+		}
+
+		if err := m.Delete("k1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := m.FindOne("k1"); err != ErrNotFound {
+			t.Errorf("FindOne() after delete error = %v, want %v", err, ErrNotFound)
+		}
+		if count, _ := m.CountByOwner("u2", ""); count != 0 {
+			t.Errorf("CountByOwner() after delete = %d, want 0", count)
+		}
+	})
+
+	t.Run("update owned", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.Insert(Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1", Password: "hash", MaxClicks: 3}); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+		if _, err := m.IncrClicks("k1"); err != nil {
+			t.Fatalf("IncrClicks() error = %v", err)
+		}
+
+		// each change keeps the fields written by the others and the clicks
+		url, disabled, rules := "https://go.dev/blog", true, `{"targets":[]}`
+		for _, change := range []ShorturlChange{{Url: &url}, {Disabled: &disabled}, {Rules: &rules}} {
+			if err := m.UpdateOwned("k1", "u1", change); err != nil {
+				t.Fatalf("UpdateOwned(%+v) error = %v", change, err)
+			}
+		}
+		item, err := m.FindOne("k1")
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if item.Url != url || !item.Disabled || item.Rules != rules || item.Owner != "u1" ||
+			item.Password != "hash" || item.MaxClicks != 3 || item.Clicks != 1 {
+			t.Errorf("FindOne() after UpdateOwned = %+v", item)
+		}
+
+		// the links of other owners are left alone
+		other := "https://evil.test/"
+		if err := m.UpdateOwned("k1", "u2", ShorturlChange{Url: &other}); err != nil {
+			t.Fatalf("UpdateOwned() of another owner error = %v", err)
+		}
+		if item, err := m.FindOne("k1"); err != nil || item.Url != url {
+			t.Errorf("FindOne() after UpdateOwned of another owner = %+v, %v", item, err)
+		}
+		if err := m.UpdateOwned("missing", "u1", ShorturlChange{Url: &other}); err != nil {
+			t.Errorf("UpdateOwned() of a missing link error = %v", err)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.Insert(Shorturl{Shorten: "k1", Url: "https://go.dev/", Owner: "u1"}); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		updated := created.Add(time.Hour)
+		if err := m.Restore([]Shorturl{
+			{Shorten: "k1", Url: "https://go.dev/blog", Owner: "u2", Clicks: 5, CreateTime: created, UpdateTime: updated},
+			{Shorten: "k2", Url: "https://go.dev/doc", Clicks: 1},
+		}); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+
+		item, err := m.FindOne("k1")
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if item.Url != "https://go.dev/blog" || item.Owner != "u2" || item.Clicks != 5 ||
+			!item.CreateTime.Equal(created) || !item.UpdateTime.Equal(updated) {
+			t.Errorf("FindOne() after restore = %+v", item)
+		}
+		if count, _ := m.CountByOwner("u1", ""); count != 0 {
+			t.Errorf("CountByOwner() previous owner = %d, want 0", count)
+		}
+
+		item, err = m.FindOne("k2")
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if item.Clicks != 1 || item.CreateTime.IsZero() || item.UpdateTime.IsZero() {
+			t.Errorf("FindOne() of a new restored link = %+v", item)
+		}
+	})
+
+	t.Run("incr clicks", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.InsertMany([]Shorturl{
+			{Shorten: "k1", Url: "https://go.dev/", Password: "hash", MaxClicks: 2},
+			{Shorten: "k2", Url: "https://go.dev/"},
+		}); err != nil {
+			t.Fatalf("InsertMany() error = %v", err)
+		}
+
+		for i, want := range []bool{true, true, false} {
+			if counted, err := m.IncrClicks("k1"); err != nil || counted != want {
+				t.Errorf("IncrClicks() #%d = %v, %v, want %v", i, counted, err, want)
+			}
+		}
+		if counted, err := m.IncrClicks("k2"); err != nil || !counted {
+			t.Errorf("IncrClicks() unlimited = %v, %v", counted, err)
+		}
+		if counted, err := m.IncrClicks("missing"); err != nil || counted {
+			t.Errorf("IncrClicks() missing = %v, %v", counted, err)
+		}
+
+		// updates keep the clicks
+		if err := m.Update(Shorturl{Shorten: "k1", Url: "https://go.dev/doc", Password: "hash", MaxClicks: 2}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		item, err := m.FindOne("k1")
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if item.Clicks != 2 || item.MaxClicks != 2 || item.Password != "hash" {
+			t.Errorf("FindOne() = %+v", item)
+		}
+	})
+
+	t.Run("find by owner", func(t *testing.T) {
+		m := newModel(t)
+		if _, err := m.InsertMany([]Shorturl{
+			{Shorten: "k1", Url: "https://go.dev/a", Owner: "u1"},
+			{Shorten: "k2", Url: "https://go.dev/100%_b", Owner: "u1"},
+			{Shorten: "k3", Url: "https://go.dev/c", Owner: "u1"},
+			{Shorten: "k4", Url: "https://go.dev/a", Owner: "u2"},
+			{Shorten: "k5", Url: "https://go.dev/a", Owner: "u10"},
+		}); err != nil {
+			t.Fatalf("InsertMany() error = %v", err)
+		}
+
+		count, err := m.CountByOwner("u1", "")
+		if err != nil || count != 3 {
+			t.Errorf("CountByOwner() = %d, %v, want 3", count, err)
+		}
+
+		var keys []string
+		for offset := int64(0); offset < 4; offset += 2 {
+			items, err := m.FindByOwner("u1", "", offset, 2)
+			if err != nil {
+				t.Fatalf("FindByOwner() error = %v", err)
+			}
+			for i, item := range items {
+				if i > 0 && item.CreateTime.After(items[i-1].CreateTime) {
+					t.Errorf("FindByOwner() is not ordered by create time desc")
+				}
+				keys = append(keys, item.Shorten)
+			}
+		}
+		sort.Strings(keys)
+		if len(keys) != 3 || keys[0] != "k1" || keys[1] != "k2" || keys[2] != "k3" {
+			t.Errorf("FindByOwner() pages = %v", keys)
+		}
+
+		items, err := m.FindByOwner("u1", "%_", 0, 10)
+		if err != nil || len(items) != 1 || items[0].Shorten != "k2" {
+			t.Errorf("FindByOwner() with keyword = %v, %v", items, err)
+		}
+		if count, _ := m.CountByOwner("u1", "%_"); count != 1 {
+			t.Errorf("CountByOwner() with keyword = %d, want 1", count)
+		}
+	})
+}
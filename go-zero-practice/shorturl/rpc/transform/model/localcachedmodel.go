@@ -0,0 +1,112 @@
+package model
+
+import (
+	"database/sql"
+
+	"shorturl/common/localcache"
+)
+
+// localCachedShorturlModel keeps the hot links in process in front of
+// another ShorturlModel, the writes invalidate the keys on all instances.
+type localCachedShorturlModel struct {
+	ShorturlModel
+	cache *localcache.Cache
+}
+
+// NewLocalCachedShorturlModel returns a ShorturlModel that caches FindOne of m in process.
+func NewLocalCachedShorturlModel(m ShorturlModel, c localcache.Conf) ShorturlModel {
+	return &localCachedShorturlModel{
+		ShorturlModel: m,
+		cache: localcache.MustNew("shorturl", c, func(err error) bool {
+			return err == ErrNotFound
+		}),
+	}
+}
+
+func (m *localCachedShorturlModel) FindOne(shorten string) (*Shorturl, error) {
+	val, err := m.cache.Take(shorten, func() (interface{}, error) {
+		return m.ShorturlModel.FindOne(shorten)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// callers may modify the result, don't let them touch the cached one
+	item := *val.(*Shorturl)
+	return &item, nil
+}
+
+func (m *localCachedShorturlModel) Insert(data Shorturl) (sql.Result, error) {
+	res, err := m.ShorturlModel.Insert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the key may be cached as not found
+	m.cache.Invalidate(data.Shorten)
+	return res, nil
+}
+
+func (m *localCachedShorturlModel) InsertMany(data []Shorturl) (sql.Result, error) {
+	res, err := m.ShorturlModel.InsertMany(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for _, item := range data {
+		keys = append(keys, item.Shorten)
+	}
+	m.cache.Invalidate(keys...)
+	return res, nil
+}
+
+func (m *localCachedShorturlModel) Update(data Shorturl) error {
+	if err := m.ShorturlModel.Update(data); err != nil {
+		return err
+	}
+
+	m.cache.Invalidate(data.Shorten)
+	return nil
+}
+
+func (m *localCachedShorturlModel) UpdateOwned(shorten, owner string, change ShorturlChange) error {
+	if err := m.ShorturlModel.UpdateOwned(shorten, owner, change); err != nil {
+		return err
+	}
+
+	m.cache.Invalidate(shorten)
+	return nil
+}
+
+func (m *localCachedShorturlModel) Restore(data []Shorturl) error {
+	if err := m.ShorturlModel.Restore(data); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(data))
+	for _, item := range data {
+		keys = append(keys, item.Shorten)
+	}
+	m.cache.Invalidate(keys...)
+	return nil
+}
+
+func (m *localCachedShorturlModel) IncrClicks(shorten string) (bool, error) {
+	counted, err := m.ShorturlModel.IncrClicks(shorten)
+	if err != nil {
+		return false, err
+	}
+
+	m.cache.Invalidate(shorten)
+	return counted, nil
+}
+
+func (m *localCachedShorturlModel) Delete(shorten string) error {
+	if err := m.ShorturlModel.Delete(shorten); err != nil {
+		return err
+	}
+
+	m.cache.Invalidate(shorten)
+	return nil
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/tal-tech/go-zero/core/stores/sqlx"
+)
+
+type (
+	// postgresSession rewrites the mysql flavored queries of the model into postgres syntax.
+	postgresSession struct {
+		sqlx.Session
+	}
+
+	postgresConn struct {
+		postgresSession
+		conn sqlx.SqlConn
+	}
+)
+
+func newPostgresConn(conn sqlx.SqlConn) sqlx.SqlConn {
+	return postgresConn{
+		postgresSession: postgresSession{conn},
+		conn:            conn,
+	}
+}
+
+func (c postgresConn) Transact(fn func(sqlx.Session) error) error {
+	return c.conn.Transact(func(session sqlx.Session) error {
+		return fn(postgresSession{session})
+	})
+}
+
+func (s postgresSession) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.Session.Exec(rebindPostgres(query), args...)
+}
+
+func (s postgresSession) Prepare(query string) (sqlx.StmtSession, error) {
+	return s.Session.Prepare(rebindPostgres(query))
+}
+
+func (s postgresSession) QueryRow(v interface{}, query string, args ...interface{}) error {
+	return s.Session.QueryRow(v, rebindPostgres(query), args...)
+}
+
+func (s postgresSession) QueryRowPartial(v interface{}, query string, args ...interface{}) error {
+	return s.Session.QueryRowPartial(v, rebindPostgres(query), args...)
+}
+
+func (s postgresSession) QueryRows(v interface{}, query string, args ...interface{}) error {
+	return s.Session.QueryRows(v, rebindPostgres(query), args...)
+}
+
+func (s postgresSession) QueryRowsPartial(v interface{}, query string, args ...interface{}) error {
+	return s.Session.QueryRowsPartial(v, rebindPostgres(query), args...)
+}
+
+// rebindPostgres converts the ? placeholders into $n and the backquoted identifiers into double quoted ones.
+func rebindPostgres(query string) string {
+	var builder strings.Builder
+	var n int
+	for _, ch := range query {
+		switch ch {
+		case '?':
+			n++
+			builder.WriteByte('$')
+			builder.WriteString(strconv.Itoa(n))
+		case '`':
+			builder.WriteByte('"')
+		default:
+			builder.WriteRune(ch)
+		}
+	}
+
+	return builder.String()
+}
@@ -1,6 +1,15 @@
 CREATE TABLE `shorturl`
 (
   `shorten` varchar(255) NOT NULL COMMENT 'shorten key',
-  `url` varchar(255) NOT NULL COMMENT 'original url',
-  PRIMARY KEY(`shorten`)
+  `url` varchar(2048) NOT NULL COMMENT 'original url',
+  `owner` varchar(64) NOT NULL DEFAULT '' COMMENT 'owner id',
+  `disabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'whether the link is disabled',
+  `rules` text NOT NULL COMMENT 'targeting rules in json',
+  `password` varchar(255) NOT NULL DEFAULT '' COMMENT 'bcrypt hash of the password',
+  `max_clicks` bigint NOT NULL DEFAULT 0 COMMENT 'clicks allowed, 0 means unlimited',
+  `clicks` bigint NOT NULL DEFAULT 0 COMMENT 'clicks used',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY(`shorten`),
+  KEY `idx_owner_create_time` (`owner`, `create_time`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
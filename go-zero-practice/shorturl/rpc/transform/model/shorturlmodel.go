@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/tal-tech/go-zero/core/logx"
 	"github.com/tal-tech/go-zero/core/stores/cache"
 	"github.com/tal-tech/go-zero/core/stores/sqlc"
 	"github.com/tal-tech/go-zero/core/stores/sqlx"
@@ -13,47 +15,98 @@ import (
 )
 
 var (
-	shorturlFieldNames          = builderx.RawFieldNames(&Shorturl{})
-	shorturlRows                = strings.Join(shorturlFieldNames, ",")
-	shorturlRowsExpectAutoSet   = strings.Join(stringx.Remove(shorturlFieldNames, "`create_time`", "`update_time`"), ",")
-	shorturlRowsWithPlaceHolder = strings.Join(stringx.Remove(shorturlFieldNames, "`shorten`", "`create_time`", "`update_time`"), "=?,") + "=?"
+	shorturlFieldNames        = builderx.RawFieldNames(&Shorturl{})
+	shorturlRows              = strings.Join(shorturlFieldNames, ",")
+	shorturlRowsExpectAutoSet = strings.Join(stringx.Remove(shorturlFieldNames, "`create_time`", "`update_time`"), ",")
+	// clicks is only changed by IncrClicks, updates must not overwrite concurrent clicks
+	shorturlRowsWithPlaceHolder = strings.Join(stringx.Remove(shorturlFieldNames, "`shorten`", "`clicks`", "`create_time`", "`update_time`"), "=?,") + "=?"
 
 	cacheShorturlShortenPrefix = "cache#shorturl#shorten#"
+
+	likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
 )
 
 type (
 	ShorturlModel interface {
 		Insert(data Shorturl) (sql.Result, error)
+		InsertMany(data []Shorturl) (sql.Result, error)
 		FindOne(shorten string) (*Shorturl, error)
+		FindMany(shortens []string) ([]*Shorturl, error)
+		FindAfter(shorten string, limit int64) ([]*Shorturl, error)
+		FindByOwner(owner, keyword string, offset, limit int64) ([]*Shorturl, error)
+		CountByOwner(owner, keyword string) (int64, error)
 		Update(data Shorturl) error
+		UpdateOwned(shorten, owner string, change ShorturlChange) error
+		Restore(data []Shorturl) error
+		IncrClicks(shorten string) (bool, error)
 		Delete(shorten string) error
 	}
 
 	defaultShorturlModel struct {
-		sqlc.CachedConn
+		cachedConn
 		table string
 	}
 
 	Shorturl struct {
-		Shorten string `db:"shorten"` // shorten key
-		Url     string `db:"url"`     // original url
+		Shorten    string    `db:"shorten"`    // shorten key
+		Url        string    `db:"url"`        // original url
+		Owner      string    `db:"owner"`      // owner id
+		Disabled   bool      `db:"disabled"`   // whether the link is disabled
+		Rules      string    `db:"rules"`      // targeting rules in json
+		Password   string    `db:"password"`   // bcrypt hash of the password
+		MaxClicks  int64     `db:"max_clicks"` // clicks allowed, 0 means unlimited
+		Clicks     int64     `db:"clicks"`     // clicks used
+		CreateTime time.Time `db:"create_time"`
+		UpdateTime time.Time `db:"update_time"`
+	}
+
+	// ShorturlChange is a partial update of a link, the nil fields are kept.
+	ShorturlChange struct {
+		Url      *string
+		Disabled *bool
+		Rules    *string
 	}
 )
 
+// NewShorturlModel returns a ShorturlModel on conn, the cache is skipped if c is empty.
 func NewShorturlModel(conn sqlx.SqlConn, c cache.CacheConf) ShorturlModel {
+	var cc cachedConn = noCacheConn{db: conn}
+	if len(c) > 0 {
+		cc = sqlc.NewConn(conn, c)
+	}
+
 	return &defaultShorturlModel{
-		CachedConn: sqlc.NewConn(conn, c),
+		cachedConn: cc,
 		table:      "`shorturl`",
 	}
 }
 
 func (m *defaultShorturlModel) Insert(data Shorturl) (sql.Result, error) {
-	query := fmt.Sprintf("insert into %s (%s) values (?, ?)", m.table, shorturlRowsExpectAutoSet)
-	ret, err := m.ExecNoCache(query, data.Shorten, data.Url)
+	query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?)", m.table, shorturlRowsExpectAutoSet)
+	ret, err := m.ExecNoCache(query, data.Shorten, data.Url, data.Owner, data.Disabled, data.Rules,
+		data.Password, data.MaxClicks, data.Clicks)
 
 	return ret, err
 }
 
+func (m *defaultShorturlModel) InsertMany(data []Shorturl) (sql.Result, error) {
+	values := make([]string, 0, len(data))
+	args := make([]interface{}, 0, len(data)*8)
+	keys := make([]string, 0, len(data))
+	for _, item := range data {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, item.Shorten, item.Url, item.Owner, item.Disabled, item.Rules,
+			item.Password, item.MaxClicks, item.Clicks)
+		keys = append(keys, m.formatPrimary(item.Shorten))
+	}
+
+	// clear the not found placeholders that might be cached for the new keys
+	return m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("insert into %s (%s) values %s", m.table, shorturlRowsExpectAutoSet, strings.Join(values, ", "))
+		return conn.Exec(query, args...)
+	}, keys...)
+}
+
 func (m *defaultShorturlModel) FindOne(shorten string) (*Shorturl, error) {
 	shorturlShortenKey := fmt.Sprintf("%s%v", cacheShorturlShortenPrefix, shorten)
 	var resp Shorturl
@@ -71,15 +124,165 @@ func (m *defaultShorturlModel) FindOne(shorten string) (*Shorturl, error) {
 	}
 }
 
+func (m *defaultShorturlModel) FindMany(shortens []string) ([]*Shorturl, error) {
+	var resp []*Shorturl
+	var missed []interface{}
+	for _, shorten := range shortens {
+		var item Shorturl
+		switch err := m.GetCache(m.formatPrimary(shorten), &item); err {
+		case nil:
+			resp = append(resp, &item)
+		case sqlc.ErrNotFound:
+			missed = append(missed, shorten)
+		default:
+			return nil, err
+		}
+	}
+	if len(missed) == 0 {
+		return resp, nil
+	}
+
+	var rows []*Shorturl
+	query := fmt.Sprintf("select %s from %s where `shorten` in (%s)", shorturlRows, m.table,
+		strings.TrimSuffix(strings.Repeat("?, ", len(missed)), ", "))
+	if err := m.QueryRowsNoCache(&rows, query, missed...); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if err := m.SetCache(m.formatPrimary(row.Shorten), row); err != nil {
+			logx.Error(err)
+		}
+		resp = append(resp, row)
+	}
+
+	return resp, nil
+}
+
+// FindAfter returns the links whose keys come after shorten in key order,
+// it pages through all links with the last key of the previous page.
+func (m *defaultShorturlModel) FindAfter(shorten string, limit int64) ([]*Shorturl, error) {
+	query := fmt.Sprintf("select %s from %s where `shorten` > ? order by `shorten` limit ?", shorturlRows, m.table)
+	var resp []*Shorturl
+	if err := m.QueryRowsNoCache(&resp, query, shorten, limit); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (m *defaultShorturlModel) FindByOwner(owner, keyword string, offset, limit int64) ([]*Shorturl, error) {
+	cond, args := ownerCondition(owner, keyword)
+	query := fmt.Sprintf("select %s from %s where %s order by `create_time` desc, `shorten` limit ? offset ?",
+		shorturlRows, m.table, cond)
+	var resp []*Shorturl
+	if err := m.QueryRowsNoCache(&resp, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (m *defaultShorturlModel) CountByOwner(owner, keyword string) (int64, error) {
+	cond, args := ownerCondition(owner, keyword)
+	query := fmt.Sprintf("select count(*) from %s where %s", m.table, cond)
+	var count int64
+	if err := m.QueryRowNoCache(&count, query, args...); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (m *defaultShorturlModel) Update(data Shorturl) error {
 	shorturlShortenKey := fmt.Sprintf("%s%v", cacheShorturlShortenPrefix, data.Shorten)
 	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("update %s set %s where `shorten` = ?", m.table, shorturlRowsWithPlaceHolder)
-		return conn.Exec(query, data.Url, data.Shorten)
+		query := fmt.Sprintf("update %s set %s, `update_time` = CURRENT_TIMESTAMP where `shorten` = ?", m.table, shorturlRowsWithPlaceHolder)
+		return conn.Exec(query, data.Url, data.Owner, data.Disabled, data.Rules,
+			data.Password, data.MaxClicks, data.Shorten)
+	}, shorturlShortenKey)
+	return err
+}
+
+// UpdateOwned sets the fields of change of the link if it belongs to owner, it does nothing
+// otherwise. The other fields aren't written, so concurrent changes of them and the clicks are kept.
+func (m *defaultShorturlModel) UpdateOwned(shorten, owner string, change ShorturlChange) error {
+	var sets []string
+	var args []interface{}
+	if change.Url != nil {
+		sets = append(sets, "`url` = ?")
+		args = append(args, *change.Url)
+	}
+	if change.Disabled != nil {
+		sets = append(sets, "`disabled` = ?")
+		args = append(args, *change.Disabled)
+	}
+	if change.Rules != nil {
+		sets = append(sets, "`rules` = ?")
+		args = append(args, *change.Rules)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	shorturlShortenKey := fmt.Sprintf("%s%v", cacheShorturlShortenPrefix, shorten)
+	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set %s, `update_time` = CURRENT_TIMESTAMP where `shorten` = ? and `owner` = ?",
+			m.table, strings.Join(sets, ", "))
+		return conn.Exec(query, append(args, shorten, owner)...)
 	}, shorturlShortenKey)
 	return err
 }
 
+// Restore writes the links with all of their fields in one transaction, replacing
+// the links of the same keys. Unlike Insert and Update it keeps the clicks and
+// the times, zero times are set to now. It's used to import the exported links.
+func (m *defaultShorturlModel) Restore(data []Shorturl) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	keys := make([]string, 0, len(data))
+	for _, item := range data {
+		keys = append(keys, m.formatPrimary(item.Shorten))
+	}
+
+	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+		return nil, conn.Transact(func(session sqlx.Session) error {
+			del := fmt.Sprintf("delete from %s where `shorten` = ?", m.table)
+			insert := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, shorturlRows)
+			for _, item := range data {
+				restoreTimes(&item, now)
+				if _, err := session.Exec(del, item.Shorten); err != nil {
+					return err
+				}
+				if _, err := session.Exec(insert, item.Shorten, item.Url, item.Owner, item.Disabled, item.Rules,
+					item.Password, item.MaxClicks, item.Clicks, item.CreateTime, item.UpdateTime); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}, keys...)
+	return err
+}
+
+// IncrClicks counts a click of the link, it returns false if the link used up its clicks.
+func (m *defaultShorturlModel) IncrClicks(shorten string) (bool, error) {
+	shorturlShortenKey := fmt.Sprintf("%s%v", cacheShorturlShortenPrefix, shorten)
+	ret, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set `clicks` = `clicks` + 1 where `shorten` = ? and (`max_clicks` = 0 or `clicks` < `max_clicks`)", m.table)
+		return conn.Exec(query, shorten)
+	}, shorturlShortenKey)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
 func (m *defaultShorturlModel) Delete(shorten string) error {
 
 	shorturlShortenKey := fmt.Sprintf("%s%v", cacheShorturlShortenPrefix, shorten)
@@ -98,3 +301,13 @@ func (m *defaultShorturlModel) queryPrimary(conn sqlx.SqlConn, v, primary interf
 	query := fmt.Sprintf("select %s from %s where `shorten` = ? limit 1", shorturlRows, m.table)
 	return conn.QueryRow(v, query, primary)
 }
+
+// ownerCondition returns the where clause that matches the links of owner,
+// and whose url contains keyword if given.
+func ownerCondition(owner, keyword string) (string, []interface{}) {
+	if len(keyword) == 0 {
+		return "`owner` = ?", []interface{}{owner}
+	}
+
+	return "`owner` = ? and `url` like ? escape '!'", []interface{}{owner, "%" + likeEscaper.Replace(keyword) + "%"}
+}
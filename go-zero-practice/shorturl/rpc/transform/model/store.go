@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	// imports the sqlite driver.
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tal-tech/go-zero/core/stores/cache"
+	"github.com/tal-tech/go-zero/core/stores/postgres"
+	"github.com/tal-tech/go-zero/core/stores/sqlx"
+)
+
+const (
+	DriverMysql    = "mysql"
+	DriverSqlite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverBolt     = "bolt"
+
+	sqliteDriverName = "sqlite3"
+)
+
+var (
+	sqliteSchema = []string{
+		"CREATE TABLE IF NOT EXISTS `shorturl` (" +
+			"`shorten` varchar(255) NOT NULL PRIMARY KEY," +
+			"`url` varchar(2048) NOT NULL," +
+			"`owner` varchar(64) NOT NULL DEFAULT ''," +
+			"`disabled` boolean NOT NULL DEFAULT 0," +
+			"`rules` text NOT NULL DEFAULT ''," +
+			"`password` varchar(255) NOT NULL DEFAULT ''," +
+			"`max_clicks` bigint NOT NULL DEFAULT 0," +
+			"`clicks` bigint NOT NULL DEFAULT 0," +
+			"`create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"`update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP)",
+		"CREATE INDEX IF NOT EXISTS `idx_owner_create_time` ON `shorturl` (`owner`, `create_time`)",
+	}
+	postgresSchema = []string{
+		`CREATE TABLE IF NOT EXISTS "shorturl" (` +
+			`"shorten" varchar(255) NOT NULL PRIMARY KEY,` +
+			`"url" varchar(2048) NOT NULL,` +
+			`"owner" varchar(64) NOT NULL DEFAULT '',` +
+			`"disabled" boolean NOT NULL DEFAULT false,` +
+			`"rules" text NOT NULL DEFAULT '',` +
+			`"password" varchar(255) NOT NULL DEFAULT '',` +
+			`"max_clicks" bigint NOT NULL DEFAULT 0,` +
+			`"clicks" bigint NOT NULL DEFAULT 0,` +
+			`"create_time" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,` +
+			`"update_time" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE INDEX IF NOT EXISTS "idx_owner_create_time" ON "shorturl" ("owner", "create_time")`,
+	}
+)
+
+// Open returns a ShorturlModel that stores the links with driver in dataSource.
+// The schema is created if missing, except for mysql, which uses shorturl.sql.
+func Open(driver, dataSource string, c cache.CacheConf) (ShorturlModel, error) {
+	switch strings.ToLower(driver) {
+	case DriverMysql:
+		return NewShorturlModel(sqlx.NewMysql(dataSource), c), nil
+	case DriverSqlite:
+		conn := sqlx.NewSqlConn(sqliteDriverName, dataSource)
+		if err := createSchema(conn, sqliteSchema); err != nil {
+			return nil, err
+		}
+		return NewShorturlModel(conn, c), nil
+	case DriverPostgres:
+		conn := newPostgresConn(postgres.New(dataSource))
+		if err := createSchema(conn, postgresSchema); err != nil {
+			return nil, err
+		}
+		return NewShorturlModel(conn, c), nil
+	case DriverBolt:
+		m, err := NewBoltShorturlModel(dataSource)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unknown shorturl store driver: %s", driver)
+	}
+}
+
+// MustOpen returns a ShorturlModel like Open, exits on error.
+func MustOpen(driver, dataSource string, c cache.CacheConf) ShorturlModel {
+	m, err := Open(driver, dataSource, c)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func createSchema(conn sqlx.SqlConn, schema []string) error {
+	for _, stmt := range schema {
+		if _, err := conn.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,5 +1,24 @@
 package model
 
-import "github.com/tal-tech/go-zero/core/stores/sqlx"
+import (
+	"errors"
+	"time"
 
-var ErrNotFound = sqlx.ErrNotFound
+	"github.com/tal-tech/go-zero/core/stores/sqlx"
+)
+
+var (
+	ErrNotFound     = sqlx.ErrNotFound
+	ErrDuplicateKey = errors.New("shorten key already exists")
+)
+
+// restoreTimes sets the zero times of a restored link to now,
+// the files of other shorteners don't have them.
+func restoreTimes(item *Shorturl, now time.Time) {
+	if item.CreateTime.IsZero() {
+		item.CreateTime = now
+	}
+	if item.UpdateTime.IsZero() {
+		item.UpdateTime = now
+	}
+}
@@ -25,7 +25,12 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type ExpandReq struct {
-	Shorten              string   `protobuf:"bytes,1,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Shorten        string `protobuf:"bytes,1,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	UserAgent      string `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	AcceptLanguage string `protobuf:"bytes,3,opt,name=accept_language,json=acceptLanguage,proto3" json:"accept_language,omitempty"`
+	Password       string `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	// check only tells if the key resolves, without the password and without counting a click
+	Check                bool     `protobuf:"varint,5,opt,name=check,proto3" json:"check,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -63,8 +68,39 @@ func (m *ExpandReq) GetShorten() string {
 	return ""
 }
 
+func (m *ExpandReq) GetUserAgent() string {
+	if m != nil {
+		return m.UserAgent
+	}
+	return ""
+}
+
+func (m *ExpandReq) GetAcceptLanguage() string {
+	if m != nil {
+		return m.AcceptLanguage
+	}
+	return ""
+}
+
+func (m *ExpandReq) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *ExpandReq) GetCheck() bool {
+	if m != nil {
+		return m.Check
+	}
+	return false
+}
+
 type ExpandResp struct {
-	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Url     string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Variant string `protobuf:"bytes,2,opt,name=variant,proto3" json:"variant,omitempty"`
+	// cacheable is false if the url depends on the client
+	Cacheable            bool     `protobuf:"varint,3,opt,name=cacheable,proto3" json:"cacheable,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -102,8 +138,25 @@ func (m *ExpandResp) GetUrl() string {
 	return ""
 }
 
+func (m *ExpandResp) GetVariant() string {
+	if m != nil {
+		return m.Variant
+	}
+	return ""
+}
+
+func (m *ExpandResp) GetCacheable() bool {
+	if m != nil {
+		return m.Cacheable
+	}
+	return false
+}
+
 type ShortenReq struct {
 	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Password             string   `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	MaxClicks            int64    `protobuf:"varint,4,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -141,8 +194,31 @@ func (m *ShortenReq) GetUrl() string {
 	return ""
 }
 
+func (m *ShortenReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ShortenReq) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *ShortenReq) GetMaxClicks() int64 {
+	if m != nil {
+		return m.MaxClicks
+	}
+	return 0
+}
+
 type ShortenResp struct {
-	Shorten              string   `protobuf:"bytes,1,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Shorten string `protobuf:"bytes,1,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	// url is the normalized url that is stored
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -180,140 +256,1257 @@ func (m *ShortenResp) GetShorten() string {
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*ExpandReq)(nil), "transform.expandReq")
-	proto.RegisterType((*ExpandResp)(nil), "transform.expandResp")
-	proto.RegisterType((*ShortenReq)(nil), "transform.shortenReq")
-	proto.RegisterType((*ShortenResp)(nil), "transform.shortenResp")
+func (m *ShortenResp) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
 }
 
-func init() { proto.RegisterFile("transform.proto", fileDescriptor_cb4a498eeb2ba07d) }
+type BatchShortenReq struct {
+	Urls                 []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-var fileDescriptor_cb4a498eeb2ba07d = []byte{
-	// 163 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2f, 0x29, 0x4a, 0xcc,
-	0x2b, 0x4e, 0xcb, 0x2f, 0xca, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x84, 0x0b, 0x28,
-	0xa9, 0x72, 0x71, 0xa6, 0x56, 0x14, 0x24, 0xe6, 0xa5, 0x04, 0xa5, 0x16, 0x0a, 0x49, 0x70, 0xb1,
-	0x17, 0x67, 0xe4, 0x17, 0x95, 0xa4, 0xe6, 0x49, 0x30, 0x2a, 0x30, 0x6a, 0x70, 0x06, 0xc1, 0xb8,
-	0x4a, 0x72, 0x5c, 0x5c, 0x30, 0x65, 0xc5, 0x05, 0x42, 0x02, 0x5c, 0xcc, 0xa5, 0x45, 0x39, 0x50,
-	0x35, 0x20, 0x26, 0x48, 0x1e, 0xaa, 0x14, 0x64, 0x0e, 0xa6, 0xbc, 0x3a, 0x17, 0x37, 0x5c, 0xbe,
-	0xb8, 0x00, 0xb7, 0x45, 0x46, 0x75, 0x5c, 0xdc, 0x70, 0xc7, 0xa5, 0x16, 0x09, 0x99, 0x72, 0xb1,
-	0x41, 0xec, 0x15, 0x12, 0xd1, 0x43, 0xf8, 0x02, 0xee, 0x62, 0x29, 0x51, 0x2c, 0xa2, 0xc5, 0x05,
-	0x42, 0x16, 0x70, 0xf3, 0x85, 0x90, 0x55, 0x20, 0x9c, 0x28, 0x25, 0x86, 0x4d, 0xb8, 0xb8, 0x20,
-	0x89, 0x0d, 0x1c, 0x42, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x8a, 0x27, 0x78, 0x05, 0x34,
-	0x01, 0x00, 0x00,
+func (m *BatchShortenReq) Reset()         { *m = BatchShortenReq{} }
+func (m *BatchShortenReq) String() string { return proto.CompactTextString(m) }
+func (*BatchShortenReq) ProtoMessage()    {}
+func (*BatchShortenReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{4}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *BatchShortenReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchShortenReq.Unmarshal(m, b)
+}
+func (m *BatchShortenReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchShortenReq.Marshal(b, m, deterministic)
+}
+func (m *BatchShortenReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchShortenReq.Merge(m, src)
+}
+func (m *BatchShortenReq) XXX_Size() int {
+	return xxx_messageInfo_BatchShortenReq.Size(m)
+}
+func (m *BatchShortenReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchShortenReq.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_BatchShortenReq proto.InternalMessageInfo
 
-// TransformerClient is the client API for Transformer service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type TransformerClient interface {
-	Expand(ctx context.Context, in *ExpandReq, opts ...grpc.CallOption) (*ExpandResp, error)
-	Shorten(ctx context.Context, in *ShortenReq, opts ...grpc.CallOption) (*ShortenResp, error)
+func (m *BatchShortenReq) GetUrls() []string {
+	if m != nil {
+		return m.Urls
+	}
+	return nil
 }
 
-type transformerClient struct {
-	cc *grpc.ClientConn
+func (m *BatchShortenReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
 }
 
-func NewTransformerClient(cc *grpc.ClientConn) TransformerClient {
-	return &transformerClient{cc}
+type ShortenResult struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Shorten              string   `protobuf:"bytes,2,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *transformerClient) Expand(ctx context.Context, in *ExpandReq, opts ...grpc.CallOption) (*ExpandResp, error) {
-	out := new(ExpandResp)
-	err := c.cc.Invoke(ctx, "/transform.transformer/expand", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ShortenResult) Reset()         { *m = ShortenResult{} }
+func (m *ShortenResult) String() string { return proto.CompactTextString(m) }
+func (*ShortenResult) ProtoMessage()    {}
+func (*ShortenResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{5}
+}
+
+func (m *ShortenResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShortenResult.Unmarshal(m, b)
+}
+func (m *ShortenResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShortenResult.Marshal(b, m, deterministic)
+}
+func (m *ShortenResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShortenResult.Merge(m, src)
+}
+func (m *ShortenResult) XXX_Size() int {
+	return xxx_messageInfo_ShortenResult.Size(m)
+}
+func (m *ShortenResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShortenResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShortenResult proto.InternalMessageInfo
+
+func (m *ShortenResult) GetUrl() string {
+	if m != nil {
+		return m.Url
 	}
-	return out, nil
+	return ""
 }
 
-func (c *transformerClient) Shorten(ctx context.Context, in *ShortenReq, opts ...grpc.CallOption) (*ShortenResp, error) {
-	out := new(ShortenResp)
-	err := c.cc.Invoke(ctx, "/transform.transformer/shorten", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ShortenResult) GetShorten() string {
+	if m != nil {
+		return m.Shorten
 	}
-	return out, nil
+	return ""
 }
 
-// TransformerServer is the server API for Transformer service.
-type TransformerServer interface {
-	Expand(context.Context, *ExpandReq) (*ExpandResp, error)
-	Shorten(context.Context, *ShortenReq) (*ShortenResp, error)
+func (m *ShortenResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
 }
 
-// UnimplementedTransformerServer can be embedded to have forward compatible implementations.
-type UnimplementedTransformerServer struct {
+type BatchShortenResp struct {
+	Results              []*ShortenResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
-func (*UnimplementedTransformerServer) Expand(ctx context.Context, req *ExpandReq) (*ExpandResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Expand not implemented")
+func (m *BatchShortenResp) Reset()         { *m = BatchShortenResp{} }
+func (m *BatchShortenResp) String() string { return proto.CompactTextString(m) }
+func (*BatchShortenResp) ProtoMessage()    {}
+func (*BatchShortenResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{6}
 }
-func (*UnimplementedTransformerServer) Shorten(ctx context.Context, req *ShortenReq) (*ShortenResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Shorten not implemented")
+
+func (m *BatchShortenResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchShortenResp.Unmarshal(m, b)
+}
+func (m *BatchShortenResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchShortenResp.Marshal(b, m, deterministic)
+}
+func (m *BatchShortenResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchShortenResp.Merge(m, src)
+}
+func (m *BatchShortenResp) XXX_Size() int {
+	return xxx_messageInfo_BatchShortenResp.Size(m)
+}
+func (m *BatchShortenResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchShortenResp.DiscardUnknown(m)
 }
 
-func RegisterTransformerServer(s *grpc.Server, srv TransformerServer) {
-	s.RegisterService(&_Transformer_serviceDesc, srv)
+var xxx_messageInfo_BatchShortenResp proto.InternalMessageInfo
+
+func (m *BatchShortenResp) GetResults() []*ShortenResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
 }
 
-func _Transformer_Expand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ExpandReq)
-	if err := dec(in); err != nil {
-		return nil, err
+type BatchExpandReq struct {
+	Shortens []string `protobuf:"bytes,1,rep,name=shortens,proto3" json:"shortens,omitempty"`
+	// the client is matched against the targeting rules like in expand
+	UserAgent            string   `protobuf:"bytes,2,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	AcceptLanguage       string   `protobuf:"bytes,3,opt,name=accept_language,json=acceptLanguage,proto3" json:"accept_language,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchExpandReq) Reset()         { *m = BatchExpandReq{} }
+func (m *BatchExpandReq) String() string { return proto.CompactTextString(m) }
+func (*BatchExpandReq) ProtoMessage()    {}
+func (*BatchExpandReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{7}
+}
+
+func (m *BatchExpandReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchExpandReq.Unmarshal(m, b)
+}
+func (m *BatchExpandReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchExpandReq.Marshal(b, m, deterministic)
+}
+func (m *BatchExpandReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchExpandReq.Merge(m, src)
+}
+func (m *BatchExpandReq) XXX_Size() int {
+	return xxx_messageInfo_BatchExpandReq.Size(m)
+}
+func (m *BatchExpandReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchExpandReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchExpandReq proto.InternalMessageInfo
+
+func (m *BatchExpandReq) GetShortens() []string {
+	if m != nil {
+		return m.Shortens
 	}
-	if interceptor == nil {
-		return srv.(TransformerServer).Expand(ctx, in)
+	return nil
+}
+
+func (m *BatchExpandReq) GetUserAgent() string {
+	if m != nil {
+		return m.UserAgent
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/transform.transformer/Expand",
+	return ""
+}
+
+func (m *BatchExpandReq) GetAcceptLanguage() string {
+	if m != nil {
+		return m.AcceptLanguage
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransformerServer).Expand(ctx, req.(*ExpandReq))
+	return ""
+}
+
+type ExpandResult struct {
+	Shorten              string   `protobuf:"bytes,1,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Variant              string   `protobuf:"bytes,4,opt,name=variant,proto3" json:"variant,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExpandResult) Reset()         { *m = ExpandResult{} }
+func (m *ExpandResult) String() string { return proto.CompactTextString(m) }
+func (*ExpandResult) ProtoMessage()    {}
+func (*ExpandResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{8}
+}
+
+func (m *ExpandResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExpandResult.Unmarshal(m, b)
+}
+func (m *ExpandResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExpandResult.Marshal(b, m, deterministic)
+}
+func (m *ExpandResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExpandResult.Merge(m, src)
+}
+func (m *ExpandResult) XXX_Size() int {
+	return xxx_messageInfo_ExpandResult.Size(m)
+}
+func (m *ExpandResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExpandResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExpandResult proto.InternalMessageInfo
+
+func (m *ExpandResult) GetShorten() string {
+	if m != nil {
+		return m.Shorten
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _Transformer_Shorten_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ShortenReq)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *ExpandResult) GetUrl() string {
+	if m != nil {
+		return m.Url
 	}
-	if interceptor == nil {
-		return srv.(TransformerServer).Shorten(ctx, in)
+	return ""
+}
+
+func (m *ExpandResult) GetError() string {
+	if m != nil {
+		return m.Error
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/transform.transformer/Shorten",
+	return ""
+}
+
+func (m *ExpandResult) GetVariant() string {
+	if m != nil {
+		return m.Variant
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransformerServer).Shorten(ctx, req.(*ShortenReq))
+	return ""
+}
+
+type BatchExpandResp struct {
+	Results              []*ExpandResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *BatchExpandResp) Reset()         { *m = BatchExpandResp{} }
+func (m *BatchExpandResp) String() string { return proto.CompactTextString(m) }
+func (*BatchExpandResp) ProtoMessage()    {}
+func (*BatchExpandResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{9}
+}
+
+func (m *BatchExpandResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchExpandResp.Unmarshal(m, b)
+}
+func (m *BatchExpandResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchExpandResp.Marshal(b, m, deterministic)
+}
+func (m *BatchExpandResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchExpandResp.Merge(m, src)
+}
+func (m *BatchExpandResp) XXX_Size() int {
+	return xxx_messageInfo_BatchExpandResp.Size(m)
+}
+func (m *BatchExpandResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchExpandResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchExpandResp proto.InternalMessageInfo
+
+func (m *BatchExpandResp) GetResults() []*ExpandResult {
+	if m != nil {
+		return m.Results
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _Transformer_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "transform.transformer",
-	HandlerType: (*TransformerServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "expand",
-			Handler:    _Transformer_Expand_Handler,
-		},
-		{
-			MethodName: "shorten",
-			Handler:    _Transformer_Shorten_Handler,
+type Link struct {
+	Shorten              string   `protobuf:"bytes,1,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Owner                string   `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Disabled             bool     `protobuf:"varint,4,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	CreateTime           int64    `protobuf:"varint,5,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime           int64    `protobuf:"varint,6,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	Rules                string   `protobuf:"bytes,7,opt,name=rules,proto3" json:"rules,omitempty"`
+	Protected            bool     `protobuf:"varint,8,opt,name=protected,proto3" json:"protected,omitempty"`
+	MaxClicks            int64    `protobuf:"varint,9,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	Clicks               int64    `protobuf:"varint,10,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Link) Reset()         { *m = Link{} }
+func (m *Link) String() string { return proto.CompactTextString(m) }
+func (*Link) ProtoMessage()    {}
+func (*Link) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{10}
+}
+
+func (m *Link) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Link.Unmarshal(m, b)
+}
+func (m *Link) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Link.Marshal(b, m, deterministic)
+}
+func (m *Link) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Link.Merge(m, src)
+}
+func (m *Link) XXX_Size() int {
+	return xxx_messageInfo_Link.Size(m)
+}
+func (m *Link) XXX_DiscardUnknown() {
+	xxx_messageInfo_Link.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Link proto.InternalMessageInfo
+
+func (m *Link) GetShorten() string {
+	if m != nil {
+		return m.Shorten
+	}
+	return ""
+}
+
+func (m *Link) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Link) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Link) GetDisabled() bool {
+	if m != nil {
+		return m.Disabled
+	}
+	return false
+}
+
+func (m *Link) GetCreateTime() int64 {
+	if m != nil {
+		return m.CreateTime
+	}
+	return 0
+}
+
+func (m *Link) GetUpdateTime() int64 {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return 0
+}
+
+func (m *Link) GetRules() string {
+	if m != nil {
+		return m.Rules
+	}
+	return ""
+}
+
+func (m *Link) GetProtected() bool {
+	if m != nil {
+		return m.Protected
+	}
+	return false
+}
+
+func (m *Link) GetMaxClicks() int64 {
+	if m != nil {
+		return m.MaxClicks
+	}
+	return 0
+}
+
+func (m *Link) GetClicks() int64 {
+	if m != nil {
+		return m.Clicks
+	}
+	return 0
+}
+
+type LinkResp struct {
+	Link                 *Link    `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LinkResp) Reset()         { *m = LinkResp{} }
+func (m *LinkResp) String() string { return proto.CompactTextString(m) }
+func (*LinkResp) ProtoMessage()    {}
+func (*LinkResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{11}
+}
+
+func (m *LinkResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LinkResp.Unmarshal(m, b)
+}
+func (m *LinkResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LinkResp.Marshal(b, m, deterministic)
+}
+func (m *LinkResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LinkResp.Merge(m, src)
+}
+func (m *LinkResp) XXX_Size() int {
+	return xxx_messageInfo_LinkResp.Size(m)
+}
+func (m *LinkResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_LinkResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LinkResp proto.InternalMessageInfo
+
+func (m *LinkResp) GetLink() *Link {
+	if m != nil {
+		return m.Link
+	}
+	return nil
+}
+
+type ListLinksReq struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Keyword              string   `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Page                 int64    `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize             int64    `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLinksReq) Reset()         { *m = ListLinksReq{} }
+func (m *ListLinksReq) String() string { return proto.CompactTextString(m) }
+func (*ListLinksReq) ProtoMessage()    {}
+func (*ListLinksReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{12}
+}
+
+func (m *ListLinksReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLinksReq.Unmarshal(m, b)
+}
+func (m *ListLinksReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLinksReq.Marshal(b, m, deterministic)
+}
+func (m *ListLinksReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLinksReq.Merge(m, src)
+}
+func (m *ListLinksReq) XXX_Size() int {
+	return xxx_messageInfo_ListLinksReq.Size(m)
+}
+func (m *ListLinksReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLinksReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLinksReq proto.InternalMessageInfo
+
+func (m *ListLinksReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ListLinksReq) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *ListLinksReq) GetPage() int64 {
+	if m != nil {
+		return m.Page
+	}
+	return 0
+}
+
+func (m *ListLinksReq) GetPageSize() int64 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+type ListLinksResp struct {
+	Links                []*Link  `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	Total                int64    `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLinksResp) Reset()         { *m = ListLinksResp{} }
+func (m *ListLinksResp) String() string { return proto.CompactTextString(m) }
+func (*ListLinksResp) ProtoMessage()    {}
+func (*ListLinksResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{13}
+}
+
+func (m *ListLinksResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLinksResp.Unmarshal(m, b)
+}
+func (m *ListLinksResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLinksResp.Marshal(b, m, deterministic)
+}
+func (m *ListLinksResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLinksResp.Merge(m, src)
+}
+func (m *ListLinksResp) XXX_Size() int {
+	return xxx_messageInfo_ListLinksResp.Size(m)
+}
+func (m *ListLinksResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLinksResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLinksResp proto.InternalMessageInfo
+
+func (m *ListLinksResp) GetLinks() []*Link {
+	if m != nil {
+		return m.Links
+	}
+	return nil
+}
+
+func (m *ListLinksResp) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+type UpdateLinkReq struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Shorten              string   `protobuf:"bytes,2,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Url                  string   `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateLinkReq) Reset()         { *m = UpdateLinkReq{} }
+func (m *UpdateLinkReq) String() string { return proto.CompactTextString(m) }
+func (*UpdateLinkReq) ProtoMessage()    {}
+func (*UpdateLinkReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{14}
+}
+
+func (m *UpdateLinkReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateLinkReq.Unmarshal(m, b)
+}
+func (m *UpdateLinkReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateLinkReq.Marshal(b, m, deterministic)
+}
+func (m *UpdateLinkReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateLinkReq.Merge(m, src)
+}
+func (m *UpdateLinkReq) XXX_Size() int {
+	return xxx_messageInfo_UpdateLinkReq.Size(m)
+}
+func (m *UpdateLinkReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateLinkReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateLinkReq proto.InternalMessageInfo
+
+func (m *UpdateLinkReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *UpdateLinkReq) GetShorten() string {
+	if m != nil {
+		return m.Shorten
+	}
+	return ""
+}
+
+func (m *UpdateLinkReq) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+type SetLinkDisabledReq struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Shorten              string   `protobuf:"bytes,2,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Disabled             bool     `protobuf:"varint,3,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetLinkDisabledReq) Reset()         { *m = SetLinkDisabledReq{} }
+func (m *SetLinkDisabledReq) String() string { return proto.CompactTextString(m) }
+func (*SetLinkDisabledReq) ProtoMessage()    {}
+func (*SetLinkDisabledReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{15}
+}
+
+func (m *SetLinkDisabledReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLinkDisabledReq.Unmarshal(m, b)
+}
+func (m *SetLinkDisabledReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLinkDisabledReq.Marshal(b, m, deterministic)
+}
+func (m *SetLinkDisabledReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLinkDisabledReq.Merge(m, src)
+}
+func (m *SetLinkDisabledReq) XXX_Size() int {
+	return xxx_messageInfo_SetLinkDisabledReq.Size(m)
+}
+func (m *SetLinkDisabledReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLinkDisabledReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLinkDisabledReq proto.InternalMessageInfo
+
+func (m *SetLinkDisabledReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *SetLinkDisabledReq) GetShorten() string {
+	if m != nil {
+		return m.Shorten
+	}
+	return ""
+}
+
+func (m *SetLinkDisabledReq) GetDisabled() bool {
+	if m != nil {
+		return m.Disabled
+	}
+	return false
+}
+
+type SetLinkRulesReq struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Shorten              string   `protobuf:"bytes,2,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	Rules                string   `protobuf:"bytes,3,opt,name=rules,proto3" json:"rules,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetLinkRulesReq) Reset()         { *m = SetLinkRulesReq{} }
+func (m *SetLinkRulesReq) String() string { return proto.CompactTextString(m) }
+func (*SetLinkRulesReq) ProtoMessage()    {}
+func (*SetLinkRulesReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{16}
+}
+
+func (m *SetLinkRulesReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLinkRulesReq.Unmarshal(m, b)
+}
+func (m *SetLinkRulesReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLinkRulesReq.Marshal(b, m, deterministic)
+}
+func (m *SetLinkRulesReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLinkRulesReq.Merge(m, src)
+}
+func (m *SetLinkRulesReq) XXX_Size() int {
+	return xxx_messageInfo_SetLinkRulesReq.Size(m)
+}
+func (m *SetLinkRulesReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLinkRulesReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLinkRulesReq proto.InternalMessageInfo
+
+func (m *SetLinkRulesReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *SetLinkRulesReq) GetShorten() string {
+	if m != nil {
+		return m.Shorten
+	}
+	return ""
+}
+
+func (m *SetLinkRulesReq) GetRules() string {
+	if m != nil {
+		return m.Rules
+	}
+	return ""
+}
+
+type DeleteLinkReq struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Shorten              string   `protobuf:"bytes,2,opt,name=shorten,proto3" json:"shorten,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteLinkReq) Reset()         { *m = DeleteLinkReq{} }
+func (m *DeleteLinkReq) String() string { return proto.CompactTextString(m) }
+func (*DeleteLinkReq) ProtoMessage()    {}
+func (*DeleteLinkReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{17}
+}
+
+func (m *DeleteLinkReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteLinkReq.Unmarshal(m, b)
+}
+func (m *DeleteLinkReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteLinkReq.Marshal(b, m, deterministic)
+}
+func (m *DeleteLinkReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteLinkReq.Merge(m, src)
+}
+func (m *DeleteLinkReq) XXX_Size() int {
+	return xxx_messageInfo_DeleteLinkReq.Size(m)
+}
+func (m *DeleteLinkReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteLinkReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteLinkReq proto.InternalMessageInfo
+
+func (m *DeleteLinkReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *DeleteLinkReq) GetShorten() string {
+	if m != nil {
+		return m.Shorten
+	}
+	return ""
+}
+
+type DeleteLinkResp struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteLinkResp) Reset()         { *m = DeleteLinkResp{} }
+func (m *DeleteLinkResp) String() string { return proto.CompactTextString(m) }
+func (*DeleteLinkResp) ProtoMessage()    {}
+func (*DeleteLinkResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb4a498eeb2ba07d, []int{18}
+}
+
+func (m *DeleteLinkResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteLinkResp.Unmarshal(m, b)
+}
+func (m *DeleteLinkResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteLinkResp.Marshal(b, m, deterministic)
+}
+func (m *DeleteLinkResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteLinkResp.Merge(m, src)
+}
+func (m *DeleteLinkResp) XXX_Size() int {
+	return xxx_messageInfo_DeleteLinkResp.Size(m)
+}
+func (m *DeleteLinkResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteLinkResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteLinkResp proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*ExpandReq)(nil), "transform.expandReq")
+	proto.RegisterType((*ExpandResp)(nil), "transform.expandResp")
+	proto.RegisterType((*ShortenReq)(nil), "transform.shortenReq")
+	proto.RegisterType((*ShortenResp)(nil), "transform.shortenResp")
+	proto.RegisterType((*BatchShortenReq)(nil), "transform.batchShortenReq")
+	proto.RegisterType((*ShortenResult)(nil), "transform.shortenResult")
+	proto.RegisterType((*BatchShortenResp)(nil), "transform.batchShortenResp")
+	proto.RegisterType((*BatchExpandReq)(nil), "transform.batchExpandReq")
+	proto.RegisterType((*ExpandResult)(nil), "transform.expandResult")
+	proto.RegisterType((*BatchExpandResp)(nil), "transform.batchExpandResp")
+	proto.RegisterType((*Link)(nil), "transform.link")
+	proto.RegisterType((*LinkResp)(nil), "transform.linkResp")
+	proto.RegisterType((*ListLinksReq)(nil), "transform.listLinksReq")
+	proto.RegisterType((*ListLinksResp)(nil), "transform.listLinksResp")
+	proto.RegisterType((*UpdateLinkReq)(nil), "transform.updateLinkReq")
+	proto.RegisterType((*SetLinkDisabledReq)(nil), "transform.setLinkDisabledReq")
+	proto.RegisterType((*SetLinkRulesReq)(nil), "transform.setLinkRulesReq")
+	proto.RegisterType((*DeleteLinkReq)(nil), "transform.deleteLinkReq")
+	proto.RegisterType((*DeleteLinkResp)(nil), "transform.deleteLinkResp")
+}
+
+func init() { proto.RegisterFile("transform.proto", fileDescriptor_cb4a498eeb2ba07d) }
+
+var fileDescriptor_cb4a498eeb2ba07d = []byte{
+	// 819 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdd, 0x4e, 0xe3, 0x46,
+	0x14, 0x56, 0xe2, 0xfc, 0xd8, 0x27, 0x81, 0xa0, 0x69, 0x00, 0x63, 0x8a, 0x8a, 0xa6, 0xaa, 0xca,
+	0x15, 0x55, 0x53, 0x55, 0x6a, 0x85, 0x54, 0x84, 0x0a, 0xed, 0x4d, 0x6e, 0x6a, 0xaa, 0xf6, 0x32,
+	0x3b, 0x38, 0xb3, 0xc4, 0x1b, 0xc7, 0x1e, 0x66, 0x9c, 0x85, 0xe5, 0x2d, 0xf6, 0x09, 0xf6, 0xa9,
+	0xf6, 0x7d, 0x56, 0x33, 0x63, 0x8f, 0x7f, 0x70, 0x90, 0x40, 0x7b, 0x85, 0xcf, 0x0f, 0x67, 0xbe,
+	0xf3, 0xcd, 0xf7, 0x8d, 0x02, 0xa3, 0x94, 0x93, 0x58, 0xbc, 0x4d, 0xf8, 0xea, 0x94, 0xf1, 0x24,
+	0x4d, 0x90, 0x63, 0x12, 0xf8, 0x53, 0x0b, 0x1c, 0xfa, 0xc0, 0x48, 0x3c, 0xf7, 0xe9, 0x1d, 0x72,
+	0xa1, 0x2f, 0x16, 0x09, 0x4f, 0x69, 0xec, 0xb6, 0x8e, 0x5b, 0x27, 0x8e, 0x9f, 0x87, 0xe8, 0x08,
+	0x60, 0x2d, 0x28, 0x9f, 0x91, 0x5b, 0x1a, 0xa7, 0x6e, 0x5b, 0x15, 0x1d, 0x99, 0xb9, 0x90, 0x09,
+	0xf4, 0x23, 0x8c, 0x48, 0x10, 0x50, 0x96, 0xce, 0x22, 0x12, 0xdf, 0xae, 0xc9, 0x2d, 0x75, 0x2d,
+	0xd5, 0xb3, 0xad, 0xd3, 0xd3, 0x2c, 0x8b, 0x3c, 0xb0, 0x19, 0x11, 0xe2, 0x3e, 0xe1, 0x73, 0xb7,
+	0xa3, 0x3a, 0x4c, 0x8c, 0xc6, 0xd0, 0x0d, 0x16, 0x34, 0x58, 0xba, 0xdd, 0xe3, 0xd6, 0x89, 0xed,
+	0xeb, 0x00, 0xff, 0x07, 0x90, 0x03, 0x14, 0x0c, 0xed, 0x80, 0xb5, 0xe6, 0x51, 0x86, 0x4e, 0x7e,
+	0x4a, 0xcc, 0xef, 0x09, 0x0f, 0x89, 0x81, 0x95, 0x87, 0xe8, 0x5b, 0x70, 0x02, 0x12, 0x2c, 0x28,
+	0xb9, 0x89, 0x34, 0x1c, 0xdb, 0x2f, 0x12, 0x38, 0x01, 0xc8, 0x96, 0x93, 0x9b, 0x3f, 0x9d, 0x3b,
+	0x86, 0x6e, 0x72, 0x1f, 0x53, 0x9e, 0x4d, 0xd5, 0x41, 0x05, 0xbf, 0x55, 0xc3, 0x7f, 0x04, 0xb0,
+	0x22, 0x0f, 0xb3, 0x20, 0x0a, 0x83, 0xa5, 0x50, 0xdb, 0x59, 0xbe, 0xb3, 0x22, 0x0f, 0x7f, 0xaa,
+	0x04, 0xfe, 0x1d, 0x06, 0xe6, 0x40, 0xc1, 0x9e, 0xe1, 0x3a, 0xc3, 0xd2, 0x36, 0x58, 0xf0, 0x19,
+	0x8c, 0x6e, 0x48, 0x1a, 0x2c, 0xae, 0x0b, 0xc0, 0x08, 0x3a, 0x6b, 0x1e, 0x09, 0xb7, 0x75, 0x6c,
+	0x9d, 0x38, 0xbe, 0xfa, 0x6e, 0x86, 0x8c, 0xff, 0x81, 0xad, 0xe2, 0xdc, 0x75, 0x94, 0x36, 0x73,
+	0x98, 0x63, 0x69, 0x57, 0xb1, 0x8c, 0xa1, 0x4b, 0x39, 0x4f, 0x78, 0xb6, 0xac, 0x0e, 0xf0, 0x5f,
+	0xb0, 0x53, 0xc5, 0x23, 0x18, 0x9a, 0x40, 0x9f, 0xab, 0xf9, 0x1a, 0xd3, 0x60, 0xe2, 0x9e, 0x16,
+	0xba, 0xab, 0x00, 0xf0, 0xf3, 0x46, 0x9c, 0xc2, 0xb6, 0x9a, 0x73, 0x65, 0x14, 0xe8, 0x81, 0x9d,
+	0xf5, 0xe6, 0xab, 0x99, 0xf8, 0x6b, 0x69, 0x10, 0xbf, 0x83, 0xa1, 0x51, 0x94, 0xe4, 0xe3, 0x05,
+	0x37, 0xd1, 0xcc, 0x47, 0x59, 0x83, 0x9d, 0x8a, 0x06, 0xf1, 0x25, 0x8c, 0x2a, 0x1b, 0x0a, 0x86,
+	0x7e, 0xae, 0x13, 0xb5, 0x5f, 0x22, 0xaa, 0x0c, 0xac, 0xe0, 0xe9, 0x63, 0x1b, 0x3a, 0x51, 0x18,
+	0x2f, 0x5f, 0x0a, 0x55, 0xab, 0xc1, 0xaa, 0x09, 0x78, 0x1e, 0x0a, 0xe9, 0x00, 0x6d, 0x40, 0xdb,
+	0x37, 0x31, 0xfa, 0x0e, 0x06, 0x01, 0xa7, 0x24, 0xa5, 0xb3, 0x34, 0x5c, 0x51, 0x65, 0x43, 0xcb,
+	0x07, 0x9d, 0xfa, 0x37, 0x5c, 0x51, 0xd9, 0xb0, 0x66, 0x73, 0xd3, 0xd0, 0xd3, 0x0d, 0x3a, 0xa5,
+	0x1a, 0xc6, 0xd0, 0xe5, 0xeb, 0x88, 0x0a, 0xb7, 0xaf, 0xcf, 0x54, 0x81, 0x34, 0xa2, 0x7c, 0x78,
+	0x68, 0x90, 0xd2, 0xb9, 0x6b, 0x6b, 0x23, 0x9a, 0x44, 0xcd, 0x36, 0x4e, 0xcd, 0x36, 0x68, 0x0f,
+	0x7a, 0x59, 0x09, 0x54, 0x29, 0x8b, 0xf0, 0x4f, 0x60, 0x4b, 0x4a, 0x14, 0xa5, 0xdf, 0x6b, 0x7a,
+	0x14, 0x27, 0x83, 0xc9, 0xa8, 0xc4, 0xa7, 0x6a, 0x51, 0x45, 0x9c, 0xc0, 0x30, 0x0a, 0x45, 0x3a,
+	0x0d, 0xe3, 0xa5, 0x90, 0x52, 0x33, 0xfc, 0xb4, 0xca, 0xfc, 0xb8, 0xd0, 0x5f, 0xd2, 0x0f, 0xca,
+	0xdf, 0x99, 0x15, 0xb2, 0x50, 0x3a, 0x8e, 0xe5, 0xa2, 0xb2, 0x7c, 0xf5, 0x8d, 0x0e, 0xc1, 0x91,
+	0x7f, 0x67, 0x22, 0x7c, 0xa4, 0x99, 0xe3, 0x6d, 0x99, 0xb8, 0x0e, 0x1f, 0x29, 0x9e, 0xc2, 0x56,
+	0xe9, 0x40, 0xc1, 0xd0, 0x0f, 0xd0, 0x95, 0x48, 0xf2, 0x7b, 0x7f, 0x82, 0x53, 0x57, 0x25, 0xb0,
+	0x34, 0x49, 0x89, 0xbe, 0x4c, 0xcb, 0xd7, 0x81, 0xb4, 0xb1, 0x26, 0x7a, 0xaa, 0xb6, 0x7e, 0x06,
+	0xff, 0x06, 0x2b, 0x67, 0x0a, 0xb1, 0x8a, 0x67, 0xe5, 0x0d, 0x20, 0x41, 0x15, 0xbe, 0xcb, 0x4c,
+	0x02, 0xaf, 0x99, 0x5b, 0x56, 0x94, 0x55, 0x55, 0x14, 0xfe, 0x1f, 0x46, 0xd9, 0x09, 0xbe, 0x54,
+	0xc2, 0x6b, 0xc6, 0x1b, 0x49, 0x59, 0x25, 0x49, 0xe1, 0x73, 0xd8, 0x9a, 0xd3, 0x88, 0xbe, 0x9a,
+	0x0d, 0xbc, 0x03, 0xdb, 0xe5, 0x01, 0x82, 0x4d, 0x3e, 0x77, 0x60, 0x60, 0x2e, 0x84, 0x72, 0xf4,
+	0x2b, 0xf4, 0xb4, 0x1b, 0xd1, 0xb8, 0xc1, 0xa0, 0x77, 0xde, 0x6e, 0x43, 0x56, 0x30, 0xf4, 0x9b,
+	0x39, 0x12, 0xed, 0x36, 0xbd, 0x80, 0x77, 0xde, 0x5e, 0x53, 0x5a, 0x30, 0xf4, 0x37, 0x0c, 0xcb,
+	0xaf, 0x2a, 0xf2, 0x4a, 0x7d, 0xb5, 0xe7, 0xdf, 0x3b, 0xdc, 0x58, 0x13, 0x0c, 0x5d, 0xc2, 0xa0,
+	0xf4, 0xe8, 0xa0, 0x83, 0x7a, 0xaf, 0x79, 0x6e, 0x3d, 0x6f, 0x53, 0x49, 0x30, 0xf4, 0x07, 0x38,
+	0x46, 0xbe, 0x68, 0xbf, 0xa2, 0xd5, 0xc2, 0x45, 0x9e, 0xdb, 0x5c, 0x10, 0x0c, 0x9d, 0x01, 0x14,
+	0x82, 0x45, 0xe5, 0xbe, 0x8a, 0x8e, 0xbd, 0x6f, 0xea, 0x36, 0x90, 0xff, 0x7c, 0x05, 0xa3, 0x9a,
+	0x34, 0xd1, 0x51, 0x99, 0xb6, 0x27, 0xb2, 0x6d, 0x1e, 0x73, 0x0e, 0xc3, 0xb2, 0xfe, 0x2a, 0x94,
+	0xd6, 0x84, 0xd9, 0x3c, 0xe0, 0x02, 0xa0, 0x90, 0x49, 0x65, 0x89, 0x8a, 0xfc, 0xbc, 0x83, 0x0d,
+	0x15, 0xc1, 0x6e, 0x7a, 0xea, 0x47, 0xd7, 0x2f, 0x5f, 0x06, 0x00, 0xe6, 0x2d, 0x42, 0xeb, 0x87,
+	0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TransformerClient is the client API for Transformer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TransformerClient interface {
+	Expand(ctx context.Context, in *ExpandReq, opts ...grpc.CallOption) (*ExpandResp, error)
+	Shorten(ctx context.Context, in *ShortenReq, opts ...grpc.CallOption) (*ShortenResp, error)
+	BatchShorten(ctx context.Context, in *BatchShortenReq, opts ...grpc.CallOption) (*BatchShortenResp, error)
+	BatchExpand(ctx context.Context, in *BatchExpandReq, opts ...grpc.CallOption) (*BatchExpandResp, error)
+	ListLinks(ctx context.Context, in *ListLinksReq, opts ...grpc.CallOption) (*ListLinksResp, error)
+	UpdateLink(ctx context.Context, in *UpdateLinkReq, opts ...grpc.CallOption) (*LinkResp, error)
+	SetLinkDisabled(ctx context.Context, in *SetLinkDisabledReq, opts ...grpc.CallOption) (*LinkResp, error)
+	SetLinkRules(ctx context.Context, in *SetLinkRulesReq, opts ...grpc.CallOption) (*LinkResp, error)
+	DeleteLink(ctx context.Context, in *DeleteLinkReq, opts ...grpc.CallOption) (*DeleteLinkResp, error)
+}
+
+type transformerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTransformerClient(cc *grpc.ClientConn) TransformerClient {
+	return &transformerClient{cc}
+}
+
+func (c *transformerClient) Expand(ctx context.Context, in *ExpandReq, opts ...grpc.CallOption) (*ExpandResp, error) {
+	out := new(ExpandResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/expand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) Shorten(ctx context.Context, in *ShortenReq, opts ...grpc.CallOption) (*ShortenResp, error) {
+	out := new(ShortenResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/shorten", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) BatchShorten(ctx context.Context, in *BatchShortenReq, opts ...grpc.CallOption) (*BatchShortenResp, error) {
+	out := new(BatchShortenResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/batchShorten", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) BatchExpand(ctx context.Context, in *BatchExpandReq, opts ...grpc.CallOption) (*BatchExpandResp, error) {
+	out := new(BatchExpandResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/batchExpand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) ListLinks(ctx context.Context, in *ListLinksReq, opts ...grpc.CallOption) (*ListLinksResp, error) {
+	out := new(ListLinksResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/listLinks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) UpdateLink(ctx context.Context, in *UpdateLinkReq, opts ...grpc.CallOption) (*LinkResp, error) {
+	out := new(LinkResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/updateLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) SetLinkDisabled(ctx context.Context, in *SetLinkDisabledReq, opts ...grpc.CallOption) (*LinkResp, error) {
+	out := new(LinkResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/setLinkDisabled", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) SetLinkRules(ctx context.Context, in *SetLinkRulesReq, opts ...grpc.CallOption) (*LinkResp, error) {
+	out := new(LinkResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/setLinkRules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transformerClient) DeleteLink(ctx context.Context, in *DeleteLinkReq, opts ...grpc.CallOption) (*DeleteLinkResp, error) {
+	out := new(DeleteLinkResp)
+	err := c.cc.Invoke(ctx, "/transform.transformer/deleteLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransformerServer is the server API for Transformer service.
+type TransformerServer interface {
+	Expand(context.Context, *ExpandReq) (*ExpandResp, error)
+	Shorten(context.Context, *ShortenReq) (*ShortenResp, error)
+	BatchShorten(context.Context, *BatchShortenReq) (*BatchShortenResp, error)
+	BatchExpand(context.Context, *BatchExpandReq) (*BatchExpandResp, error)
+	ListLinks(context.Context, *ListLinksReq) (*ListLinksResp, error)
+	UpdateLink(context.Context, *UpdateLinkReq) (*LinkResp, error)
+	SetLinkDisabled(context.Context, *SetLinkDisabledReq) (*LinkResp, error)
+	SetLinkRules(context.Context, *SetLinkRulesReq) (*LinkResp, error)
+	DeleteLink(context.Context, *DeleteLinkReq) (*DeleteLinkResp, error)
+}
+
+// UnimplementedTransformerServer can be embedded to have forward compatible implementations.
+type UnimplementedTransformerServer struct {
+}
+
+func (*UnimplementedTransformerServer) Expand(ctx context.Context, req *ExpandReq) (*ExpandResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expand not implemented")
+}
+func (*UnimplementedTransformerServer) Shorten(ctx context.Context, req *ShortenReq) (*ShortenResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shorten not implemented")
+}
+func (*UnimplementedTransformerServer) BatchShorten(ctx context.Context, req *BatchShortenReq) (*BatchShortenResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchShorten not implemented")
+}
+func (*UnimplementedTransformerServer) BatchExpand(ctx context.Context, req *BatchExpandReq) (*BatchExpandResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchExpand not implemented")
+}
+func (*UnimplementedTransformerServer) ListLinks(ctx context.Context, req *ListLinksReq) (*ListLinksResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLinks not implemented")
+}
+func (*UnimplementedTransformerServer) UpdateLink(ctx context.Context, req *UpdateLinkReq) (*LinkResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLink not implemented")
+}
+func (*UnimplementedTransformerServer) SetLinkDisabled(ctx context.Context, req *SetLinkDisabledReq) (*LinkResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLinkDisabled not implemented")
+}
+func (*UnimplementedTransformerServer) SetLinkRules(ctx context.Context, req *SetLinkRulesReq) (*LinkResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLinkRules not implemented")
+}
+func (*UnimplementedTransformerServer) DeleteLink(ctx context.Context, req *DeleteLinkReq) (*DeleteLinkResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLink not implemented")
+}
+
+func RegisterTransformerServer(s *grpc.Server, srv TransformerServer) {
+	s.RegisterService(&_Transformer_serviceDesc, srv)
+}
+
+func _Transformer_Expand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpandReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).Expand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/Expand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).Expand(ctx, req.(*ExpandReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_Shorten_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortenReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).Shorten(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/Shorten",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).Shorten(ctx, req.(*ShortenReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_BatchShorten_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchShortenReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).BatchShorten(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/BatchShorten",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).BatchShorten(ctx, req.(*BatchShortenReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_BatchExpand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchExpandReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).BatchExpand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/BatchExpand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).BatchExpand(ctx, req.(*BatchExpandReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_ListLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLinksReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).ListLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/ListLinks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).ListLinks(ctx, req.(*ListLinksReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_UpdateLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLinkReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).UpdateLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/UpdateLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).UpdateLink(ctx, req.(*UpdateLinkReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_SetLinkDisabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLinkDisabledReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).SetLinkDisabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/SetLinkDisabled",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).SetLinkDisabled(ctx, req.(*SetLinkDisabledReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_SetLinkRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLinkRulesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).SetLinkRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/SetLinkRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).SetLinkRules(ctx, req.(*SetLinkRulesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transformer_DeleteLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLinkReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformerServer).DeleteLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/transform.transformer/DeleteLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformerServer).DeleteLink(ctx, req.(*DeleteLinkReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Transformer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "transform.transformer",
+	HandlerType: (*TransformerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "expand",
+			Handler:    _Transformer_Expand_Handler,
+		},
+		{
+			MethodName: "shorten",
+			Handler:    _Transformer_Shorten_Handler,
+		},
+		{
+			MethodName: "batchShorten",
+			Handler:    _Transformer_BatchShorten_Handler,
+		},
+		{
+			MethodName: "batchExpand",
+			Handler:    _Transformer_BatchExpand_Handler,
+		},
+		{
+			MethodName: "listLinks",
+			Handler:    _Transformer_ListLinks_Handler,
+		},
+		{
+			MethodName: "updateLink",
+			Handler:    _Transformer_UpdateLink_Handler,
+		},
+		{
+			MethodName: "setLinkDisabled",
+			Handler:    _Transformer_SetLinkDisabled_Handler,
+		},
+		{
+			MethodName: "setLinkRules",
+			Handler:    _Transformer_SetLinkRules_Handler,
+		},
+		{
+			MethodName: "deleteLink",
+			Handler:    _Transformer_DeleteLink_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
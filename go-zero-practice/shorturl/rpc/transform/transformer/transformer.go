@@ -14,14 +14,36 @@ import (
 )
 
 type (
-	ExpandReq   = transform.ExpandReq
-	ExpandResp  = transform.ExpandResp
-	ShortenReq  = transform.ShortenReq
-	ShortenResp = transform.ShortenResp
+	ExpandReq          = transform.ExpandReq
+	ExpandResp         = transform.ExpandResp
+	ShortenReq         = transform.ShortenReq
+	ShortenResp        = transform.ShortenResp
+	BatchShortenReq    = transform.BatchShortenReq
+	ShortenResult      = transform.ShortenResult
+	BatchShortenResp   = transform.BatchShortenResp
+	BatchExpandReq     = transform.BatchExpandReq
+	ExpandResult       = transform.ExpandResult
+	BatchExpandResp    = transform.BatchExpandResp
+	Link               = transform.Link
+	LinkResp           = transform.LinkResp
+	ListLinksReq       = transform.ListLinksReq
+	ListLinksResp      = transform.ListLinksResp
+	UpdateLinkReq      = transform.UpdateLinkReq
+	SetLinkDisabledReq = transform.SetLinkDisabledReq
+	SetLinkRulesReq    = transform.SetLinkRulesReq
+	DeleteLinkReq      = transform.DeleteLinkReq
+	DeleteLinkResp     = transform.DeleteLinkResp
 
 	Transformer interface {
 		Expand(ctx context.Context, in *ExpandReq) (*ExpandResp, error)
 		Shorten(ctx context.Context, in *ShortenReq) (*ShortenResp, error)
+		BatchShorten(ctx context.Context, in *BatchShortenReq) (*BatchShortenResp, error)
+		BatchExpand(ctx context.Context, in *BatchExpandReq) (*BatchExpandResp, error)
+		ListLinks(ctx context.Context, in *ListLinksReq) (*ListLinksResp, error)
+		UpdateLink(ctx context.Context, in *UpdateLinkReq) (*LinkResp, error)
+		SetLinkDisabled(ctx context.Context, in *SetLinkDisabledReq) (*LinkResp, error)
+		SetLinkRules(ctx context.Context, in *SetLinkRulesReq) (*LinkResp, error)
+		DeleteLink(ctx context.Context, in *DeleteLinkReq) (*DeleteLinkResp, error)
 	}
 
 	defaultTransformer struct {
@@ -44,3 +66,38 @@ func (m *defaultTransformer) Shorten(ctx context.Context, in *ShortenReq) (*Shor
 	client := transform.NewTransformerClient(m.cli.Conn())
 	return client.Shorten(ctx, in)
 }
+
+func (m *defaultTransformer) BatchShorten(ctx context.Context, in *BatchShortenReq) (*BatchShortenResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.BatchShorten(ctx, in)
+}
+
+func (m *defaultTransformer) BatchExpand(ctx context.Context, in *BatchExpandReq) (*BatchExpandResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.BatchExpand(ctx, in)
+}
+
+func (m *defaultTransformer) ListLinks(ctx context.Context, in *ListLinksReq) (*ListLinksResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.ListLinks(ctx, in)
+}
+
+func (m *defaultTransformer) UpdateLink(ctx context.Context, in *UpdateLinkReq) (*LinkResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.UpdateLink(ctx, in)
+}
+
+func (m *defaultTransformer) SetLinkDisabled(ctx context.Context, in *SetLinkDisabledReq) (*LinkResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.SetLinkDisabled(ctx, in)
+}
+
+func (m *defaultTransformer) SetLinkRules(ctx context.Context, in *SetLinkRulesReq) (*LinkResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.SetLinkRules(ctx, in)
+}
+
+func (m *defaultTransformer) DeleteLink(ctx context.Context, in *DeleteLinkReq) (*DeleteLinkResp, error) {
+	client := transform.NewTransformerClient(m.cli.Conn())
+	return client.DeleteLink(ctx, in)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"admission-registry/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+// 离线测试规则文件，例如：
+//
+//	go run ./cmd/rules -rules manifests/rules.yaml -f deploy/test-pod1.yaml
+//
+// 输出 mutate 之后的对象、patch 和违反的校验规则，有违反的规则时退出码为 1
+func main() {
+	rulesFile := flag.String("rules", "", "validation and mutation rules file")
+	sampleFile := flag.String("f", "", "sample object or AdmissionReview, in YAML or JSON")
+	oldFile := flag.String("old", "", "old object of the sample, for UPDATE requests")
+	operation := flag.String("operation", "CREATE", "operation of the request when the sample is an object")
+	flag.Parse()
+
+	if *rulesFile == "" || *sampleFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := ioutil.ReadFile(*rulesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rules, err := pkg.ParseRules(data)
+	if err != nil {
+		log.Fatalf("%s: %v", *rulesFile, err)
+	}
+
+	sample, err := ioutil.ReadFile(*sampleFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var old []byte
+	if *oldFile != "" {
+		if old, err = ioutil.ReadFile(*oldFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	req, err := pkg.SampleRequest(sample, *operation, old)
+	if err != nil {
+		log.Fatalf("%s: %v", *sampleFile, err)
+	}
+
+	result, err := rules.Evaluate(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := yaml.Marshal(result)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(out))
+
+	if len(result.Violations) > 0 {
+		os.Exit(1)
+	}
+}
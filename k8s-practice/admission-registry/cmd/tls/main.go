@@ -3,13 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"crypto/x509/pkix"
-	"encoding/pem"
 	"log"
-	"math/big"
 	"os"
 	"time"
 
@@ -20,109 +14,48 @@ import (
 )
 
 func main() {
-	// CA 配置
-	subject := pkix.Name{
-		Country:            []string{"CN"},
-		Province:           []string{"Beijing"},
-		Locality:           []string{"Beijing"},
-		Organization:       []string{"ydzs.io"},
-		OrganizationalUnit: []string{"ydzs.io"},
-	}
-	ca := &x509.Certificate{
-		SerialNumber:          big.NewInt(2021),
-		Subject:               subject,
-		NotBefore:             time.Now(), // 有效期
-		NotAfter:              time.Now().AddDate(10, 0, 0),
-		IsCA:                  true, // 根证书
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		BasicConstraintsValid: true,
-	}
-
-	// 生成CA私钥
-	caPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
-	if err != nil {
-		log.Panic(err)
-	}
-
-	// 创建自签名的 CA 证书
-	caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, &caPrivKey.PublicKey, caPrivKey)
+	// 生成 CA 和 CA 签名的服务端证书
+	ca, err := pkg.GenerateCA(time.Now(), 10*365*24*time.Hour)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	// 编码证书文件
-	caPEM := new(bytes.Buffer)
-	if err := pem.Encode(caPEM, &pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: caBytes,
-	}); err != nil {
-		log.Panic(err)
-	}
-
 	dnsNames := []string{"admission-registry",
 		"admission-registry.default",
 		"admission-registry.default.svc",
 		"admission-registry.default.svc.cluster.local",
 	}
-	commonName := "admission-registry.default.svc"
-	// 服务端的证书配置
-	subject.CommonName = commonName
-	cert := &x509.Certificate{
-		DNSNames:     dnsNames,
-		SerialNumber: big.NewInt(2020),
-		Subject:      subject,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().AddDate(1, 0, 0),
-		SubjectKeyId: []byte{1, 2, 3, 4, 6},
-		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-		KeyUsage:     x509.KeyUsageDigitalSignature,
-	}
-
-	// 生成服务端的私钥
-	serverPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	server, err := pkg.GenerateServerCert(ca, dnsNames, time.Now(), 365*24*time.Hour)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	// 对服务端私钥签名
-	serverCertBytes, err := x509.CreateCertificate(rand.Reader, cert, ca, &serverPrivKey.PublicKey, caPrivKey)
-	if err != nil {
-		log.Panic(err)
-	}
-	serverCertPEM := new(bytes.Buffer)
-	if err := pem.Encode(serverCertPEM, &pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: serverCertBytes,
-	}); err != nil {
+	// 已经生成了CA server.pem server-key.pem
+
+	if err := os.MkdirAll("/etc/webhook/certs/", 0666); err != nil {
 		log.Panic(err)
 	}
 
-	serverPrivKeyPEM := new(bytes.Buffer)
-	if err := pem.Encode(serverPrivKeyPEM, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(serverPrivKey),
-	}); err != nil {
+	if err := pkg.WriteFile("/etc/webhook/certs/tls.crt", server.CertPEM); err != nil {
 		log.Panic(err)
 	}
 
-	// 已经生成了CA server.pem server-key.pem
-
-	if err := os.MkdirAll("/etc/webhook/certs/", 0666); err != nil {
+	if err := pkg.WriteFile("/etc/webhook/certs/tls.key", server.KeyPEM); err != nil {
 		log.Panic(err)
 	}
 
-	if err := pkg.WriteFile("/etc/webhook/certs/tls.crt", serverCertPEM.Bytes()); err != nil {
+	// webhook server 在证书过期之前使用 CA 重新签名证书
+	if err := pkg.WriteFile("/etc/webhook/certs/ca.crt", ca.CertPEM); err != nil {
 		log.Panic(err)
 	}
 
-	if err := pkg.WriteFile("/etc/webhook/certs/tls.key", serverPrivKeyPEM.Bytes()); err != nil {
+	if err := pkg.WriteFile("/etc/webhook/certs/ca.key", ca.KeyPEM); err != nil {
 		log.Panic(err)
 	}
 
 	log.Println("webhook server tls generated successfully")
 
-	if err := CreateAdmissionConfig(caPEM); err != nil {
+	if err := CreateAdmissionConfig(bytes.NewBuffer(ca.CertPEM)); err != nil {
 		log.Panic(err)
 	}
 
@@ -171,6 +104,15 @@ func CreateAdmissionConfig(caCert *bytes.Buffer) error {
 								Resources:   []string{"pods"},
 							},
 						},
+						{
+							// 临时容器是通过子资源添加的
+							Operations: []admissionv1.OperationType{admissionv1.Update},
+							Rule: admissionv1.Rule{
+								APIGroups:   []string{""},
+								APIVersions: []string{"v1"},
+								Resources:   []string{"pods/ephemeralcontainers"},
+							},
+						},
 					},
 					AdmissionReviewVersions: []string{"v1"},
 					SideEffects: func() *admissionv1.SideEffectClass {
@@ -229,6 +171,37 @@ func CreateAdmissionConfig(caCert *bytes.Buffer) error {
 						return &se
 					}(),
 				},
+				{
+					// 注入 sidecar 和填充资源的默认值，webhook 自己的 Pod 创建时 webhook 还不可用，所以失败时忽略
+					Name: "io.ydzs.admission-registry-inject",
+					ClientConfig: admissionv1.WebhookClientConfig{
+						CABundle: caCert.Bytes(),
+						Service: &admissionv1.ServiceReference{
+							Name:      webhookService,
+							Namespace: webhookNamespace,
+							Path:      &mutatePath,
+						},
+					},
+					Rules: []admissionv1.RuleWithOperations{
+						{
+							Operations: []admissionv1.OperationType{admissionv1.Create},
+							Rule: admissionv1.Rule{
+								APIGroups:   []string{""},
+								APIVersions: []string{"v1"},
+								Resources:   []string{"pods"},
+							},
+						},
+					},
+					FailurePolicy: func() *admissionv1.FailurePolicyType {
+						fp := admissionv1.Ignore
+						return &fp
+					}(),
+					AdmissionReviewVersions: []string{"v1"},
+					SideEffects: func() *admissionv1.SideEffectClass {
+						se := admissionv1.SideEffectClassNone
+						return &se
+					}(),
+				},
 			},
 		}
 		mutateAdmissionClient := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations()
@@ -10,8 +10,15 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"admission-registry/pkg"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 )
 
@@ -24,23 +31,134 @@ func main()  {
 	flag.IntVar(&param.Port, "port", 443, "Webhook Server Port.")
 	flag.StringVar(&param.CertFile, "tlsCertFile", "/etc/webhook/certs/tls.crt", "x509 certification file")
 	flag.StringVar(&param.KeyFile, "tlsKeyFile", "/etc/webhook/certs/tls.key", "x509 private key file")
+	flag.StringVar(&param.CACertFile, "tlsCACertFile", "/etc/webhook/certs/ca.crt", "x509 CA certification file used to sign the rotated certification, a new CA is generated if it doesn't exist")
+	flag.StringVar(&param.CAKeyFile, "tlsCAKeyFile", "/etc/webhook/certs/ca.key", "x509 CA private key file")
+	flag.DurationVar(&param.CertValidity, "certValidity", 365*24*time.Hour, "validity of the rotated certification")
+	flag.DurationVar(&param.CAValidity, "caValidity", 10*365*24*time.Hour, "validity of the generated CA certification")
+	flag.DurationVar(&param.RotateBefore, "certRotateBefore", 30*24*time.Hour, "rotate the certification when it expires within the duration")
+	flag.DurationVar(&param.CertInterval, "certInterval", time.Hour, "interval of checking the certification for expiry")
+	flag.StringVar(&param.ValidateConfig, "validateConfig", "", "ValidatingWebhookConfiguration whose caBundle is patched after the CA changes")
+	flag.StringVar(&param.MutateConfig, "mutateConfig", "", "MutatingWebhookConfiguration whose caBundle is patched after the CA changes")
+	flag.StringVar(&param.PolicyFile, "policyFile", "", "image policy file, the WHITELIST_REGISTRIES env is used if empty")
+	flag.StringVar(&param.RulesFile, "rulesFile", "", "validation and mutation rules file written in expressions")
+	flag.StringVar(&param.ResourcesFile, "resourcesFile", "", "default resources and limits of containers per namespace")
+	flag.StringVar(&param.SecurityFile, "securityFile", "", "pod security policy file")
+	flag.StringVar(&param.SidecarTemplate, "sidecarTemplate", "", "sidecar template file, sidecars are not injected if empty")
+	flag.DurationVar(&param.PolicyInterval, "policyInterval", 10*time.Second, "interval of checking the policy and rules files for changes")
 	flag.Parse()
 
-	cert, err := tls.LoadX509KeyPair(param.CertFile, param.KeyFile)
+	// 证书在过期之前自动轮换，通过 GetCertificate 使用新的证书
+	rotator := &pkg.CertRotator{
+		CertFile:     param.CertFile,
+		KeyFile:      param.KeyFile,
+		CAFile:       param.CACertFile,
+		CAKeyFile:    param.CAKeyFile,
+		Validity:     param.CertValidity,
+		CAValidity:   param.CAValidity,
+		RotateBefore: param.RotateBefore,
+	}
+	err := rotator.Load()
 	if err != nil {
 		klog.Errorf("Failed to load key pair: %v", err)
 		return
 	}
+	if param.ValidateConfig != "" || param.MutateConfig != "" {
+		clientset, err := inClusterClient()
+		if err != nil {
+			klog.Errorf("Failed to create the kubernetes client: %v", err)
+			return
+		}
+		patcher := &pkg.WebhookConfigPatcher{Client: clientset}
+		if param.ValidateConfig != "" {
+			patcher.Validating = []string{param.ValidateConfig}
+		}
+		if param.MutateConfig != "" {
+			patcher.Mutating = []string{param.MutateConfig}
+		}
+		rotator.Patcher = patcher
+	}
+	// 启动时证书可能已经快要过期了
+	if _, err := rotator.Rotate(); err != nil {
+		klog.Errorf("Failed to rotate the webhook certificate: %v", err)
+	}
+
+	// 加载镜像准入策略和规则，文件变化后自动重新加载
+	policies := pkg.NewPolicyStore(pkg.WhitelistPolicy(strings.Split(os.Getenv("WHITELIST_REGISTRIES"), ",")))
+	if param.PolicyFile != "" {
+		if policies, err = pkg.LoadPolicyStore(param.PolicyFile); err != nil {
+			klog.Errorf("Failed to load policy: %v", err)
+			return
+		}
+	}
+	rules := pkg.NewRuleStore(&pkg.Rules{})
+	if param.RulesFile != "" {
+		if rules, err = pkg.LoadRuleStore(param.RulesFile); err != nil {
+			klog.Errorf("Failed to load rules: %v", err)
+			return
+		}
+	}
+	resources := pkg.NewResourceStore(&pkg.ResourcePolicy{})
+	if param.ResourcesFile != "" {
+		if resources, err = pkg.LoadResourceStore(param.ResourcesFile); err != nil {
+			klog.Errorf("Failed to load resource policy: %v", err)
+			return
+		}
+	}
+	security := pkg.NewSecurityStore(&pkg.SecurityPolicy{})
+	if param.SecurityFile != "" {
+		if security, err = pkg.LoadSecurityStore(param.SecurityFile); err != nil {
+			klog.Errorf("Failed to load security policy: %v", err)
+			return
+		}
+	}
+	stopCh := make(chan struct{})
+	go rotator.Run(param.CertInterval, stopCh)
+	go policies.Watch(param.PolicyInterval, stopCh)
+	go rules.Watch(param.PolicyInterval, stopCh)
+	go resources.Watch(param.PolicyInterval, stopCh)
+	go security.Watch(param.PolicyInterval, stopCh)
+
+	// 配置了 sidecar 模板时才注入 sidecar，通过 informer 获取命名空间的标签
+	var (
+		sidecar    *pkg.SidecarStore
+		namespaces corelisters.NamespaceLister
+	)
+	if param.SidecarTemplate != "" {
+		if sidecar, err = pkg.LoadSidecarStore(param.SidecarTemplate); err != nil {
+			klog.Errorf("Failed to load sidecar template: %v", err)
+			return
+		}
+		go sidecar.Watch(param.PolicyInterval, stopCh)
+
+		if namespaces, err = namespaceLister(stopCh); err != nil {
+			klog.Errorf("Failed to watch namespaces: %v", err)
+			return
+		}
+	}
+
+	// 添加临时容器时需要获取 Pod，没有 client 时拒绝添加临时容器
+	var pods corev1client.PodsGetter
+	if clientset, err := inClusterClient(); err != nil {
+		klog.Warningf("Failed to create the kubernetes client, ephemeral containers will be denied: %v", err)
+	} else {
+		pods = clientset.CoreV1()
+	}
 
 	// 实例化一个Webhook Server
 	whsrv := pkg.WebhookServer{
 		Server: &http.Server{
 			Addr: fmt.Sprintf(":%d", param.Port),
 			TLSConfig: &tls.Config{
-				Certificates: []tls.Certificate{cert},
+				GetCertificate: rotator.GetCertificate,
 			},
 		},
-		WhiteListRegistries: strings.Split(os.Getenv("WHITELIST_REGISTRIES"), ","),
+		Policies:   policies,
+		Rules:      rules,
+		Resources:  resources,
+		Security:   security,
+		Sidecar:    sidecar,
+		Namespaces: namespaces,
+		Pods:       pods,
 	}
 
 	// 定义 http server handler
@@ -64,7 +182,33 @@ func main()  {
 	<-signalChan
 
 	klog.Infof("Got OS shutdown signal, gracefully shutting down...")
+	close(stopCh)
 	if err := whsrv.Server.Shutdown(context.Background()); err != nil {
 		klog.Errorf("HTTP Server Shutdown error: %v", err)
 	}
 }
+
+func inClusterClient() (*kubernetes.Clientset, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// namespaceLister 返回从 informer 缓存中获取命名空间的 lister
+func namespaceLister(stopCh <-chan struct{}) (corelisters.NamespaceLister, error) {
+	clientset, err := inClusterClient()
+	if err != nil {
+		return nil, err
+	}
+
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+	informer := factory.Core().V1().Namespaces()
+	lister := informer.Lister()
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.Informer().HasSynced) {
+		return nil, fmt.Errorf("failed to sync the namespace cache")
+	}
+	return lister, nil
+}
@@ -0,0 +1,481 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+// keyBits 是生成的 RSA 私钥的长度
+var keyBits = 4096
+
+var certSubject = pkix.Name{
+	Country:            []string{"CN"},
+	Province:           []string{"Beijing"},
+	Locality:           []string{"Beijing"},
+	Organization:       []string{"ydzs.io"},
+	OrganizationalUnit: []string{"ydzs.io"},
+}
+
+// KeyPair 是 PEM 编码的证书和私钥
+type KeyPair struct {
+	CertPEM []byte
+	KeyPEM  []byte
+
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+// Certificate 返回解析后的证书
+func (p *KeyPair) Certificate() *x509.Certificate {
+	return p.cert
+}
+
+// ParseKeyPair 解析 PEM 编码的证书和 RSA 私钥
+func ParseKeyPair(certPEM, keyPEM []byte) (*KeyPair, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil || certBlock.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil || keyBlock.Type != "RSA PRIVATE KEY" {
+		return nil, fmt.Errorf("no RSA private key found")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{CertPEM: certPEM, KeyPEM: keyPEM, cert: cert, key: key}, nil
+}
+
+// GenerateCA 生成自签名的 CA 证书，有效期从 now 开始
+func GenerateCA(now time.Time, validity time.Duration) (*KeyPair, error) {
+	ca := &x509.Certificate{
+		Subject:               certSubject,
+		NotBefore:             now,
+		NotAfter:              now.Add(validity),
+		IsCA:                  true, // 根证书
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	return createKeyPair(ca, nil)
+}
+
+// GenerateServerCert 生成 ca 签名的服务端证书，有效期从 now 开始
+func GenerateServerCert(ca *KeyPair, dnsNames []string, now time.Time, validity time.Duration) (*KeyPair, error) {
+	if len(dnsNames) == 0 {
+		return nil, fmt.Errorf("the dns names of the certificate are required")
+	}
+
+	// 和原来生成的证书一样，使用 .svc 结尾的域名作为 CommonName
+	subject := certSubject
+	subject.CommonName = dnsNames[0]
+	for _, name := range dnsNames {
+		if strings.HasSuffix(name, ".svc") {
+			subject.CommonName = name
+			break
+		}
+	}
+	cert := &x509.Certificate{
+		DNSNames:    dnsNames,
+		Subject:     subject,
+		NotBefore:   now,
+		NotAfter:    now.Add(validity),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}
+	return createKeyPair(cert, ca)
+}
+
+// createKeyPair 生成私钥并用 ca 签名证书，ca 为空时生成自签名的证书
+func createKeyPair(template *x509.Certificate, ca *KeyPair) (*KeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, err
+	}
+	// 每次轮换都使用不同的序列号
+	if template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128)); err != nil {
+		return nil, err
+	}
+
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return ParseKeyPair(certPEM, keyPEM)
+}
+
+// MergeCABundle 把 ca 放在 caBundle 的最前面，并保留 caBundle 中没有过期的其他证书，
+// 这样在切换到新 CA 签名的证书之前，apiserver 仍然信任旧的证书
+func MergeCABundle(ca, caBundle []byte, now time.Time) []byte {
+	merged := append([]byte{}, ca...)
+	for rest := caBundle; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || now.After(cert.NotAfter) {
+			continue
+		}
+		data := pem.EncodeToMemory(block)
+		if !bytes.Contains(merged, data) {
+			merged = append(merged, data...)
+		}
+	}
+	return merged
+}
+
+// CABundlePatcher 更新 webhook 配置中的 caBundle
+type CABundlePatcher interface {
+	PatchCABundle(ca []byte) error
+	// CABundleContains 读取 webhook 配置，判断所有 webhook 的 caBundle 是否都包含 ca
+	CABundleContains(ca []byte) (bool, error)
+}
+
+// WebhookConfigPatcher 更新 ValidatingWebhookConfiguration 和 MutatingWebhookConfiguration 中所有 webhook 的 caBundle
+type WebhookConfigPatcher struct {
+	Client     kubernetes.Interface
+	Validating []string
+	Mutating   []string
+}
+
+// PatchCABundle 把 ca 合并到每个 webhook 的 caBundle 中，caBundle 没有变化的配置不会更新
+func (p *WebhookConfigPatcher) PatchCABundle(ca []byte) error {
+	ctx := context.Background()
+	for _, name := range p.Validating {
+		client := p.Client.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+		config, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		var bundles [][]byte
+		for _, webhook := range config.Webhooks {
+			bundles = append(bundles, webhook.ClientConfig.CABundle)
+		}
+		patch := caBundlePatch(config.ResourceVersion, bundles, ca)
+		if patch == nil {
+			continue
+		}
+		if _, err := client.Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+		klog.Infof("Patched the caBundle of ValidatingWebhookConfiguration %s", name)
+	}
+
+	for _, name := range p.Mutating {
+		client := p.Client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+		config, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		var bundles [][]byte
+		for _, webhook := range config.Webhooks {
+			bundles = append(bundles, webhook.ClientConfig.CABundle)
+		}
+		patch := caBundlePatch(config.ResourceVersion, bundles, ca)
+		if patch == nil {
+			continue
+		}
+		if _, err := client.Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+		klog.Infof("Patched the caBundle of MutatingWebhookConfiguration %s", name)
+	}
+
+	return nil
+}
+
+// CABundleContains 读取 webhook 配置，判断所有 webhook 的 caBundle 是否都包含 ca
+func (p *WebhookConfigPatcher) CABundleContains(ca []byte) (bool, error) {
+	ctx := context.Background()
+	var bundles [][]byte
+	for _, name := range p.Validating {
+		config, err := p.Client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, webhook := range config.Webhooks {
+			bundles = append(bundles, webhook.ClientConfig.CABundle)
+		}
+	}
+	for _, name := range p.Mutating {
+		config, err := p.Client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, webhook := range config.Webhooks {
+			bundles = append(bundles, webhook.ClientConfig.CABundle)
+		}
+	}
+
+	for _, bundle := range bundles {
+		if !bytes.Contains(bundle, ca) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// caBundlePatch 返回更新 caBundle 的 JSON Patch，没有变化时返回空。
+// 先检查 resourceVersion，避免覆盖同时发生的其他修改
+func caBundlePatch(resourceVersion string, bundles [][]byte, ca []byte) []byte {
+	var patch []patchOperation
+	if resourceVersion != "" {
+		patch = append(patch, patchOperation{Op: "test", Path: "/metadata/resourceVersion", Value: resourceVersion})
+	}
+	checks := len(patch)
+	for i, bundle := range bundles {
+		merged := MergeCABundle(ca, bundle, time.Now())
+		if bytes.Equal(merged, bundle) {
+			continue
+		}
+		patch = append(patch, patchOperation{Op: "add", Path: fmt.Sprintf("/webhooks/%d/clientConfig/caBundle", i), Value: merged})
+	}
+	if len(patch) == checks {
+		return nil
+	}
+
+	data, _ := json.Marshal(patch)
+	return data
+}
+
+// CertRotator 在服务端证书过期之前重新生成证书，并通过 tls.Config.GetCertificate 使用新的证书。
+// CA 的文件存在时使用原来的 CA 签名，否则生成新的 CA，并更新 webhook 配置中的 caBundle
+type CertRotator struct {
+	CertFile  string
+	KeyFile   string
+	CAFile    string
+	CAKeyFile string
+	// Validity 是服务端证书的有效期，CAValidity 是 CA 证书的有效期
+	Validity   time.Duration
+	CAValidity time.Duration
+	// RotateBefore 是在证书过期之前多久重新生成证书
+	RotateBefore time.Duration
+	// Patcher 为空时不更新 caBundle，这时没有 CA 的文件或者 CA 快要过期时不能在证书过期之前轮换
+	Patcher CABundlePatcher
+
+	now func() time.Time
+
+	lock    sync.RWMutex
+	serving *KeyPair
+	tlsCert *tls.Certificate
+	ca      *KeyPair
+	// 新 CA 签名的证书要等到 apiserver 信任新的 CA 之后才能使用
+	pending *KeyPair
+}
+
+// Load 从文件加载当前的证书和 CA，CA 的文件不存在时忽略
+func (r *CertRotator) Load() error {
+	certPEM, err := ioutil.ReadFile(r.CertFile)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := ioutil.ReadFile(r.KeyFile)
+	if err != nil {
+		return err
+	}
+	serving, err := ParseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.CertFile, err)
+	}
+
+	var ca *KeyPair
+	if r.CAFile != "" && r.CAKeyFile != "" {
+		caPEM, err := ioutil.ReadFile(r.CAFile)
+		if err == nil {
+			var caKeyPEM []byte
+			if caKeyPEM, err = ioutil.ReadFile(r.CAKeyFile); err == nil {
+				ca, err = ParseKeyPair(caPEM, caKeyPEM)
+			}
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("%s: %v", r.CAFile, err)
+		}
+	}
+
+	tlsCert, err := tls.X509KeyPair(serving.CertPEM, serving.KeyPEM)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.serving, r.tlsCert, r.ca = serving, &tlsCert, ca
+	return nil
+}
+
+// GetCertificate 返回当前的证书，用于 tls.Config.GetCertificate
+func (r *CertRotator) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.tlsCert == nil {
+		return nil, fmt.Errorf("no certificate loaded")
+	}
+	return r.tlsCert, nil
+}
+
+func (r *CertRotator) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// Rotate 在证书快要过期时重新生成证书，返回是否开始使用新的证书。
+// 需要新的 CA 时，先更新 caBundle，在下一次调用 Rotate 时确认 caBundle 包含新的 CA 之后才使用新的证书，
+// 给 apiserver 留出更新配置的时间。原来的证书没有过期时，不会使用 apiserver 不信任的 CA 签名的证书
+func (r *CertRotator) Rotate() (bool, error) {
+	now := r.currentTime()
+	r.lock.RLock()
+	serving, ca, pending := r.serving, r.ca, r.pending
+	r.lock.RUnlock()
+	valid := now.Before(serving.cert.NotAfter)
+
+	if pending != nil {
+		if valid {
+			if err := r.checkCABundle(ca); err != nil {
+				return false, err
+			}
+		}
+		return true, r.use(pending, ca)
+	}
+	if serving.cert.NotAfter.Sub(now) > r.RotateBefore {
+		return false, nil
+	}
+
+	// CA 要比新的证书更晚过期
+	newCA := ca == nil || ca.cert.NotAfter.Before(now.Add(r.Validity))
+	// 当前的证书不是这个 CA 签名的时候，apiserver 也不一定信任它，
+	// 例如生成新的 CA 之后、使用新的证书之前重启了，这时 pending 已经丢失，但是新的 CA 已经写入了文件
+	untrusted := newCA || serving.cert.CheckSignatureFrom(ca.cert) != nil
+	if untrusted && r.Patcher == nil && valid {
+		return false, fmt.Errorf("the certificate expires at %s, rotating it needs a CA that the caBundle may not "+
+			"contain but there is no webhook configuration to patch, add the CA files of the current certificate "+
+			"or the webhook configurations", serving.cert.NotAfter.Format(time.RFC3339))
+	}
+	if newCA {
+		var err error
+		if ca, err = GenerateCA(now, r.CAValidity); err != nil {
+			return false, err
+		}
+	}
+	next, err := GenerateServerCert(ca, serving.cert.DNSNames, now, r.Validity)
+	if err != nil {
+		return false, err
+	}
+
+	if r.Patcher != nil {
+		if err := r.Patcher.PatchCABundle(ca.CertPEM); err != nil {
+			return false, fmt.Errorf("failed to patch the caBundle: %v", err)
+		}
+	}
+	// 原来的证书还没有过期时等待 apiserver 信任新的 CA
+	if untrusted && valid {
+		r.lock.Lock()
+		r.ca, r.pending = ca, next
+		r.lock.Unlock()
+		klog.Infof("The new certificate will be used after the caBundle contains its CA")
+		if !newCA {
+			return false, nil
+		}
+		return false, r.writeCA(ca)
+	}
+
+	if err := r.use(next, ca); err != nil {
+		return true, err
+	}
+	return true, r.writeCA(ca)
+}
+
+// checkCABundle 确认 webhook 配置的 caBundle 包含 ca，配置被覆盖（例如重新 apply 了清单）时重新更新，
+// 返回错误表示继续使用原来的证书
+func (r *CertRotator) checkCABundle(ca *KeyPair) error {
+	trusted, err := r.Patcher.CABundleContains(ca.CertPEM)
+	if err != nil {
+		return fmt.Errorf("failed to read the caBundle, keep the current certificate: %v", err)
+	}
+	if trusted {
+		return nil
+	}
+
+	if err := r.Patcher.PatchCABundle(ca.CertPEM); err != nil {
+		return fmt.Errorf("failed to patch the caBundle: %v", err)
+	}
+	return fmt.Errorf("the caBundle does not contain the new CA, patched it again, keep the current certificate")
+}
+
+// use 开始使用证书 serving，并写入文件，重启之后继续使用这个证书。
+// 证书目录是只读的时候写入会失败，但是仍然使用新的证书
+func (r *CertRotator) use(serving, ca *KeyPair) error {
+	tlsCert, err := tls.X509KeyPair(serving.CertPEM, serving.KeyPEM)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	r.serving, r.tlsCert, r.ca, r.pending = serving, &tlsCert, ca, nil
+	r.lock.Unlock()
+	klog.Infof("Rotated the webhook certificate, it expires at %s", serving.cert.NotAfter.Format(time.RFC3339))
+
+	if err := WriteFile(r.KeyFile, serving.KeyPEM); err != nil {
+		return err
+	}
+	return WriteFile(r.CertFile, serving.CertPEM)
+}
+
+func (r *CertRotator) writeCA(ca *KeyPair) error {
+	if r.CAFile == "" || r.CAKeyFile == "" {
+		return nil
+	}
+	if err := WriteFile(r.CAKeyFile, ca.KeyPEM); err != nil {
+		return err
+	}
+	return WriteFile(r.CAFile, ca.CertPEM)
+}
+
+// Run 每隔 interval 检查一次证书，直到 stop 被关闭
+func (r *CertRotator) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := r.Rotate(); err != nil {
+				klog.Errorf("Failed to rotate the webhook certificate: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testDNSNames = []string{"admission-registry", "admission-registry.default.svc"}
+
+func init() {
+	// 测试中使用较短的私钥，加快生成证书的速度
+	keyBits = 1024
+}
+
+// recordPatcher 记录每次更新的 CA，bundle 是更新之后的 caBundle
+type recordPatcher struct {
+	cas    [][]byte
+	bundle []byte
+}
+
+func (p *recordPatcher) PatchCABundle(ca []byte) error {
+	p.cas = append(p.cas, ca)
+	p.bundle = MergeCABundle(ca, p.bundle, time.Now())
+	return nil
+}
+
+func (p *recordPatcher) CABundleContains(ca []byte) (bool, error) {
+	return bytes.Contains(p.bundle, ca), nil
+}
+
+// newTestRotator 生成 now 开始有效一年的证书，withCA 为 false 时不保存 CA 的文件
+func newTestRotator(t *testing.T, now time.Time, withCA bool) (*CertRotator, *KeyPair) {
+	ca, err := GenerateCA(now, 10*365*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serving, err := GenerateServerCert(ca, testDNSNames, now, 365*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	r := &CertRotator{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		CAFile:       filepath.Join(dir, "ca.crt"),
+		CAKeyFile:    filepath.Join(dir, "ca.key"),
+		Validity:     365 * 24 * time.Hour,
+		CAValidity:   10 * 365 * 24 * time.Hour,
+		RotateBefore: 30 * 24 * time.Hour,
+	}
+	files := map[string][]byte{r.CertFile: serving.CertPEM, r.KeyFile: serving.KeyPEM}
+	if withCA {
+		files[r.CAFile], files[r.CAKeyFile] = ca.CertPEM, ca.KeyPEM
+	}
+	for file, data := range files {
+		if err := WriteFile(file, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return r, ca
+}
+
+func servingCert(t *testing.T, r *CertRotator) *x509.Certificate {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+// verify 检查证书是否由 ca 签名并且在 now 时有效
+func verify(cert *x509.Certificate, ca []byte, now time.Time) error {
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca)
+	_, err := cert.Verify(x509.VerifyOptions{DNSName: "admission-registry.default.svc", Roots: roots, CurrentTime: now})
+	return err
+}
+
+func TestCertRotator(t *testing.T) {
+	start := time.Now()
+	now := start
+	r, ca := newTestRotator(t, start, true)
+	r.now = func() time.Time { return now }
+	patcher := &recordPatcher{}
+	r.Patcher = patcher
+	original := servingCert(t, r)
+
+	// 离过期还有很久时不轮换
+	now = start.Add(300 * 24 * time.Hour)
+	if rotated, err := r.Rotate(); err != nil || rotated {
+		t.Fatalf("Rotate() = %v, %v, want false", rotated, err)
+	}
+
+	// 快要过期时使用原来的 CA 签名新的证书，caBundle 不需要等待更新
+	now = start.Add(340 * 24 * time.Hour)
+	if rotated, err := r.Rotate(); err != nil || !rotated {
+		t.Fatalf("Rotate() = %v, %v, want true", rotated, err)
+	}
+	cert := servingCert(t, r)
+	if cert.SerialNumber.Cmp(original.SerialNumber) == 0 {
+		t.Fatal("the certificate is not rotated")
+	}
+	if err := verify(cert, ca.CertPEM, start.Add(700*24*time.Hour)); err != nil {
+		t.Errorf("the rotated certificate is invalid: %v", err)
+	}
+	if len(patcher.cas) != 1 || !bytes.Equal(patcher.cas[0], ca.CertPEM) {
+		t.Errorf("patched %d CAs, want the original CA", len(patcher.cas))
+	}
+
+	// 新的证书写入了文件，重启之后继续使用
+	data, err := ioutil.ReadFile(r.CertFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, r.serving.CertPEM) {
+		t.Error("the rotated certificate is not written to the file")
+	}
+}
+
+func TestCertRotatorNewCA(t *testing.T) {
+	start := time.Now()
+	now := start.Add(350 * 24 * time.Hour)
+	r, oldCA := newTestRotator(t, start, false)
+	r.now = func() time.Time { return now }
+	patcher := &recordPatcher{}
+	r.Patcher = patcher
+	original := servingCert(t, r)
+
+	// 没有 CA 时生成新的 CA，先更新 caBundle，继续使用原来的证书
+	if rotated, err := r.Rotate(); err != nil || rotated {
+		t.Fatalf("Rotate() = %v, %v, want false", rotated, err)
+	}
+	if len(patcher.cas) != 1 || bytes.Equal(patcher.cas[0], oldCA.CertPEM) {
+		t.Fatalf("patched %d CAs, want a new CA", len(patcher.cas))
+	}
+	if cert := servingCert(t, r); !cert.Equal(original) {
+		t.Error("the certificate is rotated before the caBundle is updated")
+	}
+	newCA, err := ioutil.ReadFile(r.CAFile)
+	if err != nil || !bytes.Equal(newCA, patcher.cas[0]) {
+		t.Fatalf("the new CA is not written to the file: %v", err)
+	}
+
+	// caBundle 被覆盖时重新更新，继续使用原来的证书
+	patcher.bundle = oldCA.CertPEM
+	if rotated, err := r.Rotate(); err == nil || rotated {
+		t.Fatalf("Rotate() = %v, %v, want false and an error", rotated, err)
+	}
+	if cert := servingCert(t, r); !cert.Equal(original) {
+		t.Error("the certificate is rotated before the caBundle contains the new CA")
+	}
+	if len(patcher.cas) != 2 || !bytes.Equal(patcher.cas[1], newCA) {
+		t.Fatalf("patched %d CAs, want the new CA again", len(patcher.cas))
+	}
+
+	// 下一次检查时 caBundle 包含新的 CA，使用新 CA 签名的证书
+	if rotated, err := r.Rotate(); err != nil || !rotated {
+		t.Fatalf("Rotate() = %v, %v, want true", rotated, err)
+	}
+	if err := verify(servingCert(t, r), newCA, now); err != nil {
+		t.Errorf("the rotated certificate is invalid: %v", err)
+	}
+	if rotated, err := r.Rotate(); err != nil || rotated || len(patcher.cas) != 2 {
+		t.Errorf("Rotate() = %v, %v after the rotation, want false", rotated, err)
+	}
+}
+
+func TestCertRotatorRestartWithNewCA(t *testing.T) {
+	start := time.Now()
+	now := start.Add(350 * 24 * time.Hour)
+	r, oldCA := newTestRotator(t, start, false)
+	r.now = func() time.Time { return now }
+	patcher := &recordPatcher{}
+	r.Patcher = patcher
+	original := servingCert(t, r)
+
+	if rotated, err := r.Rotate(); err != nil || rotated {
+		t.Fatalf("Rotate() = %v, %v, want false", rotated, err)
+	}
+	newCA := patcher.cas[0]
+
+	// 重启之后加载新的 CA 和原来的证书，caBundle 被覆盖成了原来的 CA
+	restarted := &CertRotator{
+		CertFile:     r.CertFile,
+		KeyFile:      r.KeyFile,
+		CAFile:       r.CAFile,
+		CAKeyFile:    r.CAKeyFile,
+		Validity:     r.Validity,
+		CAValidity:   r.CAValidity,
+		RotateBefore: r.RotateBefore,
+		Patcher:      patcher,
+		now:          r.now,
+	}
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	patcher.bundle = oldCA.CertPEM
+
+	// 新的 CA 签名的证书同样要等 caBundle 包含新的 CA
+	if rotated, err := restarted.Rotate(); err != nil || rotated {
+		t.Fatalf("Rotate() after the restart = %v, %v, want false", rotated, err)
+	}
+	if cert := servingCert(t, restarted); !cert.Equal(original) {
+		t.Error("the certificate is rotated before the caBundle contains the new CA")
+	}
+	if len(patcher.cas) != 2 || !bytes.Equal(patcher.cas[1], newCA) {
+		t.Fatalf("patched %d CAs, want the new CA again", len(patcher.cas))
+	}
+
+	if rotated, err := restarted.Rotate(); err != nil || !rotated {
+		t.Fatalf("Rotate() = %v, %v, want true", rotated, err)
+	}
+	if err := verify(servingCert(t, restarted), newCA, now); err != nil {
+		t.Errorf("the rotated certificate is invalid: %v", err)
+	}
+}
+
+func TestCertRotatorNoPatcher(t *testing.T) {
+	start := time.Now()
+	now := start.Add(350 * 24 * time.Hour)
+	r, _ := newTestRotator(t, start, false)
+	r.now = func() time.Time { return now }
+	original := servingCert(t, r)
+
+	// 没有 CA 也不能更新 caBundle 时，不使用 apiserver 不信任的 CA 签名的证书
+	for i := 0; i < 2; i++ {
+		if rotated, err := r.Rotate(); err == nil || rotated {
+			t.Fatalf("Rotate() = %v, %v, want false and an error", rotated, err)
+		}
+	}
+	if cert := servingCert(t, r); !cert.Equal(original) {
+		t.Error("the certificate is rotated to an untrusted CA")
+	}
+	if _, err := ioutil.ReadFile(r.CAFile); !os.IsNotExist(err) {
+		t.Errorf("a new CA is written to the file: %v", err)
+	}
+
+	// 证书已经过期时，新的证书不会更差
+	now = start.Add(400 * 24 * time.Hour)
+	if rotated, err := r.Rotate(); err != nil || !rotated {
+		t.Fatalf("Rotate() = %v, %v after the expiration, want true", rotated, err)
+	}
+}
+
+func TestCertRotatorExpired(t *testing.T) {
+	start := time.Now()
+	now := start.Add(400 * 24 * time.Hour)
+	r, _ := newTestRotator(t, start, false)
+	r.now = func() time.Time { return now }
+	r.Patcher = &recordPatcher{}
+	// 证书目录是只读的
+	r.KeyFile = filepath.Join(filepath.Dir(r.KeyFile), "missing", "tls.key")
+
+	// 证书已经过期时不需要等待 caBundle 更新，写入文件失败时仍然使用新的证书
+	if rotated, err := r.Rotate(); err == nil || !rotated {
+		t.Fatalf("Rotate() = %v, %v, want true and an error", rotated, err)
+	}
+	if cert := servingCert(t, r); now.After(cert.NotAfter) {
+		t.Errorf("the certificate expires at %s", cert.NotAfter)
+	}
+}
+
+func TestMergeCABundle(t *testing.T) {
+	now := time.Now()
+	expired, err := GenerateCA(now.Add(-48*time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldCA, err := GenerateCA(now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCA, err := GenerateCA(now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := append(append([]byte{}, oldCA.CertPEM...), expired.CertPEM...)
+	merged := MergeCABundle(newCA.CertPEM, bundle, now)
+	want := append(append([]byte{}, newCA.CertPEM...), oldCA.CertPEM...)
+	if !bytes.Equal(merged, want) {
+		t.Errorf("MergeCABundle() = %s, want %s", merged, want)
+	}
+	if again := MergeCABundle(newCA.CertPEM, merged, now); !bytes.Equal(again, merged) {
+		t.Error("MergeCABundle() should not change a merged bundle")
+	}
+}
+
+func TestWebhookConfigPatcher(t *testing.T) {
+	now := time.Now()
+	oldCA, err := GenerateCA(now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCA, err := GenerateCA(now, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "admission-registry"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "io.ydzs.admission-registry", ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: oldCA.CertPEM}},
+			},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "admission-registry-mutate"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "io.ydzs.admission-registry-mutate", ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: oldCA.CertPEM}},
+				{Name: "io.ydzs.admission-registry-inject"},
+			},
+		},
+	)
+	patcher := &WebhookConfigPatcher{
+		Client:     client,
+		Validating: []string{"admission-registry"},
+		Mutating:   []string{"admission-registry-mutate"},
+	}
+	if contains, err := patcher.CABundleContains(newCA.CertPEM); err != nil || contains {
+		t.Fatalf("CABundleContains() = %v, %v before the patch, want false", contains, err)
+	}
+	if err := patcher.PatchCABundle(newCA.CertPEM); err != nil {
+		t.Fatal(err)
+	}
+	if contains, err := patcher.CABundleContains(newCA.CertPEM); err != nil || !contains {
+		t.Fatalf("CABundleContains() = %v, %v after the patch, want true", contains, err)
+	}
+
+	merged := append(append([]byte{}, newCA.CertPEM...), oldCA.CertPEM...)
+	ctx := context.Background()
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "admission-registry", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(validating.Webhooks[0].ClientConfig.CABundle, merged) {
+		t.Errorf("the caBundle of %s = %s", validating.Webhooks[0].Name, validating.Webhooks[0].ClientConfig.CABundle)
+	}
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, "admission-registry-mutate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range [][]byte{merged, newCA.CertPEM} {
+		if got := mutating.Webhooks[i].ClientConfig.CABundle; !bytes.Equal(got, want) {
+			t.Errorf("the caBundle of %s = %s, want %s", mutating.Webhooks[i].Name, got, want)
+		}
+	}
+
+	// caBundle 已经包含 CA 时不再更新
+	client.ClearActions()
+	if err := patcher.PatchCABundle(newCA.CertPEM); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Errorf("unexpected action %v", action)
+		}
+	}
+}
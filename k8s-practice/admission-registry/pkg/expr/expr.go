@@ -0,0 +1,171 @@
+// Package expr 用 CEL（Common Expression Language，https://github.com/google/cel-go）编写准入规则的表达式，
+// 规则可以用标准的 CEL 工具测试。
+//
+// 变量的类型是 dyn，值和 JSON 对应：null、bool、int、double、string、list 和 map（key 是 string）。
+// 除了 CEL 的标准函数和宏，还可以使用 cel-go 的 strings 扩展（版本 2），例如 lowerAscii、split 和 join。
+// 求值的代价超过 CostLimit 时停止求值并返回错误，避免一条规则占用过多的 CPU
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+)
+
+// CostLimit 是一次求值的代价上限，和 Kubernetes 的 ValidatingAdmissionPolicy 的单个表达式的上限相同
+const CostLimit = 1000000
+
+// Program 是编译后的表达式，可以并发求值
+type Program struct {
+	src     string
+	program cel.Program
+}
+
+// Compile 编译表达式 src，vars 是表达式可以使用的变量。常量的正则表达式在编译时检查
+func Compile(src string, vars ...string) (*Program, error) {
+	opts := []cel.EnvOption{ext.Strings(ext.StringsVersion(2))}
+	for _, v := range vars {
+		opts = append(opts, cel.Variable(v, cel.DynType))
+	}
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(src)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize), cel.CostLimit(CostLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{src: src, program: program}, nil
+}
+
+// Eval 使用变量 vars 求值，变量的值必须是 Normalize 之后的值，结果也转换成同样的值
+func (p *Program) Eval(vars map[string]interface{}) (interface{}, error) {
+	out, _, err := p.program.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return toNative(out)
+}
+
+// EvalBool 求值并要求结果是 bool
+func (p *Program) EvalBool(vars map[string]interface{}) (bool, error) {
+	out, _, err := p.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.(types.Bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool, got %s", out.Type().TypeName())
+	}
+	return bool(b), nil
+}
+
+// String 返回表达式的源码
+func (p *Program) String() string {
+	return p.src
+}
+
+// toNative 把 CEL 的值转换成 Normalize 使用的值
+func toNative(v ref.Val) (interface{}, error) {
+	switch x := v.(type) {
+	case types.Null:
+		return nil, nil
+	case types.Bool:
+		return bool(x), nil
+	case types.Int:
+		return int64(x), nil
+	case types.Uint:
+		if uint64(x) > 1<<63-1 {
+			return float64(x), nil
+		}
+		return int64(x), nil
+	case types.Double:
+		return float64(x), nil
+	case types.String:
+		return string(x), nil
+	case traits.Lister:
+		size := int(x.Size().(types.Int))
+		list := make([]interface{}, size)
+		for i := 0; i < size; i++ {
+			elem, err := toNative(x.Get(types.Int(i)))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+	case traits.Mapper:
+		m := make(map[string]interface{})
+		for it := x.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			s, ok := key.(types.String)
+			if !ok {
+				return nil, fmt.Errorf("map keys must be strings, got %s", key.Type().TypeName())
+			}
+			value, err := toNative(x.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			m[string(s)] = value
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported result type %s", v.Type().TypeName())
+}
+
+// Normalize 把任意可以序列化成 JSON 的值转换成表达式使用的值，
+// 整数转换成 int64，其他数字转换成 float64
+func Normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(data)
+}
+
+// FromJSON 解析 JSON 数据，数字的处理和 Normalize 相同
+func FromJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid JSON: extra data after the value")
+	}
+	return convertNumbers(v), nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(x), 10, 64); err == nil {
+			return i
+		}
+		f, _ := strconv.ParseFloat(string(x), 64)
+		return f
+	case []interface{}:
+		for i := range x {
+			x[i] = convertNumbers(x[i])
+		}
+	case map[string]interface{}:
+		for key := range x {
+			x[key] = convertNumbers(x[key])
+		}
+	}
+	return v
+}
@@ -0,0 +1,261 @@
+package expr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testVars(t *testing.T) map[string]interface{} {
+	object, err := FromJSON([]byte(`{
+		"metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+		"spec": {
+			"replicas": 3,
+			"containers": [
+				{"name": "nginx", "image": "nginx:1.21", "resources": {"limits": {"cpu": 0.5}}},
+				{"name": "sidecar", "image": "gcr.io/proxy:latest"}
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]interface{}{"object": object, "oldObject": nil}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{`1 + 2 * 3`, int64(7)},
+		{`(1 + 2) * 3`, int64(9)},
+		{`7 / 2`, int64(3)},
+		{`7 % 4`, int64(3)},
+		{`7.0 / 2.0`, 3.5},
+		{`-2 - -3`, int64(1)},
+		{`0x10`, int64(16)},
+		{`1e3`, 1000.0},
+		{`"a" + 'b'`, "ab"},
+		{`"a\tbA"`, "a\tbA"},
+		{`[1, 2] + [3]`, []interface{}{int64(1), int64(2), int64(3)}},
+		{`{"a": 1}`, map[string]interface{}{"a": int64(1)}},
+		{`double(1) == 1.0`, true},
+		{`[1, "a"] == [1, "a"]`, true},
+		{`{"a": [1]} != {"a": [2]}`, true},
+		{`null == null`, true},
+		{`"b" > "a" && 2 >= 2 && 1.0 < 1.5`, true},
+		{`!true || false`, false},
+		{`true ? "yes" : "no"`, "yes"},
+		{`false ? 1 : 2 + 3`, int64(5)},
+		{`object.metadata.name`, "web"},
+		{`object.metadata.labels["tier"]`, "frontend"},
+		{`object.spec.containers[1].name`, "sidecar"},
+		{`object.spec.replicas * 2`, int64(6)},
+		{`object.spec.containers[0].resources.limits.cpu`, 0.5},
+		{`oldObject == null`, true},
+		{`"app" in object.metadata.labels`, true},
+		{`"web" in ["api", "web"]`, true},
+		{`has(object.metadata.labels)`, true},
+		{`has(object.metadata.annotations)`, false},
+		{`has(object.metadata.annotations) && has(object.metadata.annotations.owner)`, false},
+		{`has(oldObject.metadata)`, false},
+		{`size(object.spec.containers)`, int64(2)},
+		{`object.metadata.labels.size()`, int64(2)},
+		{`"héllo".size()`, int64(5)},
+		{`object.spec.containers.all(c, has(c.image))`, true},
+		{`object.spec.containers.exists(c, c.image.endsWith(":latest"))`, true},
+		{`object.spec.containers.exists_one(c, c.name.startsWith("s"))`, true},
+		{`object.spec.containers.filter(c, c.image.contains("/")).map(c, c.name)`, []interface{}{"sidecar"}},
+		{`object.spec.containers.map(c, c.name == "nginx", c.image)`, []interface{}{"nginx:1.21"}},
+		{`object.metadata.labels.map(k, k)`, []interface{}{"app", "tier"}},
+		{`[1, 2, 3].map(x, [4, 5].map(y, x * y))`, []interface{}{
+			[]interface{}{int64(4), int64(5)},
+			[]interface{}{int64(8), int64(10)},
+			[]interface{}{int64(12), int64(15)},
+		}},
+		{`object.metadata.name.matches("^w.b$")`, true},
+		{`matches("abc", "^b")`, false},
+		{`object.metadata.name.indexOf("b")`, int64(2)},
+		{`"Hello".lowerAscii() + "Hello".upperAscii()`, "helloHELLO"},
+		{`"a,b".split(",")`, []interface{}{"a", "b"}},
+		{`["a", "b"].join("-")`, "a-b"},
+		{`int("42") + int(2.9)`, int64(44)},
+		{`double(1) / 4.0`, 0.25},
+		{`1u + 2u`, int64(3)},
+		{`string(1) + string(true) + string(1.5)`, "1true1.5"},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr, "object", "oldObject")
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.expr, err)
+			continue
+		}
+		got, err := p.Eval(testVars(t))
+		if err != nil {
+			t.Errorf("Eval(%s): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Eval(%s) = %#v, want %#v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// && 和 || 一边的结果能确定整个表达式的值时，忽略另一边的错误
+func TestEvalLogicalErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want interface{}
+		err  string
+	}{
+		{`object.missing == 1 || true`, true, ""},
+		{`false && object.missing == 1`, false, ""},
+		{`object.missing == 1 && false`, false, ""},
+		{`object.missing == 1 || false`, nil, "no such key: missing"},
+		{`[0, -1].all(x, 1 / x > 0)`, false, ""},
+		{`[0, 1].exists(x, 1 / x > 0)`, true, ""},
+		{`[0].exists(x, 1 / x > 0)`, nil, "division by zero"},
+		{`object.spec.replicas || true`, true, ""},
+		{`object.spec.replicas || false`, nil, "no such overload"},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr, "object", "oldObject")
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.expr, err)
+			continue
+		}
+		got, err := p.Eval(testVars(t))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Eval(%s) error = %v, want %q", tt.expr, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Eval(%s) = %v, %v, want %v", tt.expr, got, err, tt.want)
+		}
+	}
+}
+
+// 变量是 dyn，和变量有关的类型错误在求值时才发现
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{`object.metadata.namespace`, "no such key: namespace"},
+		{`has(object.metadata.annotations.owner)`, "no such key: annotations"},
+		{`object.spec.containers[2]`, "index out of bounds"},
+		{`object.metadata.name.foo`, "no such key: foo"},
+		{`object.spec.replicas + "a"`, "no such overload"},
+		{`object.spec.replicas * 1.5`, "no such overload"},
+		{`object.spec.replicas == 3.0`, ""},
+		{`1 / (object.spec.replicas - 3)`, "division by zero"},
+		{`object.spec.replicas ? 2 : 3`, "no such overload"},
+		{`object.spec.containers.all(c, c.name)`, "no such overload"},
+		{`int(object.metadata.name)`, "type conversion error"},
+		{`"a".matches("(" + object.metadata.name)`, "missing closing )"},
+		{`object.spec.replicas.matches("a")`, "no such overload"},
+		{`object.spec.containers.map(c, c.name) + [1]`, ""},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr, "object", "oldObject")
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.expr, err)
+			continue
+		}
+		_, err = p.Eval(testVars(t))
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("Eval(%s): %v", tt.expr, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Eval(%s) error = %v, want %q", tt.expr, err, tt.err)
+		}
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	p, err := Compile(`object.metadata.name`, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.EvalBool(testVars(t)); err == nil || !strings.Contains(err.Error(), "expected bool, got string") {
+		t.Errorf("EvalBool() error = %v, want %q", err, "expected bool, got string")
+	}
+}
+
+func TestCostLimit(t *testing.T) {
+	p, err := Compile(`object.spec.containers.all(c, object.metadata.name.matches("^w.b$"))`, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.EvalBool(testVars(t)); err != nil {
+		t.Errorf("EvalBool(): %v", err)
+	}
+
+	// 三层 map 求值一百万次，超过上限
+	p, err = Compile(`object.list.map(a, object.list.map(b, object.list.map(c, a + b + c))).size()`, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := make([]interface{}, 100)
+	for i := range list {
+		list[i] = int64(i)
+	}
+	vars := map[string]interface{}{"object": map[string]interface{}{"list": list}}
+	if _, err := p.Eval(vars); err == nil || !strings.Contains(err.Error(), "cost limit") {
+		t.Errorf("Eval() error = %v, want the cost limit", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{``, "Syntax error"},
+		{`1 +`, "Syntax error"},
+		{`objct.metadata`, "undeclared reference to 'objct'"},
+		{`foo(1)`, "undeclared reference to 'foo'"},
+		{`object.size(1)`, "found no matching overload for 'size'"},
+		{`has(object)`, "invalid argument to has() macro"},
+		{`[1].all(1, true)`, "argument must be a simple name"},
+		{`[1].all(x, y)`, "undeclared reference to 'y'"},
+		{`"abc`, "Syntax error"},
+		{`"\q"`, "Syntax error"},
+		// 常量的类型错误在编译时发现，int 和 double 不会自动转换
+		{`1 + "a"`, "found no matching overload for '_+_'"},
+		{`7 / 2.0`, "found no matching overload for '_/_'"},
+		{`1 || true`, "expected type 'bool' but found 'int'"},
+		{`[1].all(x, x)`, "expected type 'bool' but found 'int'"},
+		{`int("x")`, "type conversion error"},
+		// 常量正则表达式在编译时检查
+		{`"a".matches("(")`, "missing closing )"},
+		{`matches(object.metadata.name, "[")`, "missing closing ]"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.expr, "object")
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Compile(%s) error = %v, want %q", tt.expr, err, tt.err)
+		}
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	got, err := FromJSON([]byte(`{"a": 1, "b": 1.5, "c": [2, 1e20]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": int64(1), "b": 1.5, "c": []interface{}{int64(2), 1e20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromJSON() = %#v, want %#v", got, want)
+	}
+
+	if _, err := FromJSON([]byte(`{} {}`)); err == nil {
+		t.Error("FromJSON() with extra data should fail")
+	}
+}
@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	defaultRegistry  = "docker.io"
+	officialRepoName = "library"
+	latestTag        = "latest"
+)
+
+// ImageRef 是解析后的镜像地址，例如 nginx 解析为 docker.io/library/nginx:latest
+type ImageRef struct {
+	Registry   string // 镜像仓库地址，例如 docker.io、gcr.io、localhost:5000
+	Repository string // 仓库内的路径，例如 library/nginx
+	Tag        string // 没有指定 tag 和 digest 时为 latest
+	Digest     string // 例如 sha256:...
+}
+
+// ParseImage 按照 docker 的规则解析镜像地址
+func ParseImage(image string) (ImageRef, error) {
+	var ref ImageRef
+	if image == "" || strings.ContainsAny(image, " \t\n") {
+		return ref, fmt.Errorf("invalid image %q", image)
+	}
+
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if !strings.Contains(ref.Digest, ":") {
+			return ref, fmt.Errorf("invalid digest of image %q", image)
+		}
+	}
+	// tag 在最后一个路径之后，避免把仓库的端口当作 tag
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = latestTag
+	}
+
+	// 第一段包含 . 或 : 或者是 localhost 时才是仓库地址
+	if i := strings.Index(name, "/"); i >= 0 &&
+		(strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		ref.Registry, ref.Repository = name[:i], name[i+1:]
+	} else {
+		ref.Registry, ref.Repository = defaultRegistry, name
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = officialRepoName + "/" + ref.Repository
+	}
+	if ref.Repository == "" || strings.HasSuffix(ref.Repository, "/") {
+		return ref, fmt.Errorf("invalid image %q", image)
+	}
+
+	return ref, nil
+}
+
+// Name 返回包含仓库地址的镜像名称，不包含 tag 和 digest
+func (r ImageRef) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// IsLatest 表示镜像是否使用了 latest tag（包括没有指定 tag 的情况）
+func (r ImageRef) IsLatest() bool {
+	return r.Tag == latestTag
+}
+
+// MatchImage 判断镜像名称是否匹配 glob 模式，模式匹配名称本身或者它的某一级目录，
+// 例如 gcr.io、*.azurecr.io、registry.example.com/team/* 都匹配 gcr.io/team/app 这样的镜像
+func MatchImage(pattern string, ref ImageRef) bool {
+	name := ref.Name()
+	if ok, _ := path.Match(pattern, name); ok {
+		return true
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] != '/' {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name[:i]); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,87 @@
+package pkg
+
+import "testing"
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  ImageRef
+	}{
+		// Docker Hub 的短名称
+		{"nginx", ImageRef{"docker.io", "library/nginx", "latest", ""}},
+		{"nginx:1.19", ImageRef{"docker.io", "library/nginx", "1.19", ""}},
+		{"bitnami/redis:6.0", ImageRef{"docker.io", "bitnami/redis", "6.0", ""}},
+		{"docker.io/nginx", ImageRef{"docker.io", "library/nginx", "latest", ""}},
+		{"docker.io/library/nginx:latest", ImageRef{"docker.io", "library/nginx", "latest", ""}},
+		// 仓库地址
+		{"gcr.io/project/app:v1", ImageRef{"gcr.io", "project/app", "v1", ""}},
+		{"registry.example.com/team/sub/app", ImageRef{"registry.example.com", "team/sub/app", "latest", ""}},
+		// 仓库的端口不是 tag
+		{"localhost:5000/app", ImageRef{"localhost:5000", "app", "latest", ""}},
+		{"registry.example.com:8443/team/app:v2", ImageRef{"registry.example.com:8443", "team/app", "v2", ""}},
+		{"localhost/app:dev", ImageRef{"localhost", "app", "dev", ""}},
+		// 不包含 . 和 : 的第一段是 Docker Hub 的用户名
+		{"myregistry/app", ImageRef{"docker.io", "myregistry/app", "latest", ""}},
+		// digest
+		{"nginx@" + testDigest, ImageRef{"docker.io", "library/nginx", "", testDigest}},
+		{"gcr.io/project/app:v1@" + testDigest, ImageRef{"gcr.io", "project/app", "v1", testDigest}},
+		{"localhost:5000/app@" + testDigest, ImageRef{"localhost:5000", "app", "", testDigest}},
+	}
+	for _, tt := range tests {
+		got, err := ParseImage(tt.image)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseImage(%q) = %+v, %v, want %+v", tt.image, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseImageErrors(t *testing.T) {
+	for _, image := range []string{
+		"",
+		"nginx latest",
+		"nginx@sha256",
+		"gcr.io/",
+		"localhost:5000/",
+	} {
+		if ref, err := ParseImage(image); err == nil {
+			t.Errorf("ParseImage(%q) = %+v, want an error", image, ref)
+		}
+	}
+}
+
+func TestMatchImage(t *testing.T) {
+	tests := []struct {
+		pattern string
+		image   string
+		want    bool
+	}{
+		{"docker.io", "nginx", true},
+		{"docker.io/library/*", "nginx", true},
+		{"docker.io/library/nginx", "nginx:1.19", true},
+		{"docker.io/bitnami/*", "nginx", false},
+		{"gcr.io", "gcr.io/project/app", true},
+		{"gcr.io/project", "gcr.io/project/app", true},
+		{"gcr.io/project/*", "gcr.io/project/app", true},
+		{"gcr.io/other/*", "gcr.io/project/app", false},
+		// * 不跨越 /，但是模式可以匹配其中一级目录
+		{"gcr.io/*", "gcr.io/project/team/app", true},
+		{"*.azurecr.io", "team.azurecr.io/app", true},
+		{"*.azurecr.io", "azurecr.io/app", false},
+		// 前缀相同的仓库不匹配
+		{"gcr.io", "gcr.io.evil.com/app", false},
+		{"registry.example.com", "registry.example.com:8443/app", false},
+		{"registry.example.com:*", "registry.example.com:8443/app", true},
+		{"localhost:5000", "localhost:5000/app@" + testDigest, true},
+	}
+	for _, tt := range tests {
+		ref, err := ParseImage(tt.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := MatchImage(tt.pattern, ref); got != tt.want {
+			t.Errorf("MatchImage(%q, %q) = %v, want %v", tt.pattern, tt.image, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	AnnotationInjectKey = "io.ydzs.admission-registry/inject" // io.ydzs.admission-registry/inject=yes/true/on/y 或者 no/false/off/n
+	NamespaceInjectKey  = "admission-registry-injection"      // 命名空间的标签，admission-registry-injection=enabled/disabled
+
+	statusInjected = "injected"
+)
+
+// Sidecar 是注入到 Pod 中的容器和卷
+type Sidecar struct {
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	Containers     []corev1.Container `json:"containers,omitempty"`
+	Volumes        []corev1.Volume    `json:"volumes,omitempty"`
+}
+
+// SidecarTemplate 是 YAML 格式的 Sidecar 的模板，使用 text/template 语法，模板的数据是要注入的 Pod
+//
+//	containers:
+//	- name: proxy
+//	  image: envoyproxy/envoy:v1.18.3
+//	  args: ["--service-node", "{{ .Name }}.{{ .Namespace }}"]
+//	  volumeMounts:
+//	  - name: proxy-config
+//	    mountPath: /etc/envoy
+//	volumes:
+//	- name: proxy-config
+//	  configMap:
+//	    name: proxy-config
+type SidecarTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseSidecarTemplate 解析 Sidecar 的模板，并用一个空的 Pod 检查模板是否有效
+func ParseSidecarTemplate(data []byte) (*SidecarTemplate, error) {
+	tmpl, err := template.New("sidecar").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &SidecarTemplate{tmpl: tmpl}
+	if _, err := t.Render(&corev1.Pod{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render 使用 pod 渲染模板
+func (t *SidecarTemplate) Render(pod *corev1.Pod) (*Sidecar, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, pod); err != nil {
+		return nil, err
+	}
+
+	var sidecar Sidecar
+	if err := yaml.UnmarshalStrict(buf.Bytes(), &sidecar); err != nil {
+		return nil, err
+	}
+	for _, c := range append(append([]corev1.Container{}, sidecar.InitContainers...), sidecar.Containers...) {
+		if c.Name == "" || c.Image == "" {
+			return nil, fmt.Errorf("the name and image of containers are required")
+		}
+	}
+	for _, v := range sidecar.Volumes {
+		if v.Name == "" {
+			return nil, fmt.Errorf("the name of volumes is required")
+		}
+	}
+	return &sidecar, nil
+}
+
+// SidecarStore 保存当前的 Sidecar 模板，模板文件变化后可以重新加载
+type SidecarStore struct {
+	fileStore
+}
+
+// NewSidecarStore 返回保存固定模板的 SidecarStore
+func NewSidecarStore(t *SidecarTemplate) *SidecarStore {
+	return &SidecarStore{fileStore{name: "sidecar template", value: t}}
+}
+
+// LoadSidecarStore 从模板文件加载 SidecarStore
+func LoadSidecarStore(file string) (*SidecarStore, error) {
+	s := &SidecarStore{fileStore{name: "sidecar template", file: file, parse: func(data []byte) (interface{}, error) {
+		return ParseSidecarTemplate(data)
+	}}}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Template 返回当前的模板
+func (s *SidecarStore) Template() *SidecarTemplate {
+	return s.load().(*SidecarTemplate)
+}
+
+// injectionRequired 判断是否需要注入，命名空间的标签为 disabled 时不注入，否则 Pod 的 annotation 优先于命名空间的标签
+func injectionRequired(pod *corev1.Pod, namespaces corelisters.NamespaceLister, namespace string) (bool, error) {
+	annotations := pod.GetAnnotations()
+	if strings.ToLower(annotations[AnnotationStatusKey]) == statusInjected {
+		return false, nil
+	}
+
+	var label string
+	if namespaces != nil {
+		ns, err := namespaces.Get(namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if err == nil {
+			label = ns.Labels[NamespaceInjectKey]
+		}
+	}
+	if label == "disabled" {
+		return false, nil
+	}
+
+	switch strings.ToLower(annotations[AnnotationInjectKey]) {
+	case "y", "yes", "true", "on":
+		return true, nil
+	case "n", "no", "false", "off":
+		return false, nil
+	}
+	return label == "enabled", nil
+}
+
+// injectSidecar 把 sidecar 注入到 pod 中并返回对应的 patch，已经存在的同名容器和卷不会重复注入
+func injectSidecar(pod *corev1.Pod, sidecar *Sidecar) (patch []patchOperation) {
+	patch = append(patch, addContainers(&pod.Spec.InitContainers, sidecar.InitContainers, "/spec/initContainers")...)
+	patch = append(patch, addContainers(&pod.Spec.Containers, sidecar.Containers, "/spec/containers")...)
+	patch = append(patch, addVolumes(&pod.Spec.Volumes, sidecar.Volumes, "/spec/volumes")...)
+	patch = append(patch, mutateAnnotations(pod.GetAnnotations(), map[string]string{
+		AnnotationStatusKey: statusInjected,
+	})...)
+
+	return patch
+}
+
+// addContainers 把 added 追加到 target 中，patch 在数组为空时需要添加整个数组，否则追加到数组的最后
+func addContainers(target *[]corev1.Container, added []corev1.Container, basePath string) (patch []patchOperation) {
+	names := map[string]bool{}
+	for _, c := range *target {
+		names[c.Name] = true
+	}
+
+	first := len(*target) == 0
+	for _, c := range added {
+		if names[c.Name] {
+			klog.Infof("Container %s already exists, skip injecting it", c.Name)
+			continue
+		}
+		names[c.Name] = true
+		*target = append(*target, c)
+
+		if first {
+			first = false
+			patch = append(patch, patchOperation{Op: "add", Path: basePath, Value: []corev1.Container{c}})
+		} else {
+			patch = append(patch, patchOperation{Op: "add", Path: basePath + "/-", Value: c})
+		}
+	}
+	return patch
+}
+
+func addVolumes(target *[]corev1.Volume, added []corev1.Volume, basePath string) (patch []patchOperation) {
+	names := map[string]bool{}
+	for _, v := range *target {
+		names[v.Name] = true
+	}
+
+	first := len(*target) == 0
+	for _, v := range added {
+		if names[v.Name] {
+			klog.Infof("Volume %s already exists, skip injecting it", v.Name)
+			continue
+		}
+		names[v.Name] = true
+		*target = append(*target, v)
+
+		if first {
+			first = false
+			patch = append(patch, patchOperation{Op: "add", Path: basePath, Value: []corev1.Volume{v}})
+		} else {
+			patch = append(patch, patchOperation{Op: "add", Path: basePath + "/-", Value: v})
+		}
+	}
+	return patch
+}
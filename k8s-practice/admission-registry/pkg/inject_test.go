@@ -0,0 +1,264 @@
+package pkg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const testSidecar = `
+initContainers:
+- name: init-log
+  image: busybox
+containers:
+- name: log-agent
+  image: busybox
+  env:
+  - name: POD
+    value: "{{ .Name }}.{{ .Namespace }}"
+  volumeMounts:
+  - name: app-log
+    mountPath: /var/log
+volumes:
+- name: app-log
+  emptyDir: {}
+`
+
+func newInjectServer(t *testing.T, namespaces ...*corev1.Namespace) *WebhookServer {
+	tmpl, err := ParseSidecarTemplate([]byte(testSidecar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range namespaces {
+		if err := indexer.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &WebhookServer{
+		Policies:   NewPolicyStore(&Policy{}),
+		Rules:      NewRuleStore(&Rules{}),
+		Resources:  NewResourceStore(&ResourcePolicy{}),
+		Security:   NewSecurityStore(&SecurityPolicy{}),
+		Sidecar:    NewSidecarStore(tmpl),
+		Namespaces: corelisters.NewNamespaceLister(indexer),
+	}
+}
+
+func testPodSpec(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: annotations},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}},
+		},
+	}
+}
+
+// mutatePod 调用 mutate 并把返回的 patch 应用到 pod 上，返回 patch 和修改之后的 Pod
+func mutatePod(t *testing.T, s *WebhookServer, namespace string, pod *corev1.Pod) ([]patchOperation, *corev1.Pod) {
+	t.Helper()
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := s.mutate(&admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:       "1",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace: namespace,
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	if !resp.Allowed {
+		t.Fatalf("mutate() = %+v, want allowed", resp.Result)
+	}
+	if resp.Patch == nil {
+		return nil, pod
+	}
+
+	var patch []patchOperation
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range patch {
+		if doc, _, err = applyPatch(doc, op); err != nil {
+			t.Fatalf("the patch %s is invalid: %v", resp.Patch, err)
+		}
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mutated corev1.Pod
+	if err := json.Unmarshal(data, &mutated); err != nil {
+		t.Fatal(err)
+	}
+	return patch, &mutated
+}
+
+func containerNames(containers []corev1.Container) []string {
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestInjectWithoutVolumes(t *testing.T) {
+	s := newInjectServer(t)
+	patch, pod := mutatePod(t, s, "prod", testPodSpec(map[string]string{AnnotationInjectKey: "true"}))
+
+	// 没有 initContainers 和 volumes 时添加整个数组
+	paths := make([]string, len(patch))
+	for i, op := range patch {
+		paths[i] = op.Op + " " + op.Path
+	}
+	want := []string{
+		"add /spec/initContainers",
+		"add /spec/containers/-",
+		"add /spec/volumes",
+		"add /metadata/annotations/io.ydzs.admission-registry~1status",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("patch = %q, want %q", paths, want)
+	}
+
+	if got := containerNames(pod.Spec.InitContainers); !reflect.DeepEqual(got, []string{"init-log"}) {
+		t.Errorf("initContainers = %v", got)
+	}
+	if got := containerNames(pod.Spec.Containers); !reflect.DeepEqual(got, []string{"nginx", "log-agent"}) {
+		t.Errorf("containers = %v", got)
+	}
+	if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].Name != "app-log" || pod.Spec.Volumes[0].EmptyDir == nil {
+		t.Errorf("volumes = %+v", pod.Spec.Volumes)
+	}
+	// 模板使用请求中的命名空间渲染
+	if env := pod.Spec.Containers[1].Env; len(env) != 1 || env[0].Value != "web.prod" {
+		t.Errorf("env = %+v", env)
+	}
+	wantAnnotations := map[string]string{AnnotationInjectKey: "true", AnnotationStatusKey: "injected"}
+	if !reflect.DeepEqual(pod.Annotations, wantAnnotations) {
+		t.Errorf("annotations = %v, want %v", pod.Annotations, wantAnnotations)
+	}
+
+	// 注入之后再次 mutate 不会重复注入
+	if patch, _ := mutatePod(t, s, "prod", pod); patch != nil {
+		t.Errorf("patch = %+v for an injected pod, want none", patch)
+	}
+}
+
+func TestInjectWithVolumes(t *testing.T) {
+	s := newInjectServer(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "prod",
+		Labels: map[string]string{NamespaceInjectKey: "enabled"},
+	}})
+	pod := testPodSpec(nil)
+	pod.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+	pod.Spec.Volumes = []corev1.Volume{{Name: "data"}, {Name: "app-log"}}
+
+	patch, pod := mutatePod(t, s, "prod", pod)
+	paths := make([]string, len(patch))
+	for i, op := range patch {
+		paths[i] = op.Op + " " + op.Path
+	}
+	// 同名的卷已经存在，不再注入；没有 annotations 时添加整个 map
+	want := []string{
+		"add /spec/initContainers/-",
+		"add /spec/containers/-",
+		"add /metadata/annotations",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("patch = %q, want %q", paths, want)
+	}
+	if got := containerNames(pod.Spec.InitContainers); !reflect.DeepEqual(got, []string{"init", "init-log"}) {
+		t.Errorf("initContainers = %v", got)
+	}
+	if len(pod.Spec.Volumes) != 2 {
+		t.Errorf("volumes = %+v", pod.Spec.Volumes)
+	}
+	if pod.Annotations[AnnotationStatusKey] != "injected" {
+		t.Errorf("annotations = %v", pod.Annotations)
+	}
+}
+
+func TestInjectionRequired(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "enabled",
+		Labels: map[string]string{NamespaceInjectKey: "enabled"},
+	}})
+	indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "disabled",
+		Labels: map[string]string{NamespaceInjectKey: "disabled"},
+	}})
+	indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "plain"}})
+	namespaces := corelisters.NewNamespaceLister(indexer)
+
+	tests := []struct {
+		namespace   string
+		annotations map[string]string
+		want        bool
+	}{
+		{"enabled", nil, true},
+		{"enabled", map[string]string{AnnotationInjectKey: "off"}, false},
+		{"enabled", map[string]string{AnnotationStatusKey: "injected"}, false},
+		{"disabled", map[string]string{AnnotationInjectKey: "true"}, false},
+		{"plain", nil, false},
+		{"plain", map[string]string{AnnotationInjectKey: "Yes"}, true},
+		{"missing", nil, false},
+		{"missing", map[string]string{AnnotationInjectKey: "y"}, true},
+	}
+	for _, tt := range tests {
+		got, err := injectionRequired(testPodSpec(tt.annotations), namespaces, tt.namespace)
+		if err != nil || got != tt.want {
+			t.Errorf("injectionRequired(%s, %v) = %v, %v, want %v", tt.namespace, tt.annotations, got, err, tt.want)
+		}
+	}
+
+	// 没有 lister 时只根据 annotation 判断
+	if got, _ := injectionRequired(testPodSpec(nil), nil, "enabled"); got {
+		t.Error("injectionRequired() without a lister = true, want false")
+	}
+}
+
+func TestInjectDisabled(t *testing.T) {
+	s := newInjectServer(t)
+	s.Sidecar = nil
+	if patch, _ := mutatePod(t, s, "prod", testPodSpec(map[string]string{AnnotationInjectKey: "true"})); patch != nil {
+		t.Errorf("patch = %+v without a sidecar template, want none", patch)
+	}
+}
+
+func TestParseSidecarTemplateErrors(t *testing.T) {
+	tests := []string{
+		"containers:\n- name: {{ .Name",
+		"containers:\n- image: busybox",
+		"volumes:\n- emptyDir: {}",
+		"container: []",
+	}
+	for _, tmpl := range tests {
+		if _, err := ParseSidecarTemplate([]byte(tmpl)); err == nil {
+			t.Errorf("ParseSidecarTemplate(%q) should fail", tmpl)
+		}
+	}
+}
+
+func TestMutateAnnotations(t *testing.T) {
+	patch := mutateAnnotations(map[string]string{"a": "b"}, map[string]string{"x/y": "z"})
+	want := []patchOperation{{Op: "add", Path: "/metadata/annotations/x~1y", Value: "z"}}
+	if !reflect.DeepEqual(patch, want) {
+		t.Errorf("mutateAnnotations() = %+v, want %+v", patch, want)
+	}
+}
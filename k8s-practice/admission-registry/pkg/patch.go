@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"admission-registry/pkg/expr"
+)
+
+// splitPointer 把 JSON Pointer（RFC 6901）拆分成每一级的 key
+func splitPointer(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid path %q, it must start with /", path)
+	}
+
+	keys := strings.Split(path[1:], "/")
+	for i, key := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+	}
+	return keys, nil
+}
+
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// applyPatch 把 patch 应用到 doc 上，返回修改后的 doc。
+// add 的上级对象不存在时会先创建，创建上级对象的 patch 会和 patch 一起返回，
+// 这样返回的 patch 可以直接交给 apiserver
+func applyPatch(doc interface{}, patch patchOperation) (interface{}, []patchOperation, error) {
+	keys, err := splitPointer(patch.Path)
+	if err != nil {
+		return doc, nil, err
+	}
+
+	var ops []patchOperation
+	if patch.Op == "add" {
+		path := ""
+		parent := doc
+		for _, key := range keys[:len(keys)-1] {
+			path += "/" + escapePointer(key)
+			m, ok := parent.(map[string]interface{})
+			if !ok {
+				break
+			}
+			if v, ok := m[key]; ok && v != nil {
+				parent = v
+				continue
+			}
+			m[key] = map[string]interface{}{}
+			parent = m[key]
+			ops = append(ops, patchOperation{Op: "add", Path: path, Value: map[string]interface{}{}})
+		}
+	}
+
+	// 对象和返回的 patch 各自复制一份值，避免后面修改对象时影响 patch，
+	// 这样也把 map[string]string 这样的值转换成了表达式使用的值
+	value, err := expr.Normalize(patch.Value)
+	if err != nil {
+		return doc, nil, err
+	}
+	opValue, _ := expr.Normalize(value)
+	if doc, err = patchValue(doc, keys, patch.Op, value); err != nil {
+		return doc, nil, fmt.Errorf("%s %s: %v", patch.Op, patch.Path, err)
+	}
+	return doc, append(ops, patchOperation{Op: patch.Op, Path: patch.Path, Value: opValue}), nil
+}
+
+// patchValue 修改 node 中 keys 指向的值，返回修改后的 node
+func patchValue(node interface{}, keys []string, op string, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		if op == "remove" {
+			return nil, fmt.Errorf("can't remove the whole object")
+		}
+		return value, nil
+	}
+	key := keys[0]
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		child, ok := v[key]
+		if len(keys) > 1 {
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			child, err := patchValue(child, keys[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = child
+			return v, nil
+		}
+
+		switch op {
+		case "add":
+			v[key] = value
+		case "replace":
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			v[key] = value
+		case "remove":
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			delete(v, key)
+		default:
+			return nil, fmt.Errorf("unsupported operation")
+		}
+		return v, nil
+	case []interface{}:
+		// - 表示添加到数组的最后
+		if key == "-" && op == "add" && len(keys) == 1 {
+			return append(v, value), nil
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > len(v) || i == len(v) && (op != "add" || len(keys) > 1) {
+			return nil, fmt.Errorf("invalid index %q", key)
+		}
+		if len(keys) > 1 {
+			child, err := patchValue(v[i], keys[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = child
+			return v, nil
+		}
+
+		switch op {
+		case "add":
+			v = append(v, nil)
+			copy(v[i+1:], v[i:])
+			v[i] = value
+		case "replace":
+			v[i] = value
+		case "remove":
+			v = append(v[:i], v[i+1:]...)
+		default:
+			return nil, fmt.Errorf("unsupported operation")
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("path not found")
+}
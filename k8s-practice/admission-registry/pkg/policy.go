@@ -0,0 +1,212 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// Policy 是镜像准入策略，Pod 需要满足所有匹配的规则
+//
+//	rules:
+//	- name: production
+//	  namespaces: ["prod-*"]
+//	  selector:
+//	    matchLabels:
+//	      tier: frontend
+//	  allowedRegistries: ["registry.example.com", "gcr.io/my-project/*"]
+//	  deniedRegistries: ["docker.io"]
+//	  denyLatestTag: true
+//	  requireDigest: true
+type Policy struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule 是一条策略规则，没有指定 namespaces 和 selector 时匹配所有 Pod
+type Rule struct {
+	Name string `json:"name"`
+	// Namespaces 是命名空间的 glob 模式
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector 选择 Pod 的标签
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// AllowedRegistries 不为空时，镜像必须匹配其中一个 glob 模式
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+	// DeniedRegistries 优先于 AllowedRegistries
+	DeniedRegistries []string `json:"deniedRegistries,omitempty"`
+	// DenyLatestTag 禁止 latest tag 以及没有指定 tag 的镜像
+	DenyLatestTag bool `json:"denyLatestTag,omitempty"`
+	// RequireDigest 要求镜像通过 digest 指定
+	RequireDigest bool `json:"requireDigest,omitempty"`
+
+	selector labels.Selector
+}
+
+// ContainerImage 是 Pod 中某个容器使用的镜像
+type ContainerImage struct {
+	Kind  string // container、initContainer 或 ephemeralContainer
+	Name  string
+	Image string
+}
+
+// ParsePolicy 解析 YAML 或 JSON 格式的策略
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range policy.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("rule %d is empty", i)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i)
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// WhitelistPolicy 返回只允许 registries 中镜像仓库的策略，兼容 WHITELIST_REGISTRIES 环境变量
+func WhitelistPolicy(registries []string) *Policy {
+	rule := &Rule{Name: "whitelist"}
+	for _, registry := range registries {
+		// 原来按前缀匹配，所以 docker.io/ 这样的配置也是有效的
+		if registry = strings.TrimSuffix(strings.TrimSpace(registry), "/"); registry != "" {
+			rule.AllowedRegistries = append(rule.AllowedRegistries, registry)
+		}
+	}
+	if len(rule.AllowedRegistries) == 0 {
+		return &Policy{}
+	}
+
+	rule.compile()
+	return &Policy{Rules: []*Rule{rule}}
+}
+
+func (r *Rule) compile() error {
+	patterns := append(append(append([]string{}, r.Namespaces...), r.AllowedRegistries...), r.DeniedRegistries...)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+
+	r.selector = labels.Everything()
+	if r.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(r.Selector)
+		if err != nil {
+			return err
+		}
+		r.selector = selector
+	}
+
+	return nil
+}
+
+// Matches 判断规则是否作用于命名空间 namespace 中标签为 podLabels 的 Pod
+func (r *Rule) Matches(namespace string, podLabels map[string]string) bool {
+	if len(r.Namespaces) > 0 && !matchNamespace(r.Namespaces, namespace) {
+		return false
+	}
+
+	return r.selector.Matches(labels.Set(podLabels))
+}
+
+// matchNamespace 判断命名空间是否匹配其中一个 glob 模式
+func matchNamespace(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// check 返回镜像违反规则的原因，没有违反时返回空字符串
+func (r *Rule) check(ref ImageRef) string {
+	for _, pattern := range r.DeniedRegistries {
+		if MatchImage(pattern, ref) {
+			return fmt.Sprintf("registry %s is denied", pattern)
+		}
+	}
+	if len(r.AllowedRegistries) > 0 {
+		allowed := false
+		for _, pattern := range r.AllowedRegistries {
+			if MatchImage(pattern, ref) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("only images from %v are allowed", r.AllowedRegistries)
+		}
+	}
+	if r.RequireDigest && ref.Digest == "" {
+		return "the image must be pinned by digest"
+	}
+	// 通过 digest 指定的镜像不会再变化，所以不受 latest tag 的限制
+	if r.DenyLatestTag && ref.IsLatest() && ref.Digest == "" {
+		return "the latest tag is not allowed"
+	}
+
+	return ""
+}
+
+// Validate 返回 Pod 中的镜像违反策略的原因
+func (p *Policy) Validate(namespace string, podLabels map[string]string, images []ContainerImage) []string {
+	var violations []string
+	for _, image := range images {
+		ref, err := ParseImage(image.Image)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s %s: %v", image.Kind, image.Name, err))
+			continue
+		}
+
+		for _, rule := range p.Rules {
+			if !rule.Matches(namespace, podLabels) {
+				continue
+			}
+			if reason := rule.check(ref); reason != "" {
+				violations = append(violations, fmt.Sprintf("%s %s: image %s violates rule %s: %s",
+					image.Kind, image.Name, image.Image, rule.Name, reason))
+			}
+		}
+	}
+
+	return violations
+}
+
+// PolicyStore 保存当前的策略，从文件加载时可以在文件变化后重新加载
+type PolicyStore struct {
+	fileStore
+}
+
+// NewPolicyStore 返回保存固定策略的 PolicyStore
+func NewPolicyStore(policy *Policy) *PolicyStore {
+	return &PolicyStore{fileStore{name: "policy", value: policy}}
+}
+
+// LoadPolicyStore 从策略文件加载 PolicyStore
+func LoadPolicyStore(file string) (*PolicyStore, error) {
+	s := &PolicyStore{fileStore{name: "policy", file: file, parse: func(data []byte) (interface{}, error) {
+		return ParsePolicy(data)
+	}}}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Policy 返回当前的策略
+func (s *PolicyStore) Policy() *Policy {
+	return s.load().(*Policy)
+}
@@ -0,0 +1,168 @@
+package pkg
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+rules:
+- name: registries
+  allowedRegistries: ["docker.io", "gcr.io/my-project/*"]
+  deniedRegistries: ["docker.io/evil/*"]
+- name: production
+  namespaces: ["prod-*"]
+  denyLatestTag: true
+- name: frontend
+  selector:
+    matchLabels:
+      tier: frontend
+  requireDigest: true
+`
+
+func TestRuleCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		image string
+		want  string
+	}{
+		{"allowed", Rule{AllowedRegistries: []string{"gcr.io"}}, "gcr.io/project/app:v1", ""},
+		{"not allowed", Rule{AllowedRegistries: []string{"gcr.io"}}, "nginx:1.19",
+			"only images from [gcr.io] are allowed"},
+		{"denied", Rule{DeniedRegistries: []string{"docker.io"}}, "nginx:1.19", "registry docker.io is denied"},
+		// 拒绝优先于允许
+		{"denied and allowed", Rule{AllowedRegistries: []string{"docker.io"}, DeniedRegistries: []string{"docker.io/evil/*"}},
+			"evil/miner:v1", "registry docker.io/evil/* is denied"},
+		{"latest", Rule{DenyLatestTag: true}, "nginx:latest", "the latest tag is not allowed"},
+		{"no tag", Rule{DenyLatestTag: true}, "nginx", "the latest tag is not allowed"},
+		{"tag", Rule{DenyLatestTag: true}, "nginx:1.19", ""},
+		// digest 指定的镜像不会变化
+		{"latest with digest", Rule{DenyLatestTag: true}, "nginx:latest@" + testDigest, ""},
+		{"no digest", Rule{RequireDigest: true}, "nginx:1.19", "the image must be pinned by digest"},
+		{"digest", Rule{RequireDigest: true}, "nginx@" + testDigest, ""},
+		{"no restriction", Rule{}, "nginx", ""},
+	}
+	for _, tt := range tests {
+		ref, err := ParseImage(tt.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.rule.check(ref); got != tt.want {
+			t.Errorf("%s: check(%q) = %q, want %q", tt.name, tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		labels    map[string]string
+		image     string
+		want      []string
+	}{
+		{"allowed", "default", nil, "nginx", nil},
+		{"not allowed", "default", nil, "quay.io/app:v1", []string{
+			"container app: image quay.io/app:v1 violates rule registries: only images from [docker.io gcr.io/my-project/*] are allowed",
+		}},
+		{"denied", "default", nil, "evil/miner:v1", []string{
+			"container app: image evil/miner:v1 violates rule registries: registry docker.io/evil/* is denied",
+		}},
+		{"latest in production", "prod-a", nil, "nginx", []string{
+			"container app: image nginx violates rule production: the latest tag is not allowed",
+		}},
+		{"tag in production", "prod-a", nil, "gcr.io/my-project/app:v1", nil},
+		{"frontend", "default", map[string]string{"tier": "frontend"}, "nginx:1.19", []string{
+			"container app: image nginx:1.19 violates rule frontend: the image must be pinned by digest",
+		}},
+		{"all rules", "prod-a", map[string]string{"tier": "frontend"}, "quay.io/app", []string{
+			"container app: image quay.io/app violates rule registries: only images from [docker.io gcr.io/my-project/*] are allowed",
+			"container app: image quay.io/app violates rule production: the latest tag is not allowed",
+			"container app: image quay.io/app violates rule frontend: the image must be pinned by digest",
+		}},
+		{"invalid image", "default", nil, "nginx latest", []string{
+			`container app: invalid image "nginx latest"`,
+		}},
+	}
+	for _, tt := range tests {
+		images := []ContainerImage{{Kind: "container", Name: "app", Image: tt.image}}
+		if got := policy.Validate(tt.namespace, tt.labels, images); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Validate() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePolicyErrors(t *testing.T) {
+	tests := []struct {
+		policy string
+		err    string
+	}{
+		{"rules:\n- namespaces: ['[']", "invalid pattern"},
+		{"rules:\n- allowedRegistries: ['[']", "invalid pattern"},
+		{"rules:\n- deniedRegistries: ['gcr.io/[']", "invalid pattern"},
+		{"rules:\n- selector:\n    matchExpressions:\n    - {key: tier, operator: Bad}", "rule rule-0"},
+		{"rules:\n- allowRegistries: ['gcr.io']", "unknown field"},
+		{"rules:\n-", "rule 0 is empty"},
+	}
+	for _, tt := range tests {
+		if _, err := ParsePolicy([]byte(tt.policy)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParsePolicy(%q) error = %v, want %q", tt.policy, err, tt.err)
+		}
+	}
+}
+
+func TestWhitelistPolicy(t *testing.T) {
+	policy := WhitelistPolicy([]string{" docker.io/ ", "", "gcr.io"})
+	if len(policy.Rules) != 1 || !reflect.DeepEqual(policy.Rules[0].AllowedRegistries, []string{"docker.io", "gcr.io"}) {
+		t.Fatalf("WhitelistPolicy() = %+v", policy.Rules)
+	}
+	if len(WhitelistPolicy([]string{" "}).Rules) != 0 {
+		t.Error("WhitelistPolicy() without registries should have no rules")
+	}
+}
+
+func TestPolicyStoreReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := ioutil.WriteFile(file, []byte(testPolicy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := LoadPolicyStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(store.Policy().Rules); got != 3 {
+		t.Fatalf("len(Rules) = %d, want 3", got)
+	}
+
+	// 无效的策略和空文件都不会替换当前的策略
+	for _, data := range []string{"rules:\n- allowedRegistries: ['[']", "  \n"} {
+		if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if reloaded, err := store.Reload(); reloaded || err == nil {
+			t.Errorf("Reload(%q) = %v, %v, want an error", data, reloaded, err)
+		}
+		if got := len(store.Policy().Rules); got != 3 {
+			t.Errorf("len(Rules) = %d after a failed reload, want 3", got)
+		}
+	}
+
+	if err := ioutil.WriteFile(file, []byte("rules:\n- denyLatestTag: true"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := store.Reload(); !reloaded || err != nil {
+		t.Errorf("Reload() = %v, %v, want reloaded", reloaded, err)
+	}
+	if rules := store.Policy().Rules; len(rules) != 1 || !rules[0].DenyLatestTag {
+		t.Errorf("Rules = %+v, want the new policy", rules)
+	}
+}
@@ -0,0 +1,325 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// ResourcePolicy 是容器资源的默认值和限制，和 LimitRange 中 Container 类型的限制含义相同
+//
+//	rules:
+//	- name: default
+//	  default:
+//	    cpu: 500m
+//	    memory: 512Mi
+//	  defaultRequest:
+//	    cpu: 100m
+//	    memory: 128Mi
+//	- name: production
+//	  namespaces: ["prod-*"]
+//	  max:
+//	    cpu: "4"
+//	    memory: 8Gi
+//	  maxLimitRequestRatio:
+//	    cpu: "10"
+type ResourcePolicy struct {
+	Rules []*ResourceRule `json:"rules"`
+}
+
+// ResourceRule 是一条资源规则，没有指定 namespaces 时匹配所有命名空间
+type ResourceRule struct {
+	Name string `json:"name"`
+	// Namespaces 是命名空间的 glob 模式
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Default 是容器没有设置 limits 时使用的默认值
+	Default corev1.ResourceList `json:"default,omitempty"`
+	// DefaultRequest 是容器没有设置 requests 时使用的默认值，没有设置时使用 limits
+	DefaultRequest corev1.ResourceList `json:"defaultRequest,omitempty"`
+	// Min 是 requests 和 limits 的下限
+	Min corev1.ResourceList `json:"min,omitempty"`
+	// Max 是 requests 和 limits 的上限，设置了上限的资源必须设置 limits
+	Max corev1.ResourceList `json:"max,omitempty"`
+	// MaxLimitRequestRatio 是 limits 和 requests 的最大比例，设置了比例的资源必须同时设置 requests 和 limits
+	MaxLimitRequestRatio corev1.ResourceList `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// ParseResourcePolicy 解析 YAML 或 JSON 格式的资源策略
+func ParseResourcePolicy(data []byte) (*ResourcePolicy, error) {
+	var policy ResourcePolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range policy.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("rule %d is empty", i)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i)
+		}
+		if err := rule.check(); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// check 检查规则中的值是否一致，比如默认值不能超过上限
+func (r *ResourceRule) check() error {
+	for _, pattern := range r.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+
+	for name, q := range r.MaxLimitRequestRatio {
+		if q.Cmp(resource.MustParse("1")) < 0 {
+			return fmt.Errorf("maxLimitRequestRatio of %s must be at least 1", name)
+		}
+	}
+	for name, min := range r.Min {
+		if max, ok := r.Max[name]; ok && min.Cmp(max) > 0 {
+			return fmt.Errorf("min of %s is greater than max", name)
+		}
+	}
+	for _, defaults := range []corev1.ResourceList{r.Default, r.DefaultRequest} {
+		for name, q := range defaults {
+			if min, ok := r.Min[name]; ok && q.Cmp(min) < 0 {
+				return fmt.Errorf("the default %s is less than min", name)
+			}
+			if max, ok := r.Max[name]; ok && q.Cmp(max) > 0 {
+				return fmt.Errorf("the default %s is greater than max", name)
+			}
+		}
+	}
+	for name, request := range r.DefaultRequest {
+		if limit, ok := r.Default[name]; ok && request.Cmp(limit) > 0 {
+			return fmt.Errorf("defaultRequest of %s is greater than default", name)
+		}
+	}
+
+	return nil
+}
+
+// Matches 判断规则是否作用于命名空间 namespace
+func (r *ResourceRule) Matches(namespace string) bool {
+	return len(r.Namespaces) == 0 || matchNamespace(r.Namespaces, namespace)
+}
+
+// defaults 返回命名空间 namespace 的默认 limits 和 requests，多条规则设置了同一种资源时使用前面的规则
+func (p *ResourcePolicy) defaults(namespace string) (limits, requests corev1.ResourceList) {
+	limits, requests = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, rule := range p.Rules {
+		if !rule.Matches(namespace) {
+			continue
+		}
+		for name, q := range rule.Default {
+			if _, ok := limits[name]; !ok {
+				limits[name] = q
+			}
+		}
+		for name, q := range rule.DefaultRequest {
+			if _, ok := requests[name]; !ok {
+				requests[name] = q
+			}
+		}
+	}
+	return limits, requests
+}
+
+// Default 返回给 Pod 中没有设置 requests 和 limits 的容器填充默认值的 patch
+func (p *ResourcePolicy) Default(namespace string, pod *corev1.Pod) (patch []patchOperation) {
+	limits, requests := p.defaults(namespace)
+	if len(limits) == 0 && len(requests) == 0 {
+		return nil
+	}
+
+	for i := range pod.Spec.InitContainers {
+		patch = append(patch, defaultResources(&pod.Spec.InitContainers[i], fmt.Sprintf("/spec/initContainers/%d/resources", i), limits, requests)...)
+	}
+	for i := range pod.Spec.Containers {
+		patch = append(patch, defaultResources(&pod.Spec.Containers[i], fmt.Sprintf("/spec/containers/%d/resources", i), limits, requests)...)
+	}
+	return patch
+}
+
+// defaultResources 给容器填充默认值并返回对应的 patch。
+// 没有默认的 requests 时使用容器的 limits，填充的 requests 不会超过容器的 limits
+func defaultResources(c *corev1.Container, basePath string, defaultLimits, defaultRequests corev1.ResourceList) (patch []patchOperation) {
+	limits := corev1.ResourceList{}
+	for name, q := range defaultLimits {
+		if _, ok := c.Resources.Limits[name]; !ok {
+			limits[name] = q
+		}
+	}
+	patch = append(patch, addResources(c.Resources.Limits, limits, basePath+"/limits")...)
+	for name, q := range limits {
+		if c.Resources.Limits == nil {
+			c.Resources.Limits = corev1.ResourceList{}
+		}
+		c.Resources.Limits[name] = q
+	}
+
+	requests := corev1.ResourceList{}
+	for _, defaults := range []corev1.ResourceList{defaultRequests, defaultLimits} {
+		for name := range defaults {
+			if _, ok := c.Resources.Requests[name]; ok {
+				continue
+			}
+			q, ok := defaultRequests[name]
+			limit, hasLimit := c.Resources.Limits[name]
+			if !ok || hasLimit && q.Cmp(limit) > 0 {
+				q = limit
+			}
+			if !q.IsZero() {
+				requests[name] = q
+			}
+		}
+	}
+	patch = append(patch, addResources(c.Resources.Requests, requests, basePath+"/requests")...)
+	for name, q := range requests {
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = corev1.ResourceList{}
+		}
+		c.Resources.Requests[name] = q
+	}
+
+	return patch
+}
+
+// addResources 在 target 为空时添加整个 map，否则逐个添加，按照资源名称排序保证 patch 的顺序固定
+func addResources(target, added corev1.ResourceList, basePath string) (patch []patchOperation) {
+	if len(added) == 0 {
+		return nil
+	}
+	if len(target) == 0 {
+		value := map[string]string{}
+		for name, q := range added {
+			value[string(name)] = q.String()
+		}
+		return []patchOperation{{Op: "add", Path: basePath, Value: value}}
+	}
+
+	var names []string
+	for name := range added {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		q := added[corev1.ResourceName(name)]
+		patch = append(patch, patchOperation{Op: "add", Path: basePath + "/" + escapePointer(name), Value: q.String()})
+	}
+	return patch
+}
+
+// Validate 返回 Pod 中的容器违反资源限制的原因
+func (p *ResourcePolicy) Validate(namespace string, pod *corev1.Pod) []string {
+	var violations []string
+	for _, rule := range p.Rules {
+		if !rule.Matches(namespace) {
+			continue
+		}
+		for _, c := range pod.Spec.InitContainers {
+			for _, reason := range rule.validate(c.Resources) {
+				violations = append(violations, fmt.Sprintf("initContainer %s violates rule %s: %s", c.Name, rule.Name, reason))
+			}
+		}
+		for _, c := range pod.Spec.Containers {
+			for _, reason := range rule.validate(c.Resources) {
+				violations = append(violations, fmt.Sprintf("container %s violates rule %s: %s", c.Name, rule.Name, reason))
+			}
+		}
+	}
+	return violations
+}
+
+// validate 返回容器的资源违反规则的原因，按照资源名称排序
+func (r *ResourceRule) validate(resources corev1.ResourceRequirements) []string {
+	var violations []string
+	for _, name := range resourceNames(r.Min) {
+		min := r.Min[name]
+		if request, ok := resources.Requests[name]; !ok {
+			violations = append(violations, fmt.Sprintf("the request of %s is required", name))
+		} else if request.Cmp(min) < 0 {
+			violations = append(violations, fmt.Sprintf("the request of %s %s is less than %s", name, request.String(), min.String()))
+		}
+		if limit, ok := resources.Limits[name]; ok && limit.Cmp(min) < 0 {
+			violations = append(violations, fmt.Sprintf("the limit of %s %s is less than %s", name, limit.String(), min.String()))
+		}
+	}
+
+	for _, name := range resourceNames(r.Max) {
+		max := r.Max[name]
+		if limit, ok := resources.Limits[name]; !ok {
+			violations = append(violations, fmt.Sprintf("the limit of %s is required", name))
+		} else if limit.Cmp(max) > 0 {
+			violations = append(violations, fmt.Sprintf("the limit of %s %s is greater than %s", name, limit.String(), max.String()))
+		}
+		if request, ok := resources.Requests[name]; ok && request.Cmp(max) > 0 {
+			violations = append(violations, fmt.Sprintf("the request of %s %s is greater than %s", name, request.String(), max.String()))
+		}
+	}
+
+	for _, name := range resourceNames(r.MaxLimitRequestRatio) {
+		maxRatio := r.MaxLimitRequestRatio[name]
+		limit, hasLimit := resources.Limits[name]
+		request, hasRequest := resources.Requests[name]
+		if !hasLimit || !hasRequest || request.IsZero() {
+			violations = append(violations, fmt.Sprintf("the request and limit of %s are required", name))
+			continue
+		}
+		// 和 LimitRange 一样，cpu 按照 milli 比较，避免 100m 这样的值被取整
+		limitValue, requestValue := limit.Value(), request.Value()
+		if name == corev1.ResourceCPU {
+			limitValue, requestValue = limit.MilliValue(), request.MilliValue()
+		}
+		ratio := float64(limitValue) / float64(requestValue)
+		if ratio > maxRatio.AsApproximateFloat64() {
+			violations = append(violations, fmt.Sprintf("the limit to request ratio of %s %.2f is greater than %s", name, ratio, maxRatio.String()))
+		}
+	}
+
+	return violations
+}
+
+func resourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	var names []corev1.ResourceName
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// ResourceStore 保存当前的资源策略，从文件加载时可以在文件变化后重新加载
+type ResourceStore struct {
+	fileStore
+}
+
+// NewResourceStore 返回保存固定资源策略的 ResourceStore
+func NewResourceStore(policy *ResourcePolicy) *ResourceStore {
+	return &ResourceStore{fileStore{name: "resource policy", value: policy}}
+}
+
+// LoadResourceStore 从资源策略文件加载 ResourceStore
+func LoadResourceStore(file string) (*ResourceStore, error) {
+	s := &ResourceStore{fileStore{name: "resource policy", file: file, parse: func(data []byte) (interface{}, error) {
+		return ParseResourcePolicy(data)
+	}}}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Policy 返回当前的资源策略
+func (s *ResourceStore) Policy() *ResourcePolicy {
+	return s.load().(*ResourcePolicy)
+}
@@ -0,0 +1,202 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const testResourcePolicy = `
+rules:
+- name: default
+  default:
+    cpu: 500m
+    memory: 512Mi
+  defaultRequest:
+    cpu: 100m
+- name: production
+  namespaces: ["prod-*"]
+  default:
+    cpu: "1"
+  min:
+    memory: 64Mi
+  max:
+    cpu: "2"
+    memory: 1Gi
+  maxLimitRequestRatio:
+    cpu: "5"
+`
+
+func newResourceServer(t *testing.T) *WebhookServer {
+	policy, err := ParseResourcePolicy([]byte(testResourcePolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newInjectServer(t)
+	s.Resources = NewResourceStore(policy)
+	return s
+}
+
+func resourceList(pairs ...string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for i := 0; i < len(pairs); i += 2 {
+		list[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return list
+}
+
+func TestResourceDefaults(t *testing.T) {
+	s := newResourceServer(t)
+	pod := testPodSpec(nil)
+	pod.Spec.InitContainers = []corev1.Container{{
+		Name:  "init",
+		Image: "busybox",
+		Resources: corev1.ResourceRequirements{
+			Limits:   resourceList("cpu", "50m"),
+			Requests: resourceList("cpu", "50m"),
+		},
+	}}
+
+	patch, mutated := mutatePod(t, s, "dev", pod)
+	want := []patchOperation{
+		{Op: "add", Path: "/spec/initContainers/0/resources/limits/memory", Value: "512Mi"},
+		{Op: "add", Path: "/spec/initContainers/0/resources/requests/memory", Value: "512Mi"},
+	}
+	if !reflect.DeepEqual(patch[:2], want) {
+		t.Errorf("patch = %+v, want %+v", patch[:2], want)
+	}
+
+	// 没有默认 requests 的资源使用容器的 limits
+	c := mutated.Spec.Containers[0]
+	if !reflect.DeepEqual(c.Resources.Limits, resourceList("cpu", "500m", "memory", "512Mi")) {
+		t.Errorf("limits = %v", c.Resources.Limits)
+	}
+	if !reflect.DeepEqual(c.Resources.Requests, resourceList("cpu", "100m", "memory", "512Mi")) {
+		t.Errorf("requests = %v", c.Resources.Requests)
+	}
+	// 已经设置的值不会被修改
+	if cpu := mutated.Spec.InitContainers[0].Resources.Limits[corev1.ResourceCPU]; cpu.String() != "50m" {
+		t.Errorf("the limit of cpu = %s, want 50m", cpu.String())
+	}
+}
+
+func TestResourceDefaultsForNamespace(t *testing.T) {
+	s := newResourceServer(t)
+	pod := testPodSpec(map[string]string{AnnotationInjectKey: "true"})
+	pod.Spec.Containers[0].Resources.Limits = resourceList("memory", "256Mi")
+
+	// 前面的规则优先，默认的 requests 不会超过容器的 limits；注入的 sidecar 同样填充默认值
+	_, mutated := mutatePod(t, s, "prod-a", pod)
+	if got := containerNames(mutated.Spec.Containers); !reflect.DeepEqual(got, []string{"nginx", "log-agent"}) {
+		t.Fatalf("containers = %v", got)
+	}
+	for _, c := range mutated.Spec.Containers {
+		if !reflect.DeepEqual(c.Resources.Requests, resourceList("cpu", "100m", "memory", map[string]string{
+			"nginx":     "256Mi",
+			"log-agent": "512Mi",
+		}[c.Name])) {
+			t.Errorf("the requests of %s = %v", c.Name, c.Resources.Requests)
+		}
+		if cpu := c.Resources.Limits[corev1.ResourceCPU]; cpu.String() != "500m" {
+			t.Errorf("the limit of cpu of %s = %s, want 500m", c.Name, cpu.String())
+		}
+	}
+	if got := mutated.Spec.InitContainers[0].Resources.Limits; !reflect.DeepEqual(got, resourceList("cpu", "500m", "memory", "512Mi")) {
+		t.Errorf("the limits of the init container = %v", got)
+	}
+
+	if violations := s.Resources.Policy().Validate("prod-a", mutated); len(violations) != 0 {
+		t.Errorf("the mutated pod violates %v", violations)
+	}
+}
+
+func TestResourceValidate(t *testing.T) {
+	policy, err := ParseResourcePolicy([]byte(testResourcePolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		namespace string
+		limits    corev1.ResourceList
+		requests  corev1.ResourceList
+		want      []string
+	}{
+		{"dev", nil, nil, nil},
+		{"prod-a", resourceList("cpu", "2", "memory", "1Gi"), resourceList("cpu", "500m", "memory", "64Mi"), nil},
+		{"prod-a", nil, nil, []string{
+			"the request of memory is required",
+			"the limit of cpu is required",
+			"the limit of memory is required",
+			"the request and limit of cpu are required",
+		}},
+		{"prod-a", resourceList("cpu", "3", "memory", "2Gi"), resourceList("cpu", "3", "memory", "32Mi"), []string{
+			"the request of memory 32Mi is less than 64Mi",
+			"the limit of cpu 3 is greater than 2",
+			"the request of cpu 3 is greater than 2",
+			"the limit of memory 2Gi is greater than 1Gi",
+		}},
+		{"prod-a", resourceList("cpu", "2", "memory", "1Gi"), resourceList("cpu", "100m", "memory", "1Gi"), []string{
+			"the limit to request ratio of cpu 20.00 is greater than 5",
+		}},
+	}
+	for _, tt := range tests {
+		pod := testPodSpec(nil)
+		pod.Spec.Containers[0].Resources = corev1.ResourceRequirements{Limits: tt.limits, Requests: tt.requests}
+		var want []string
+		for _, reason := range tt.want {
+			want = append(want, "container nginx violates rule production: "+reason)
+		}
+		if got := policy.Validate(tt.namespace, pod); !reflect.DeepEqual(got, want) {
+			t.Errorf("Validate(%s, %v, %v) = %q, want %q", tt.namespace, tt.limits, tt.requests, got, want)
+		}
+	}
+}
+
+func TestParseResourcePolicyErrors(t *testing.T) {
+	tests := []struct {
+		policy string
+		err    string
+	}{
+		{"rules:\n- namespaces: ['[']", "invalid pattern"},
+		{"rules:\n- min: {cpu: 2}\n  max: {cpu: 1}", "min of cpu is greater than max"},
+		{"rules:\n- default: {cpu: 2}\n  max: {cpu: 1}", "the default cpu is greater than max"},
+		{"rules:\n- defaultRequest: {cpu: 100m}\n  min: {cpu: 200m}", "the default cpu is less than min"},
+		{"rules:\n- default: {cpu: 1}\n  defaultRequest: {cpu: 2}", "defaultRequest of cpu is greater than default"},
+		{"rules:\n- maxLimitRequestRatio: {cpu: 500m}", "must be at least 1"},
+		{"rules:\n- default: {cpu: abc}", "quantities must match"},
+		{"rules:\n- defaults: {cpu: 1}", "unknown field"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseResourcePolicy([]byte(tt.policy)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseResourcePolicy(%q) error = %v, want %q", tt.policy, err, tt.err)
+		}
+	}
+}
+
+func TestWebhookValidateResources(t *testing.T) {
+	s := newResourceServer(t)
+	raw, err := json.Marshal(testPodSpec(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := s.validate(&admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:       "1",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace: "prod-a",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	if resp.Allowed || resp.Result.Code != http.StatusForbidden || !strings.Contains(resp.Result.Message, "the limit of cpu is required") {
+		t.Errorf("validate() = %v, %+v, want forbidden", resp.Allowed, resp.Result)
+	}
+}
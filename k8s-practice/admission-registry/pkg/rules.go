@@ -0,0 +1,399 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"admission-registry/pkg/expr"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// 规则中的表达式可以使用的变量，分别是 AdmissionReview 的 request、request.object 和 request.oldObject。
+// mutate 时 object 是前面的规则修改之后的对象
+var ruleVars = []string{"request", "object", "oldObject"}
+
+// Rules 是用表达式编写的准入规则，表达式的语法见 expr 包
+//
+//	validations:
+//	- name: require-team-label
+//	  match: request.kind.kind == "Pod"
+//	  expression: has(object.metadata.labels) && has(object.metadata.labels.team)
+//	  message: pods must have a team label
+//	mutations:
+//	- name: default-team
+//	  match: request.kind.kind == "Pod" && (!has(object.metadata.labels) || !has(object.metadata.labels.team))
+//	  patches:
+//	  - op: add
+//	    path: /metadata/labels/team
+//	    value: '"unknown"'
+type Rules struct {
+	Validations []*Validation `json:"validations,omitempty"`
+	Mutations   []*Mutation   `json:"mutations,omitempty"`
+}
+
+// Validation 是一条校验规则，expression 的结果为 false 时拒绝请求
+type Validation struct {
+	Name string `json:"name"`
+	// Match 为空时作用于所有请求
+	Match      string `json:"match,omitempty"`
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+	// MessageExpression 的结果是拒绝的原因，优先于 Message
+	MessageExpression string `json:"messageExpression,omitempty"`
+
+	match, expression, message *expr.Program
+}
+
+// Mutation 是一条修改规则，匹配的请求按照顺序应用 patches
+type Mutation struct {
+	Name    string       `json:"name"`
+	Match   string       `json:"match,omitempty"`
+	Patches []*PatchRule `json:"patches"`
+
+	match *expr.Program
+}
+
+// PatchRule 是 JSON Patch 的一个操作，value 是表达式，它的结果是 patch 的值
+type PatchRule struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value,omitempty"`
+
+	value *expr.Program
+}
+
+// RuleResult 是规则处理一个请求的结果，用于离线测试规则
+type RuleResult struct {
+	// Object 是 mutate 之后的对象
+	Object     interface{}      `json:"object,omitempty"`
+	Patch      []patchOperation `json:"patch,omitempty"`
+	Violations []string         `json:"violations,omitempty"`
+}
+
+// ParseRules 解析 YAML 或 JSON 格式的规则并编译其中的表达式
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		return nil, err
+	}
+
+	for i, v := range rules.Validations {
+		if v == nil {
+			return nil, fmt.Errorf("validation %d is empty", i)
+		}
+		if v.Name == "" {
+			v.Name = fmt.Sprintf("validation-%d", i)
+		}
+		if err := v.compile(); err != nil {
+			return nil, fmt.Errorf("validation %s: %v", v.Name, err)
+		}
+	}
+	for i, m := range rules.Mutations {
+		if m == nil {
+			return nil, fmt.Errorf("mutation %d is empty", i)
+		}
+		if m.Name == "" {
+			m.Name = fmt.Sprintf("mutation-%d", i)
+		}
+		if err := m.compile(); err != nil {
+			return nil, fmt.Errorf("mutation %s: %v", m.Name, err)
+		}
+	}
+
+	return &rules, nil
+}
+
+// compileOptional 编译可以为空的表达式
+func compileOptional(src string) (*expr.Program, error) {
+	if src == "" {
+		return nil, nil
+	}
+	return expr.Compile(src, ruleVars...)
+}
+
+func (v *Validation) compile() error {
+	var err error
+	if v.Expression == "" {
+		return fmt.Errorf("expression is required")
+	}
+	if v.expression, err = expr.Compile(v.Expression, ruleVars...); err != nil {
+		return fmt.Errorf("expression: %v", err)
+	}
+	if v.match, err = compileOptional(v.Match); err != nil {
+		return fmt.Errorf("match: %v", err)
+	}
+	if v.message, err = compileOptional(v.MessageExpression); err != nil {
+		return fmt.Errorf("messageExpression: %v", err)
+	}
+	return nil
+}
+
+func (m *Mutation) compile() error {
+	var err error
+	if m.match, err = compileOptional(m.Match); err != nil {
+		return fmt.Errorf("match: %v", err)
+	}
+	if len(m.Patches) == 0 {
+		return fmt.Errorf("patches are required")
+	}
+
+	for i, p := range m.Patches {
+		if p == nil {
+			return fmt.Errorf("patch %d is empty", i)
+		}
+		if _, err := splitPointer(p.Path); err != nil {
+			return fmt.Errorf("patch %d: %v", i, err)
+		}
+		switch p.Op {
+		case "add", "replace":
+			if p.Value == "" {
+				return fmt.Errorf("patch %d: value is required", i)
+			}
+		case "remove":
+			if p.Value != "" {
+				return fmt.Errorf("patch %d: remove has no value", i)
+			}
+		default:
+			return fmt.Errorf("patch %d: unsupported op %q", i, p.Op)
+		}
+		if p.value, err = compileOptional(p.Value); err != nil {
+			return fmt.Errorf("patch %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// matchRule 判断规则是否作用于当前的请求，match 为空时作用于所有请求
+func matchRule(match *expr.Program, vars map[string]interface{}) (bool, error) {
+	if match == nil {
+		return true, nil
+	}
+	ok, err := match.EvalBool(vars)
+	if err != nil {
+		return false, fmt.Errorf("match: %v", err)
+	}
+	return ok, nil
+}
+
+// reviewVars 返回求值使用的变量，object 是当前的对象
+func reviewVars(req *admissionv1.AdmissionRequest, object interface{}) (map[string]interface{}, error) {
+	request, err := expr.Normalize(req)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]interface{}{
+		"request":   request,
+		"object":    object,
+		"oldObject": nil,
+	}
+	if req.OldObject.Raw != nil {
+		if vars["oldObject"], err = expr.FromJSON(req.OldObject.Raw); err != nil {
+			return nil, fmt.Errorf("can't decode oldObject: %v", err)
+		}
+	}
+	return vars, nil
+}
+
+// requestObject 返回请求中的对象，DELETE 请求没有对象，返回 nil
+func requestObject(req *admissionv1.AdmissionRequest) (interface{}, error) {
+	if req.Object.Raw == nil {
+		return nil, nil
+	}
+	object, err := expr.FromJSON(req.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode object: %v", err)
+	}
+	return object, nil
+}
+
+// validate 返回违反的校验规则，规则求值出错时返回错误
+func (r *Rules) validate(vars map[string]interface{}) ([]string, error) {
+	var violations []string
+	for _, v := range r.Validations {
+		ok, err := matchRule(v.match, vars)
+		if err != nil {
+			return nil, fmt.Errorf("validation %s: %v", v.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if ok, err = v.expression.EvalBool(vars); err != nil {
+			return nil, fmt.Errorf("validation %s: %v", v.Name, err)
+		}
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s: %s", v.Name, v.reason(vars)))
+		}
+	}
+
+	return violations, nil
+}
+
+// reason 返回拒绝的原因，messageExpression 出错时使用 message
+func (v *Validation) reason(vars map[string]interface{}) string {
+	if v.message != nil {
+		if message, err := v.message.Eval(vars); err == nil {
+			if s, ok := message.(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	if v.Message != "" {
+		return v.Message
+	}
+	return fmt.Sprintf("failed expression: %s", v.Expression)
+}
+
+// mutate 先把 patch 应用到 object 上，再应用修改规则，返回的 patch 包括传入的 patch
+func (r *Rules) mutate(req *admissionv1.AdmissionRequest, object interface{}, patch []patchOperation) (interface{}, []patchOperation, error) {
+	var result []patchOperation
+	for _, op := range patch {
+		var (
+			ops []patchOperation
+			err error
+		)
+		if object, ops, err = applyPatch(object, op); err != nil {
+			return nil, nil, err
+		}
+		result = append(result, ops...)
+	}
+
+	vars, err := reviewVars(req, object)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range r.Mutations {
+		ok, err := matchRule(m.match, vars)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mutation %s: %v", m.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		for _, p := range m.Patches {
+			op := patchOperation{Op: p.Op, Path: p.Path}
+			if p.value != nil {
+				if op.Value, err = p.value.Eval(vars); err != nil {
+					return nil, nil, fmt.Errorf("mutation %s: %s: %v", m.Name, p.Path, err)
+				}
+				// patch 的 value 为 null 时会被省略
+				if op.Value == nil {
+					return nil, nil, fmt.Errorf("mutation %s: %s: the value is null", m.Name, p.Path)
+				}
+			}
+
+			var ops []patchOperation
+			if object, ops, err = applyPatch(object, op); err != nil {
+				return nil, nil, fmt.Errorf("mutation %s: %v", m.Name, err)
+			}
+			result = append(result, ops...)
+			// 后面的 patch 和规则看到的是修改之后的对象
+			vars["object"] = object
+		}
+	}
+
+	return object, result, nil
+}
+
+// Evaluate 和 apiserver 一样先 mutate 再 validate，用来离线测试规则
+func (r *Rules) Evaluate(req *admissionv1.AdmissionRequest) (*RuleResult, error) {
+	object, err := requestObject(req)
+	if err != nil {
+		return nil, err
+	}
+	object, patch, err := r.mutate(req, object, nil)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := reviewVars(req, object)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := r.validate(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuleResult{Object: object, Patch: patch, Violations: violations}, nil
+}
+
+// RuleStore 保存当前的规则，从文件加载时可以在文件变化后重新加载
+type RuleStore struct {
+	fileStore
+}
+
+// NewRuleStore 返回保存固定规则的 RuleStore
+func NewRuleStore(rules *Rules) *RuleStore {
+	return &RuleStore{fileStore{name: "rules", value: rules}}
+}
+
+// LoadRuleStore 从规则文件加载 RuleStore
+func LoadRuleStore(file string) (*RuleStore, error) {
+	s := &RuleStore{fileStore{name: "rules", file: file, parse: func(data []byte) (interface{}, error) {
+		return ParseRules(data)
+	}}}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Rules 返回当前的规则
+func (s *RuleStore) Rules() *Rules {
+	return s.load().(*Rules)
+}
+
+// SampleRequest 从 YAML 或 JSON 格式的样例构造请求，用于离线测试规则。
+// 样例可以是完整的 AdmissionReview，也可以只是一个对象，这时使用 operation 构造请求，
+// old 不为空时是 UPDATE 请求的旧对象
+func SampleRequest(sample []byte, operation string, old []byte) (*admissionv1.AdmissionRequest, error) {
+	data, err := yaml.YAMLToJSON(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(data, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.Kind == "AdmissionReview" {
+		var review admissionv1.AdmissionReview
+		if err := json.Unmarshal(data, &review); err != nil {
+			return nil, err
+		}
+		if review.Request == nil {
+			return nil, fmt.Errorf("the AdmissionReview has no request")
+		}
+		return review.Request, nil
+	}
+
+	var object metav1.PartialObjectMetadata
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	gv, err := schema.ParseGroupVersion(object.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	req := &admissionv1.AdmissionRequest{
+		UID:       types.UID("sample"),
+		Kind:      metav1.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: object.Kind},
+		Name:      object.Name,
+		Namespace: object.Namespace,
+		Operation: admissionv1.Operation(operation),
+		Object:    runtime.RawExtension{Raw: data},
+	}
+	if len(old) > 0 {
+		if req.OldObject.Raw, err = yaml.YAMLToJSON(old); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
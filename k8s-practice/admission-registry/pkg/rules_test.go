@@ -0,0 +1,353 @@
+package pkg
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+const testRules = `
+validations:
+- name: require-team-label
+  match: request.kind.kind == "Pod"
+  expression: has(object.metadata.labels) && has(object.metadata.labels.team) && object.metadata.labels.team != "unknown"
+  message: pods must have a team label
+- name: no-privileged
+  match: request.kind.kind == "Pod"
+  expression: object.spec.containers.all(c, !has(c.securityContext) || !has(c.securityContext.privileged) || !c.securityContext.privileged)
+  messageExpression: >-
+    "privileged containers: " + object.spec.containers.filter(c, has(c.securityContext) && has(c.securityContext.privileged) &&
+    c.securityContext.privileged).map(c, c.name).join(", ")
+- name: immutable-app-label
+  match: request.operation == "UPDATE"
+  expression: object.metadata.labels.app == oldObject.metadata.labels.app
+mutations:
+- name: default-team
+  match: request.kind.kind == "Pod" && (!has(object.metadata.labels) || !has(object.metadata.labels.team))
+  patches:
+  - op: add
+    path: /metadata/labels/team
+    value: '"unknown"'
+- name: container-count
+  patches:
+  - op: add
+    path: /metadata/annotations/example.com~1containers
+    value: string(size(object.spec.containers))
+  - op: add
+    path: /spec/containers/-
+    value: '{"name": "sidecar", "image": "busybox"}'
+`
+
+const testPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+    securityContext:
+      privileged: true
+`
+
+func evaluate(t *testing.T, rules, sample, operation, old string) *RuleResult {
+	r, err := ParseRules([]byte(rules))
+	if err != nil {
+		t.Fatalf("ParseRules(): %v", err)
+	}
+	req, err := SampleRequest([]byte(sample), operation, []byte(old))
+	if err != nil {
+		t.Fatalf("SampleRequest(): %v", err)
+	}
+	result, err := r.Evaluate(req)
+	if err != nil {
+		t.Fatalf("Evaluate(): %v", err)
+	}
+	return result
+}
+
+func TestRulesEvaluate(t *testing.T) {
+	result := evaluate(t, testRules, testPod, "CREATE", "")
+
+	wantPatch := `[
+		{"op": "add", "path": "/metadata/labels/team", "value": "unknown"},
+		{"op": "add", "path": "/metadata/annotations", "value": {}},
+		{"op": "add", "path": "/metadata/annotations/example.com~1containers", "value": "1"},
+		{"op": "add", "path": "/spec/containers/-", "value": {"name": "sidecar", "image": "busybox"}}
+	]`
+	assertJSON(t, "patch", result.Patch, wantPatch)
+
+	object := result.Object.(map[string]interface{})
+	metadata := object["metadata"].(map[string]interface{})
+	if got := metadata["annotations"]; !reflect.DeepEqual(got, map[string]interface{}{"example.com/containers": "1"}) {
+		t.Errorf("annotations = %v", got)
+	}
+	if got := len(object["spec"].(map[string]interface{})["containers"].([]interface{})); got != 2 {
+		t.Errorf("len(containers) = %d, want 2", got)
+	}
+
+	// validate 看到的是 mutate 之后的对象
+	wantViolations := []string{
+		"require-team-label: pods must have a team label",
+		"no-privileged: privileged containers: nginx",
+	}
+	if !reflect.DeepEqual(result.Violations, wantViolations) {
+		t.Errorf("violations = %q, want %q", result.Violations, wantViolations)
+	}
+}
+
+func TestRulesEvaluateUpdate(t *testing.T) {
+	pod := strings.Replace(testPod, "app: web", "app: api\n    team: web", 1)
+	pod = strings.Replace(pod, "privileged: true", "privileged: false", 1)
+
+	result := evaluate(t, testRules, pod, "UPDATE", testPod)
+	want := []string{"immutable-app-label: failed expression: object.metadata.labels.app == oldObject.metadata.labels.app"}
+	if !reflect.DeepEqual(result.Violations, want) {
+		t.Errorf("violations = %q, want %q", result.Violations, want)
+	}
+
+	if result := evaluate(t, testRules, pod, "CREATE", ""); len(result.Violations) != 0 {
+		t.Errorf("violations = %q, want none", result.Violations)
+	}
+}
+
+func TestRulesEvaluateAdmissionReview(t *testing.T) {
+	review := `{
+		"apiVersion": "admission.k8s.io/v1",
+		"kind": "AdmissionReview",
+		"request": {
+			"uid": "1",
+			"kind": {"group": "apps", "version": "v1", "kind": "Deployment"},
+			"operation": "DELETE",
+			"oldObject": {"metadata": {"name": "web", "labels": {"app": "web"}}}
+		}
+	}`
+	rules := `
+validations:
+- name: protected
+  match: request.operation == "DELETE"
+  expression: oldObject.metadata.labels.app != "web"
+  messageExpression: '"can not delete " + request.kind.kind + " " + oldObject.metadata.name'
+`
+	result := evaluate(t, rules, review, "", "")
+	want := []string{"protected: can not delete Deployment web"}
+	if !reflect.DeepEqual(result.Violations, want) || result.Object != nil || result.Patch != nil {
+		t.Errorf("result = %+v, want violations %q", result, want)
+	}
+}
+
+func TestRulesEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{"validation", `
+validations:
+- name: bad
+  expression: object.spec.missing == 1`, "validation bad: no such key: missing"},
+		{"match", `
+validations:
+- name: bad
+  match: object.kind
+  expression: "true"`, "validation bad: match: expected bool"},
+		{"replace missing", `
+mutations:
+- name: bad
+  patches:
+  - op: replace
+    path: /metadata/annotations/a
+    value: '"b"'`, "mutation bad: replace /metadata/annotations/a: path not found"},
+		{"null value", `
+mutations:
+- name: bad
+  patches:
+  - op: add
+    path: /metadata/annotations
+    value: "null"`, "the value is null"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRules([]byte(tt.rules))
+		if err != nil {
+			t.Errorf("%s: ParseRules(): %v", tt.name, err)
+			continue
+		}
+		req, err := SampleRequest([]byte(testPod), "CREATE", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Evaluate(req); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: Evaluate() error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		rules string
+		err   string
+	}{
+		{"validations:\n- name: a", "validation a: expression is required"},
+		{"validations:\n- expression: objct.spec", `validation validation-0: expression: ERROR: <input>:1:1: undeclared reference to 'objct'`},
+		{"validations:\n- expression: 'true'\n  match: '('", "validation validation-0: match:"},
+		{"mutations:\n- name: m", "mutation m: patches are required"},
+		{"mutations:\n- name: m\n  patches:\n  - op: move\n    path: /a", `mutation m: patch 0: unsupported op "move"`},
+		{"mutations:\n- name: m\n  patches:\n  - op: add\n    path: a\n    value: '1'", "mutation m: patch 0: invalid path"},
+		{"mutations:\n- name: m\n  patches:\n  - op: add\n    path: /a", "mutation m: patch 0: value is required"},
+		{"mutations:\n- name: m\n  patches:\n  - op: remove\n    path: /a\n    value: '1'", "mutation m: patch 0: remove has no value"},
+		{"validation: []", "unknown field"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseRules([]byte(tt.rules)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseRules(%q) error = %v, want %q", tt.rules, err, tt.err)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		doc   string
+		patch patchOperation
+		want  string
+		ops   string
+		err   string
+	}{
+		{`{"a": {}}`, patchOperation{Op: "add", Path: "/a/b/c", Value: 1}, `{"a": {"b": {"c": 1}}}`,
+			`[{"op": "add", "path": "/a/b", "value": {}}, {"op": "add", "path": "/a/b/c", "value": 1}]`, ""},
+		{`{"a": [1, 3]}`, patchOperation{Op: "add", Path: "/a/1", Value: 2}, `{"a": [1, 2, 3]}`, "", ""},
+		{`{"a": [1]}`, patchOperation{Op: "add", Path: "/a/-", Value: 2}, `{"a": [1, 2]}`, "", ""},
+		{`{"a": [1, 2]}`, patchOperation{Op: "remove", Path: "/a/0"}, `{"a": [2]}`, "", ""},
+		{`{"a": {"b~c": 1}}`, patchOperation{Op: "replace", Path: "/a/b~0c", Value: 2}, `{"a": {"b~c": 2}}`, "", ""},
+		{`{"a": {"b": 1}}`, patchOperation{Op: "remove", Path: "/a/b"}, `{"a": {}}`, "", ""},
+		{`{"a": {}}`, patchOperation{Op: "remove", Path: "/a/b"}, "", "", "remove /a/b: path not found"},
+		{`{"a": [1]}`, patchOperation{Op: "replace", Path: "/a/1", Value: 2}, "", "", `replace /a/1: invalid index "1"`},
+		{`{"a": 1}`, patchOperation{Op: "add", Path: "/a/b", Value: 2}, "", "", "add /a/b: path not found"},
+	}
+	for _, tt := range tests {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatal(err)
+		}
+		got, ops, err := applyPatch(doc, tt.patch)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("applyPatch(%s, %+v) error = %v, want %q", tt.doc, tt.patch, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("applyPatch(%s, %+v): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		assertJSON(t, "doc", got, tt.want)
+		if tt.ops != "" {
+			assertJSON(t, "ops", ops, tt.ops)
+		}
+	}
+}
+
+func TestRuleStoreReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := ioutil.WriteFile(file, []byte(testRules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := LoadRuleStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(store.Rules().Validations); got != 3 {
+		t.Fatalf("len(Validations) = %d, want 3", got)
+	}
+
+	// 无效的规则不会替换当前的规则
+	if err := ioutil.WriteFile(file, []byte("validations:\n- expression: '('"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := store.Reload(); reloaded || err == nil {
+		t.Errorf("Reload() = %v, %v, want an error", reloaded, err)
+	}
+	if got := len(store.Rules().Validations); got != 3 {
+		t.Errorf("len(Validations) = %d after a failed reload, want 3", got)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("mutations: []"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := store.Reload(); !reloaded || err != nil {
+		t.Errorf("Reload() = %v, %v, want reloaded", reloaded, err)
+	}
+	if got := len(store.Rules().Validations); got != 0 {
+		t.Errorf("len(Validations) = %d, want 0", got)
+	}
+}
+
+func TestWebhookMutateWithRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+mutations:
+- name: owner
+  match: '!has(object.metadata.annotations) || !has(object.metadata.annotations.owner)'
+  patches:
+  - op: add
+    path: /metadata/annotations/owner
+    value: request.userInfo.username
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &WebhookServer{
+		Policies:  NewPolicyStore(&Policy{}),
+		Rules:     NewRuleStore(rules),
+		Resources: NewResourceStore(&ResourcePolicy{}),
+		Security:  NewSecurityStore(&SecurityPolicy{}),
+	}
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal([]byte(`{
+		"request": {
+			"uid": "1",
+			"kind": {"group": "apps", "version": "v1", "kind": "Deployment"},
+			"operation": "CREATE",
+			"userInfo": {"username": "alice"},
+			"object": {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}}
+		}
+	}`), review); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := s.mutate(review)
+	if !resp.Allowed {
+		t.Fatalf("mutate() = %+v, want allowed", resp)
+	}
+	// 规则在内置的 annotation 之后执行，不会覆盖它
+	assertJSON(t, "patch", json.RawMessage(resp.Patch), `[
+		{"op": "add", "path": "/metadata/annotations", "value": {"io.ydzs.admission-registry/status": "mutated"}},
+		{"op": "add", "path": "/metadata/annotations/owner", "value": "alice"}
+	]`)
+}
+
+func assertJSON(t *testing.T, name string, got interface{}, want string) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g, w interface{}
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("%s = %s, want %s", name, data, want)
+	}
+}
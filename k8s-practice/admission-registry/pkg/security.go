@@ -0,0 +1,261 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	SecurityModeEnforce = "enforce" // 违反规则时拒绝 Pod
+	SecurityModeAudit   = "audit"   // 违反规则时允许 Pod，但是返回警告
+)
+
+// SecurityPolicy 是 Pod 的安全策略，Pod 需要满足所有匹配的规则
+//
+//	auditNamespaces: ["dev-*"]
+//	rules:
+//	- name: baseline
+//	  allowedCapabilities: ["NET_BIND_SERVICE"]
+//	- name: production
+//	  namespaces: ["prod-*"]
+//	  requireRunAsNonRoot: true
+//	- name: staging
+//	  namespaces: ["staging"]
+//	  mode: audit
+//	  requireRunAsNonRoot: true
+type SecurityPolicy struct {
+	// AuditNamespaces 是命名空间的 glob 模式，这些命名空间中的所有规则都使用 audit 模式
+	AuditNamespaces []string        `json:"auditNamespaces,omitempty"`
+	Rules           []*SecurityRule `json:"rules"`
+}
+
+// SecurityRule 是一条安全规则，没有指定 namespaces 时匹配所有命名空间。
+// 默认禁止特权容器、使用宿主机的命名空间和 hostPath 卷，以及添加任何 capability
+type SecurityRule struct {
+	Name string `json:"name"`
+	// Namespaces 是命名空间的 glob 模式
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Mode 是 enforce 或者 audit，默认是 enforce
+	Mode string `json:"mode,omitempty"`
+
+	AllowPrivileged  bool `json:"allowPrivileged,omitempty"`
+	AllowHostNetwork bool `json:"allowHostNetwork,omitempty"`
+	AllowHostPID     bool `json:"allowHostPID,omitempty"`
+	AllowHostIPC     bool `json:"allowHostIPC,omitempty"`
+	AllowHostPath    bool `json:"allowHostPath,omitempty"`
+	// AllowedCapabilities 是允许添加的 capability，可以省略 CAP_ 前缀
+	AllowedCapabilities []string `json:"allowedCapabilities,omitempty"`
+	// RequireRunAsNonRoot 要求容器设置 runAsNonRoot，或者使用非 root 的 runAsUser
+	RequireRunAsNonRoot bool `json:"requireRunAsNonRoot,omitempty"`
+
+	capabilities map[string]bool
+}
+
+// ParseSecurityPolicy 解析 YAML 或 JSON 格式的安全策略
+func ParseSecurityPolicy(data []byte) (*SecurityPolicy, error) {
+	var policy SecurityPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range policy.AuditNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	for i, rule := range policy.Rules {
+		if rule == nil {
+			return nil, fmt.Errorf("rule %d is empty", i)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i)
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+	}
+
+	return &policy, nil
+}
+
+func (r *SecurityRule) compile() error {
+	for _, pattern := range r.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+
+	switch r.Mode {
+	case "":
+		r.Mode = SecurityModeEnforce
+	case SecurityModeEnforce, SecurityModeAudit:
+	default:
+		return fmt.Errorf("invalid mode %q, it must be %s or %s", r.Mode, SecurityModeEnforce, SecurityModeAudit)
+	}
+
+	r.capabilities = map[string]bool{}
+	for _, capability := range r.AllowedCapabilities {
+		r.capabilities[normalizeCapability(capability)] = true
+	}
+	return nil
+}
+
+// normalizeCapability 去掉 CAP_ 前缀并转换成大写，和容器运行时的处理一致
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+}
+
+// Matches 判断规则是否作用于命名空间 namespace
+func (r *SecurityRule) Matches(namespace string) bool {
+	return len(r.Namespaces) == 0 || matchNamespace(r.Namespaces, namespace)
+}
+
+// Validate 返回 Pod 违反 enforce 规则的原因，违反 audit 规则的原因作为警告返回
+func (p *SecurityPolicy) Validate(namespace string, pod *corev1.Pod) (violations, warnings []string) {
+	return p.check(namespace, func(rule *SecurityRule) []string {
+		return rule.validate(pod)
+	})
+}
+
+// ValidateEphemeralContainers 检查通过 pods/ephemeralcontainers 子资源添加到 pod 中的临时容器，
+// 容器的设置和 Pod 的 securityContext 一起检查，返回值和 Validate 相同
+func (p *SecurityPolicy) ValidateEphemeralContainers(namespace string, pod *corev1.Pod,
+	added []corev1.EphemeralContainer) (violations, warnings []string) {
+	return p.check(namespace, func(rule *SecurityRule) []string {
+		var reasons []string
+		for _, c := range added {
+			for _, reason := range rule.validateContainer(pod.Spec.SecurityContext, c.SecurityContext) {
+				reasons = append(reasons, fmt.Sprintf("ephemeralContainer %s violates rule %s: %s", c.Name, rule.Name, reason))
+			}
+		}
+		return reasons
+	})
+}
+
+// check 用命名空间中生效的规则检查，audit 模式的规则和 auditNamespaces 中的原因作为警告返回
+func (p *SecurityPolicy) check(namespace string, validate func(rule *SecurityRule) []string) (violations, warnings []string) {
+	audit := matchNamespace(p.AuditNamespaces, namespace)
+	for _, rule := range p.Rules {
+		if !rule.Matches(namespace) {
+			continue
+		}
+		reasons := validate(rule)
+		if audit || rule.Mode == SecurityModeAudit {
+			warnings = append(warnings, reasons...)
+		} else {
+			violations = append(violations, reasons...)
+		}
+	}
+	return violations, warnings
+}
+
+// validate 返回 Pod 违反规则的原因
+func (r *SecurityRule) validate(pod *corev1.Pod) []string {
+	var reasons []string
+	podReason := func(format string, args ...interface{}) {
+		reasons = append(reasons, fmt.Sprintf("pod violates rule %s: ", r.Name)+fmt.Sprintf(format, args...))
+	}
+
+	if pod.Spec.HostNetwork && !r.AllowHostNetwork {
+		podReason("hostNetwork is not allowed")
+	}
+	if pod.Spec.HostPID && !r.AllowHostPID {
+		podReason("hostPID is not allowed")
+	}
+	if pod.Spec.HostIPC && !r.AllowHostIPC {
+		podReason("hostIPC is not allowed")
+	}
+	if !r.AllowHostPath {
+		for _, v := range pod.Spec.Volumes {
+			if v.HostPath != nil {
+				podReason("hostPath volume %s is not allowed", v.Name)
+			}
+		}
+	}
+
+	check := func(kind, name string, sc *corev1.SecurityContext) {
+		for _, reason := range r.validateContainer(pod.Spec.SecurityContext, sc) {
+			reasons = append(reasons, fmt.Sprintf("%s %s violates rule %s: %s", kind, name, r.Name, reason))
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		check("initContainer", c.Name, c.SecurityContext)
+	}
+	for _, c := range pod.Spec.Containers {
+		check("container", c.Name, c.SecurityContext)
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		check("ephemeralContainer", c.Name, c.SecurityContext)
+	}
+	return reasons
+}
+
+// validateContainer 检查容器的 securityContext，容器的 runAsNonRoot 和 runAsUser 优先于 Pod 的设置
+func (r *SecurityRule) validateContainer(podSC *corev1.PodSecurityContext, sc *corev1.SecurityContext) []string {
+	if sc == nil {
+		sc = &corev1.SecurityContext{}
+	}
+
+	var reasons []string
+	if sc.Privileged != nil && *sc.Privileged && !r.AllowPrivileged {
+		reasons = append(reasons, "privileged containers are not allowed")
+	}
+	if sc.Capabilities != nil {
+		for _, capability := range sc.Capabilities.Add {
+			if !r.capabilities[normalizeCapability(string(capability))] {
+				reasons = append(reasons, fmt.Sprintf("capability %s is not allowed", capability))
+			}
+		}
+	}
+
+	if r.RequireRunAsNonRoot {
+		runAsNonRoot, runAsUser := sc.RunAsNonRoot, sc.RunAsUser
+		if podSC != nil {
+			if runAsNonRoot == nil {
+				runAsNonRoot = podSC.RunAsNonRoot
+			}
+			if runAsUser == nil {
+				runAsUser = podSC.RunAsUser
+			}
+		}
+		switch {
+		case runAsUser != nil && *runAsUser == 0:
+			reasons = append(reasons, "running as root is not allowed")
+		case runAsUser == nil && (runAsNonRoot == nil || !*runAsNonRoot):
+			reasons = append(reasons, "runAsNonRoot must be true")
+		}
+	}
+
+	return reasons
+}
+
+// SecurityStore 保存当前的安全策略，从文件加载时可以在文件变化后重新加载
+type SecurityStore struct {
+	fileStore
+}
+
+// NewSecurityStore 返回保存固定安全策略的 SecurityStore
+func NewSecurityStore(policy *SecurityPolicy) *SecurityStore {
+	return &SecurityStore{fileStore{name: "security policy", value: policy}}
+}
+
+// LoadSecurityStore 从安全策略文件加载 SecurityStore
+func LoadSecurityStore(file string) (*SecurityStore, error) {
+	s := &SecurityStore{fileStore{name: "security policy", file: file, parse: func(data []byte) (interface{}, error) {
+		return ParseSecurityPolicy(data)
+	}}}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Policy 返回当前的安全策略
+func (s *SecurityStore) Policy() *SecurityPolicy {
+	return s.load().(*SecurityPolicy)
+}
@@ -0,0 +1,274 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testSecurityPolicy = `
+auditNamespaces: ["dev-*"]
+rules:
+- name: baseline
+  allowedCapabilities: ["net_bind_service"]
+- name: production
+  namespaces: ["prod-*"]
+  requireRunAsNonRoot: true
+- name: staging
+  namespaces: ["staging"]
+  mode: audit
+  requireRunAsNonRoot: true
+`
+
+func TestSecurityValidate(t *testing.T) {
+	policy, err := ParseSecurityPolicy([]byte(testSecurityPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	yes, no, root, user := true, false, int64(0), int64(1000)
+
+	tests := []struct {
+		name      string
+		namespace string
+		mutate    func(pod *corev1.Pod)
+		want      []string
+	}{
+		{"default", "default", func(pod *corev1.Pod) {}, nil},
+		{"host namespaces", "default", func(pod *corev1.Pod) {
+			pod.Spec.HostNetwork, pod.Spec.HostPID, pod.Spec.HostIPC = true, true, true
+			pod.Spec.Volumes = []corev1.Volume{{Name: "root", VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+			}}}
+		}, []string{
+			"pod violates rule baseline: hostNetwork is not allowed",
+			"pod violates rule baseline: hostPID is not allowed",
+			"pod violates rule baseline: hostIPC is not allowed",
+			"pod violates rule baseline: hostPath volume root is not allowed",
+		}},
+		{"privileged", "default", func(pod *corev1.Pod) {
+			pod.Spec.InitContainers = []corev1.Container{{Name: "init", SecurityContext: &corev1.SecurityContext{Privileged: &yes}}}
+			pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &no}
+		}, []string{
+			"initContainer init violates rule baseline: privileged containers are not allowed",
+		}},
+		{"capabilities", "default", func(pod *corev1.Pod) {
+			pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Capabilities: &corev1.Capabilities{
+				Add:  []corev1.Capability{"CAP_NET_BIND_SERVICE", "SYS_ADMIN"},
+				Drop: []corev1.Capability{"ALL"},
+			}}
+		}, []string{
+			"container nginx violates rule baseline: capability SYS_ADMIN is not allowed",
+		}},
+		{"root", "prod-a", func(pod *corev1.Pod) {
+			pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug"}}}
+		}, []string{
+			"container nginx violates rule production: runAsNonRoot must be true",
+			"ephemeralContainer debug violates rule production: runAsNonRoot must be true",
+		}},
+		{"non-root pod", "prod-a", func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &yes}
+		}, nil},
+		{"non-root user", "prod-a", func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &no}
+			pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsUser: &user}
+		}, nil},
+		{"root user", "prod-a", func(pod *corev1.Pod) {
+			// 容器的设置优先于 Pod 的设置
+			pod.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &yes, RunAsUser: &user}
+			pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsUser: &root}
+		}, []string{
+			"container nginx violates rule production: running as root is not allowed",
+		}},
+	}
+	for _, tt := range tests {
+		pod := testPodSpec(nil)
+		tt.mutate(pod)
+		violations, warnings := policy.Validate(tt.namespace, pod)
+		if !reflect.DeepEqual(violations, tt.want) || warnings != nil {
+			t.Errorf("%s: Validate() = %q, %q, want %q", tt.name, violations, warnings, tt.want)
+		}
+	}
+}
+
+func TestSecurityAudit(t *testing.T) {
+	policy, err := ParseSecurityPolicy([]byte(testSecurityPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privileged := true
+	pod := testPodSpec(nil)
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+
+	// audit 模式的规则只返回警告，其他规则仍然拒绝 Pod
+	violations, warnings := policy.Validate("staging", pod)
+	wantViolations := []string{"container nginx violates rule baseline: privileged containers are not allowed"}
+	wantWarnings := []string{
+		"container nginx violates rule staging: privileged containers are not allowed",
+		"container nginx violates rule staging: runAsNonRoot must be true",
+	}
+	if !reflect.DeepEqual(violations, wantViolations) || !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("Validate(staging) = %q, %q, want %q, %q", violations, warnings, wantViolations, wantWarnings)
+	}
+
+	// auditNamespaces 中的命名空间所有规则都只返回警告
+	violations, warnings = policy.Validate("dev-a", pod)
+	if violations != nil || !reflect.DeepEqual(warnings, wantViolations) {
+		t.Errorf("Validate(dev-a) = %q, %q, want no violations and %q", violations, warnings, wantViolations)
+	}
+}
+
+func TestParseSecurityPolicyErrors(t *testing.T) {
+	tests := []struct {
+		policy string
+		err    string
+	}{
+		{"rules:\n- namespaces: ['[']", "invalid pattern"},
+		{"auditNamespaces: ['[']", "invalid pattern"},
+		{"rules:\n- mode: warn", `invalid mode "warn"`},
+		{"rules:\n- allowPrivilege: true", "unknown field"},
+		{"rules:\n-", "rule 0 is empty"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSecurityPolicy([]byte(tt.policy)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseSecurityPolicy(%q) error = %v, want %q", tt.policy, err, tt.err)
+		}
+	}
+}
+
+func TestWebhookValidateSecurity(t *testing.T) {
+	policy, err := ParseSecurityPolicy([]byte(testSecurityPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newInjectServer(t)
+	s.Security = NewSecurityStore(policy)
+
+	validate := func(namespace string, pod *corev1.Pod) *admissionv1.AdmissionResponse {
+		raw, err := json.Marshal(pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.validate(&admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+			UID:       "1",
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Namespace: namespace,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	// audit 模式允许 Pod，但是返回警告
+	resp := validate("staging", testPodSpec(nil))
+	if !resp.Allowed || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "runAsNonRoot must be true") {
+		t.Errorf("validate() in an audit namespace = %v, %q, want allowed with a warning", resp.Allowed, resp.Warnings)
+	}
+
+	resp = validate("prod-a", testPodSpec(nil))
+	if resp.Allowed || resp.Result.Code != http.StatusForbidden || !strings.Contains(resp.Result.Message, "runAsNonRoot must be true") {
+		t.Errorf("validate() in an enforce namespace = %v, %+v, want forbidden", resp.Allowed, resp.Result)
+	}
+}
+
+func TestWebhookValidateEphemeralContainers(t *testing.T) {
+	security, err := ParseSecurityPolicy([]byte(testSecurityPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := ParsePolicy([]byte("rules:\n- selector:\n    matchLabels:\n      tier: frontend\n  denyLatestTag: true"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	yes := true
+
+	// nonroot 设置了 Pod 的 runAsNonRoot，root 没有设置，两个 Pod 都有一个已经存在的临时容器
+	newPod := func(name string, sc *corev1.PodSecurityContext) *corev1.Pod {
+		pod := testPodSpec(nil)
+		pod.Name, pod.Namespace, pod.Labels = name, "prod-a", map[string]string{"tier": "frontend"}
+		pod.Spec.SecurityContext = sc
+		pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "old", Image: "busybox:1.32",
+		}}}
+		return pod
+	}
+	s := newInjectServer(t)
+	s.Security = NewSecurityStore(security)
+	s.Policies = NewPolicyStore(policy)
+	s.Pods = fake.NewSimpleClientset(
+		newPod("nonroot", &corev1.PodSecurityContext{RunAsNonRoot: &yes}),
+		newPod("root", nil),
+	).CoreV1()
+
+	validate := func(name string, added corev1.EphemeralContainerCommon) *admissionv1.AdmissionResponse {
+		raw, err := json.Marshal(&corev1.EphemeralContainers{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod-a"},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "old", Image: "busybox:1.32"}},
+				{EphemeralContainerCommon: added},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.validate(&admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+			UID:         "1",
+			Kind:        metav1.GroupVersionKind{Version: "v1", Kind: "EphemeralContainers"},
+			SubResource: "ephemeralcontainers",
+			Name:        name,
+			Namespace:   "prod-a",
+			Operation:   admissionv1.Update,
+			Object:      runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	tests := []struct {
+		name  string
+		pod   string
+		added corev1.EphemeralContainerCommon
+		want  []string
+	}{
+		// Pod 的 securityContext 对临时容器同样有效
+		{"non-root pod", "nonroot", corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:1.32"}, nil},
+		{"root pod", "root", corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:1.32"}, []string{
+			"ephemeralContainer debug violates rule production: runAsNonRoot must be true",
+		}},
+		{"privileged", "nonroot", corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:1.32",
+			SecurityContext: &corev1.SecurityContext{Privileged: &yes}}, []string{
+			"ephemeralContainer debug violates rule baseline: privileged containers are not allowed",
+			"ephemeralContainer debug violates rule production: privileged containers are not allowed",
+		}},
+		// 镜像策略使用 Pod 的标签
+		{"latest", "nonroot", corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox"}, []string{
+			"ephemeralContainer debug: image busybox violates rule rule-0: the latest tag is not allowed",
+		}},
+	}
+	for _, tt := range tests {
+		resp := validate(tt.pod, tt.added)
+		if tt.want == nil {
+			if !resp.Allowed {
+				t.Errorf("%s: validate() = %+v, want allowed", tt.name, resp.Result)
+			}
+			continue
+		}
+		if want := strings.Join(tt.want, "; "); resp.Allowed || resp.Result.Code != http.StatusForbidden || resp.Result.Message != want {
+			t.Errorf("%s: validate() = %v, %+v, want forbidden with %q", tt.name, resp.Allowed, resp.Result, want)
+		}
+	}
+
+	// 不知道 Pod 时拒绝添加临时容器
+	resp := validate("missing", corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:1.32"})
+	if resp.Allowed || !strings.Contains(resp.Result.Message, "can't check the ephemeral containers of pod prod-a/missing") {
+		t.Errorf("validate() of a missing pod = %v, %+v, want denied", resp.Allowed, resp.Result)
+	}
+	s.Pods = nil
+	if resp := validate("nonroot", corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox:1.32"}); resp.Allowed {
+		t.Errorf("validate() without a client = %+v, want denied", resp.Result)
+	}
+}
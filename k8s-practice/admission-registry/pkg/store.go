@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// fileStore 保存从文件加载的配置，文件内容变化后可以重新加载
+type fileStore struct {
+	name  string // 配置的名称，用于日志
+	file  string
+	parse func(data []byte) (interface{}, error)
+
+	lock  sync.RWMutex
+	data  []byte
+	value interface{}
+	// 加载失败的文件内容，避免重复报告同一个错误
+	failed []byte
+}
+
+func (s *fileStore) load() interface{} {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.value
+}
+
+// Reload 在文件内容变化时重新加载，返回是否加载了新的配置。
+// 新的配置无效时继续使用原来的配置
+func (s *fileStore) Reload() (bool, error) {
+	if s.file == "" {
+		return false, nil
+	}
+
+	data, err := ioutil.ReadFile(s.file)
+	if err != nil {
+		return false, err
+	}
+
+	s.lock.RLock()
+	unchanged := s.value != nil && bytes.Equal(data, s.data) || s.failed != nil && bytes.Equal(data, s.failed)
+	s.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	// 空文件通常是写了一半的文件，不能当作没有规则的配置
+	if len(bytes.TrimSpace(data)) == 0 {
+		err = fmt.Errorf("%s: the %s file is empty", s.file, s.name)
+	}
+	var value interface{}
+	if err == nil {
+		if value, err = s.parse(data); err != nil {
+			err = fmt.Errorf("%s: %v", s.file, err)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil {
+		s.failed = data
+		return false, err
+	}
+	s.data, s.value, s.failed = data, value, nil
+	return true, nil
+}
+
+// Watch 每隔 interval 检查一次文件，直到 stop 被关闭。
+// 挂载的 ConfigMap 是通过替换符号链接更新的，所以这里比较文件内容而不是监听文件事件
+func (s *fileStore) Watch(interval time.Duration, stop <-chan struct{}) {
+	if s.file == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				klog.Errorf("Failed to reload %s, keep the current one: %v", s.name, err)
+			} else if reloaded {
+				klog.Infof("Reloaded %s from %s", s.name, s.file)
+			}
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package pkg
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -13,6 +15,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 )
 
@@ -25,12 +29,29 @@ var (
 const (
 	AnnotationMutateKey = "io.ydzs.admission-registry/mutate" // io.ydzs.admission-registry/mutate=no/off/false/n
 	AnnotationStatusKey = "io.ydzs.admission-registry/status" // io.ydzs.admission-registry/status=mutated
+
+	// getPodTimeout 是获取 Pod 的超时时间，要小于 webhook 的超时时间
+	getPodTimeout = 5 * time.Second
 )
 
 type WhSvrParam struct {
-	Port     int
-	CertFile string
-	KeyFile  string
+	Port            int
+	CertFile        string
+	KeyFile         string
+	CACertFile      string
+	CAKeyFile       string
+	CertValidity    time.Duration
+	CAValidity      time.Duration
+	RotateBefore    time.Duration
+	CertInterval    time.Duration
+	ValidateConfig  string
+	MutateConfig    string
+	PolicyFile      string
+	RulesFile       string
+	ResourcesFile   string
+	SecurityFile    string
+	SidecarTemplate string
+	PolicyInterval  time.Duration
 }
 
 type patchOperation struct {
@@ -40,8 +61,18 @@ type patchOperation struct {
 }
 
 type WebhookServer struct {
-	Server              *http.Server // http server
-	WhiteListRegistries []string     // 白名单的镜像仓库列表
+	Server   *http.Server // http server
+	Policies *PolicyStore // 镜像准入策略
+	Rules    *RuleStore   // 表达式编写的准入规则
+	// 容器资源的默认值和限制
+	Resources *ResourceStore
+	// Pod 的安全策略
+	Security *SecurityStore
+	// Sidecar 为空时不注入 sidecar，Namespaces 为空时只根据 Pod 的 annotation 判断是否注入
+	Sidecar    *SidecarStore
+	Namespaces corelisters.NamespaceLister
+	// Pods 获取添加临时容器的 Pod，为空时拒绝添加临时容器
+	Pods corev1client.PodsGetter
 }
 
 func (s *WebhookServer) Handler(writer http.ResponseWriter, request *http.Request) {
@@ -125,34 +156,85 @@ func (s *WebhookServer) validate(ar *admissionv1.AdmissionReview) *admissionv1.A
 	klog.Infof("AdmissionReview for Kind=%s, Namespace=%s Name=%s UID=%s",
 		req.Kind.Kind, req.Namespace, req.Name, req.UID)
 
-	var pod corev1.Pod
-	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
-		klog.Errorf("Can't unmarshal object raw: %v", err)
-		allowed = false
-		code = http.StatusBadRequest
+	var violations, warnings []string
+	switch req.Kind.Kind {
+	case "Pod":
+		var pod corev1.Pod
+		if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+			klog.Errorf("Can't unmarshal object raw: %v", err)
+			return &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Code:    http.StatusBadRequest,
+					Message: err.Error(),
+				},
+			}
+		}
+		violations = s.Policies.Policy().Validate(req.Namespace, pod.Labels, podImages(&pod))
+		violations = append(violations, s.Resources.Policy().Validate(req.Namespace, &pod)...)
+
+		// audit 模式的规则只返回警告
+		securityViolations, securityWarnings := s.Security.Policy().Validate(req.Namespace, &pod)
+		violations = append(violations, securityViolations...)
+		warnings = append(warnings, securityWarnings...)
+	case "EphemeralContainers":
+		// 通过 pods/ephemeralcontainers 子资源添加临时容器时，请求的对象只有临时容器，
+		// 镜像策略需要 Pod 的标签，安全策略需要 Pod 的 securityContext。临时容器不能设置资源
+		var ephemeral corev1.EphemeralContainers
+		if err := json.Unmarshal(req.Object.Raw, &ephemeral); err != nil {
+			klog.Errorf("Can't unmarshal object raw: %v", err)
+			return &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Code:    http.StatusBadRequest,
+					Message: err.Error(),
+				},
+			}
+		}
+		// 不知道 Pod 时无法检查临时容器，拒绝请求
+		pod, err := s.getPod(req.Namespace, req.Name)
+		if err != nil {
+			klog.Errorf("Failed to get pod %s/%s: %v", req.Namespace, req.Name, err)
+			return &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Code:    http.StatusForbidden,
+					Message: fmt.Sprintf("can't check the ephemeral containers of pod %s/%s: %v", req.Namespace, req.Name, err),
+				},
+			}
+		}
+
+		added := addedEphemeralContainers(pod, ephemeral.EphemeralContainers)
+		var images []ContainerImage
+		for _, container := range added {
+			images = append(images, ContainerImage{Kind: "ephemeralContainer", Name: container.Name, Image: container.Image})
+		}
+		violations = s.Policies.Policy().Validate(req.Namespace, pod.Labels, images)
+
+		securityViolations, securityWarnings := s.Security.Policy().ValidateEphemeralContainers(req.Namespace, pod, added)
+		violations = append(violations, securityViolations...)
+		warnings = append(warnings, securityWarnings...)
+	}
+
+	// 规则求值出错时拒绝请求
+	ruleViolations, err := s.validateRules(req)
+	if err != nil {
+		klog.Errorf("Failed to evaluate rules: %v", err)
 		return &admissionv1.AdmissionResponse{
-			Allowed: allowed,
+			Allowed: false,
 			Result: &metav1.Status{
-				Code:    int32(code),
+				Code:    http.StatusInternalServerError,
 				Message: err.Error(),
 			},
 		}
 	}
+	violations = append(violations, ruleViolations...)
 
 	// 处理真正的业务逻辑
-	for _, container := range pod.Spec.Containers {
-		var whitelisted = false
-		for _, reg := range s.WhiteListRegistries {
-			if strings.HasPrefix(container.Image, reg) {
-				whitelisted = true
-			}
-		}
-		if !whitelisted {
-			allowed = false
-			code = http.StatusForbidden
-			message = fmt.Sprintf("%s image comes from an untrusted registry! Only images from %v are allowed.", container.Image, s.WhiteListRegistries)
-			break
-		}
+	if len(violations) > 0 {
+		allowed = false
+		code = http.StatusForbidden
+		message = strings.Join(violations, "; ")
 	}
 
 	return &admissionv1.AdmissionResponse{
@@ -161,15 +243,73 @@ func (s *WebhookServer) validate(ar *admissionv1.AdmissionReview) *admissionv1.A
 			Code:    int32(code),
 			Message: message,
 		},
+		Warnings: warnings,
 	}
 }
 
+// podImages 返回 Pod 中所有容器的镜像，包括 initContainers 和 ephemeralContainers
+func podImages(pod *corev1.Pod) []ContainerImage {
+	var images []ContainerImage
+	for _, container := range pod.Spec.InitContainers {
+		images = append(images, ContainerImage{Kind: "initContainer", Name: container.Name, Image: container.Image})
+	}
+	for _, container := range pod.Spec.Containers {
+		images = append(images, ContainerImage{Kind: "container", Name: container.Name, Image: container.Image})
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		images = append(images, ContainerImage{Kind: "ephemeralContainer", Name: container.Name, Image: container.Image})
+	}
+	return images
+}
+
+// getPod 从 API server 获取 Pod，临时容器很少添加，所以不使用缓存所有 Pod 的 informer
+func (s *WebhookServer) getPod(namespace, name string) (*corev1.Pod, error) {
+	if s.Pods == nil {
+		return nil, fmt.Errorf("no kubernetes client")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), getPodTimeout)
+	defer cancel()
+	return s.Pods.Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// addedEphemeralContainers 返回请求中新添加的临时容器，已有的临时容器不能修改，
+// 在添加时已经检查过了
+func addedEphemeralContainers(pod *corev1.Pod, containers []corev1.EphemeralContainer) []corev1.EphemeralContainer {
+	existing := make(map[string]bool, len(pod.Spec.EphemeralContainers))
+	for _, container := range pod.Spec.EphemeralContainers {
+		existing[container.Name] = true
+	}
+
+	var added []corev1.EphemeralContainer
+	for _, container := range containers {
+		if !existing[container.Name] {
+			added = append(added, container)
+		}
+	}
+	return added
+}
+
+func (s *WebhookServer) validateRules(req *admissionv1.AdmissionRequest) ([]string, error) {
+	object, err := requestObject(req)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := reviewVars(req, object)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Rules.Rules().validate(vars)
+}
+
 func (s *WebhookServer) mutate(ar *admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
 	// Deployment、Service -> annotations： AnnotationMutateKey， AnnotationStatusKey
 	req := ar.Request
 
 	var (
 		objectMeta *metav1.ObjectMeta
+		patch      []patchOperation
 	)
 
 	klog.Infof("AdmissionReview for Kind=%s, Namespace=%s Name=%s UID=%s",
@@ -201,31 +341,71 @@ func (s *WebhookServer) mutate(ar *admissionv1.AdmissionReview) *admissionv1.Adm
 			}
 		}
 		objectMeta = &service.ObjectMeta
+	case "Pod":
+		// Pod 不添加 mutated 的 annotation，而是根据配置注入 sidecar 并填充资源的默认值
+		var pod corev1.Pod
+		if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+			klog.Errorf("Can't not unmarshal raw object: %v", err)
+			return &admissionv1.AdmissionResponse{
+				Result: &metav1.Status{
+					Code:    http.StatusBadRequest,
+					Message: err.Error(),
+				},
+			}
+		}
+		injectPatch, err := s.inject(req.Namespace, &pod)
+		if err != nil {
+			klog.Errorf("Failed to inject sidecar: %v", err)
+			return &admissionv1.AdmissionResponse{
+				Result: &metav1.Status{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+				},
+			}
+		}
+		patch = append(patch, injectPatch...)
+		// 注入的 sidecar 已经添加到 pod 中，同样需要填充默认值
+		patch = append(patch, s.Resources.Policy().Default(req.Namespace, &pod)...)
 	default:
+		// 其他类型的对象只执行规则中的 mutate
+		if len(s.Rules.Rules().Mutations) == 0 {
+			return &admissionv1.AdmissionResponse{
+				Result: &metav1.Status{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("Can't handle the kind(%s) object", req.Kind.Kind),
+				},
+			}
+		}
+	}
+
+	// 判断是否需要真的执行 mutate 操作
+	if objectMeta != nil && mutationRequired(objectMeta) {
+		annotations := map[string]string{
+			AnnotationStatusKey: "mutated",
+		}
+		patch = append(patch, mutateAnnotations(objectMeta.GetAnnotations(), annotations)...)
+	}
+
+	// 在上面的 patch 的基础上执行规则中的 mutate
+	object, err := requestObject(req)
+	if err == nil {
+		_, patch, err = s.Rules.Rules().mutate(req, object, patch)
+	}
+	if err != nil {
+		klog.Errorf("Failed to evaluate rules: %v", err)
 		return &admissionv1.AdmissionResponse{
 			Result: &metav1.Status{
-				Code:    http.StatusBadRequest,
-				Message: fmt.Sprintf("Can't handle the kind(%s) object", req.Kind.Kind),
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
 			},
 		}
 	}
-
-	// 判断是否需要真的执行 mutate 操作
-	if !mutationRequired(objectMeta) {
+	if len(patch) == 0 {
 		return &admissionv1.AdmissionResponse{
 			Allowed: true,
 		}
 	}
 
-	// 需要执行 mutate 操作
-
-	annotations := map[string]string{
-		AnnotationStatusKey: "mutated",
-	}
-
-	var patch []patchOperation
-	patch = append(patch, mutateAnnotations(objectMeta.GetAnnotations(), annotations)...)
-
 	patchBytes, err := json.Marshal(patch)
 	if err != nil {
 		klog.Errorf("patch marshal error: %v", err)
@@ -247,6 +427,29 @@ func (s *WebhookServer) mutate(ar *admissionv1.AdmissionReview) *admissionv1.Adm
 	}
 }
 
+// inject 返回注入 sidecar 的 patch，不需要注入时返回空
+func (s *WebhookServer) inject(namespace string, pod *corev1.Pod) ([]patchOperation, error) {
+	if s.Sidecar == nil {
+		return nil, nil
+	}
+
+	required, err := injectionRequired(pod, s.Namespaces, namespace)
+	klog.Infof("Injection policy for %s/%s: required: %v", pod.Name, namespace, required)
+	if err != nil || !required {
+		return nil, err
+	}
+
+	// 创建 Pod 时对象中可能没有命名空间
+	if pod.Namespace == "" {
+		pod.Namespace = namespace
+	}
+	sidecar, err := s.Sidecar.Template().Render(pod)
+	if err != nil {
+		return nil, err
+	}
+	return injectSidecar(pod, sidecar), nil
+}
+
 func mutationRequired(metadata *metav1.ObjectMeta) bool {
 	annotations := metadata.GetAnnotations()
 	if annotations == nil {
@@ -273,24 +476,20 @@ func mutationRequired(metadata *metav1.ObjectMeta) bool {
 }
 
 func mutateAnnotations(target map[string]string, added map[string]string) (patch []patchOperation) {
+	// 没有 annotations 时需要添加整个 map，否则逐个添加，避免覆盖已有的 annotations
+	if len(target) == 0 {
+		return []patchOperation{{
+			Op:    "add",
+			Path:  "/metadata/annotations",
+			Value: added,
+		}}
+	}
 	for key, value := range added {
-		if target == nil || target[key] == "" {
-			target = map[string]string{}
-			patch = append(patch, patchOperation{
-				Op:   "add",
-				Path: "/metadata/annotations",
-				Value: map[string]string{
-					key: value,
-				},
-			})
-		} else {
-			patch = append(patch, patchOperation{
-				Op:    "replace",
-				Path:  "/metadata/annotations/" + key,
-				Value: value,
-			})
-		}
+		patch = append(patch, patchOperation{
+			Op:    "add",
+			Path:  "/metadata/annotations/" + escapePointer(key),
+			Value: value,
+		})
 	}
 	return
 }
-